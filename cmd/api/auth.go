@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+var authClient pb.AuthServiceClient
+
+// authenticate rejects requests without valid credentials: a JWT in
+// "Authorization: Bearer", or an API key in "Authorization: ApiKey" or
+// X-API-Key. JWTs are verified here; API keys are looked up by the DB
+// service. The principal becomes the actor of the request, and the
+// credential is forwarded with every call to the DB service.
+func authenticate(jwt *auth.JWTVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cred, ok := requestCredential(c)
+		if !ok {
+			c.Header("WWW-Authenticate", auth.SchemeBearer)
+			writeError(c, http.StatusUnauthorized, "missing or malformed credentials")
+			c.Abort()
+			return
+		}
+
+		var principal *auth.Principal
+		if cred.Scheme == auth.SchemeBearer {
+			if jwt == nil {
+				writeError(c, http.StatusUnauthorized, "bearer tokens are not accepted")
+				c.Abort()
+				return
+			}
+			p, err := jwt.Verify(cred.Value)
+			if err != nil {
+				writeError(c, http.StatusUnauthorized, err.Error())
+				c.Abort()
+				return
+			}
+			principal = p
+		} else {
+			ctx, cancel := context.WithTimeout(auth.WithCredential(c.Request.Context(), cred), requestTimeout)
+			defer cancel()
+
+			res, err := authClient.Authenticate(ctx, &pb.AuthenticateRequest{})
+			if err != nil {
+				writeGRPCError(c, err)
+				c.Abort()
+				return
+			}
+			principal = &auth.Principal{Subject: res.Subject, WorkspaceID: res.WorkspaceId, Scheme: res.Scheme, Admin: res.Admin}
+		}
+
+		meta := reqmeta.FromContext(c.Request.Context())
+		meta.Actor = principal.Subject
+		c.Set(actorKey, principal.Subject)
+
+		ctx := reqmeta.NewContext(c.Request.Context(), meta)
+		ctx = auth.WithCredential(auth.NewContext(ctx, principal), cred)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+func requestCredential(c *gin.Context) (auth.Credential, bool) {
+	if h := c.GetHeader("Authorization"); h != "" {
+		cred, err := auth.ParseAuthorization(h)
+		return cred, err == nil
+	}
+	if key := c.GetHeader(apiKeyHeader); key != "" {
+		return auth.Credential{Scheme: auth.SchemeAPIKey, Value: key}, true
+	}
+	return auth.Credential{}, false
+}
+
+// createAPIKeyHandler issues an API key for the caller. The key is only
+// part of this response; afterwards just its prefix is known.
+func createAPIKeyHandler(c *gin.Context) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := authClient.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{Name: req.Name})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+func revokeAPIKeyHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	if _, err := authClient.RevokeAPIKey(ctx, &pb.APIKeyIDRequest{Id: c.Param("id")}); err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
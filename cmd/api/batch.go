@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"google.golang.org/grpc/codes"
+)
+
+// taskMethodsPath routes the custom methods of the task collection, such as
+// POST /tasks:batch. gin does not match literal colons in routes, so the
+// method, colon included, is a parameter told apart by taskMethodHandler.
+const taskMethodsPath = "/tasks:method"
+
+func taskMethodHandler(c *gin.Context) {
+	switch c.Param("method") {
+	case ":batch":
+		batchHandler(c)
+	default:
+		writeError(c, http.StatusNotFound, "no such method: "+c.Request.URL.Path)
+	}
+}
+
+// batchModes maps the modes of a batch request to the DB service ones.
+var batchModes = map[string]pb.BatchMode{
+	"":               pb.BatchMode_BATCH_MODE_ALL_OR_NOTHING,
+	"all_or_nothing": pb.BatchMode_BATCH_MODE_ALL_OR_NOTHING,
+	"best_effort":    pb.BatchMode_BATCH_MODE_BEST_EFFORT,
+}
+
+// batchItem is the outcome of one item of a batch: the task after the
+// change, or the status and error of a failed item.
+type batchItem struct {
+	ID     string   `json:"id,omitempty"`
+	Status int      `json:"status"`
+	Task   *pb.Task `json:"task,omitempty"`
+	Code   string   `json:"code,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// batchHandler creates, completes or deletes many tasks in one transaction.
+// The operation is "create" with the drafts in tasks, or "done" or "delete"
+// with the task IDs in ids. In mode "all_or_nothing", the default, one
+// failed item fails the batch; in "best_effort" the other items are
+// applied. The response is 200 with one result per item, in order, unless
+// the batch itself is malformed. Batches are unversioned, see
+// rejectIfMatch.
+func batchHandler(c *gin.Context) {
+	var req struct {
+		Operation string      `json:"operation"`
+		Mode      string      `json:"mode"`
+		Tasks     []taskDraft `json:"tasks"`
+		IDs       []string    `json:"ids"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	mode, ok := batchModes[req.Mode]
+	if !ok {
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("invalid mode %q: expected all_or_nothing or best_effort", req.Mode))
+		return
+	}
+	if req.Operation == "create" && len(req.IDs) > 0 || req.Operation != "create" && len(req.Tasks) > 0 {
+		writeError(c, http.StatusBadRequest, "create takes tasks, done and delete take ids")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	var (
+		res *pb.BatchResponse
+		err error
+	)
+	switch req.Operation {
+	case "create":
+		in := &pb.BatchCreateRequest{Mode: mode}
+		for _, t := range req.Tasks {
+			in.Tasks = append(in.Tasks, t.toPB())
+		}
+		res, err = taskClient.BatchCreate(ctx, in)
+	case "done":
+		res, err = taskClient.BatchMarkDone(ctx, &pb.BatchTaskIDsRequest{Ids: req.IDs, Mode: mode})
+	case "delete":
+		res, err = taskClient.BatchDelete(ctx, &pb.BatchTaskIDsRequest{Ids: req.IDs, Mode: mode})
+	default:
+		writeError(c, http.StatusBadRequest, fmt.Sprintf("invalid operation %q: expected create, done or delete", req.Operation))
+		return
+	}
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	items := make([]batchItem, len(res.Results))
+	for i, r := range res.Results {
+		items[i] = batchItem{ID: r.Id, Status: http.StatusOK, Task: r.Task}
+		if code := codes.Code(r.Code); code != codes.OK {
+			items[i].Status = httpStatusFromGRPC(code)
+			items[i].Code = errorCodeByHTTPStatus[items[i].Status]
+			items[i].Error = r.Error
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"results": items})
+}
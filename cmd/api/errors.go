@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorResponse is the body of every non-2xx response of the API.
+type errorResponse struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+var httpStatusByCode = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.Aborted:            http.StatusConflict,
+	codes.FailedPrecondition: http.StatusConflict,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+}
+
+var errorCodeByHTTPStatus = map[int]string{
+	http.StatusBadRequest:          "invalid_argument",
+	http.StatusUnauthorized:        "unauthenticated",
+	http.StatusForbidden:           "permission_denied",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusPreconditionFailed:  "precondition_failed",
+	http.StatusUnprocessableEntity: "unprocessable_entity",
+	http.StatusTooManyRequests:     "resource_exhausted",
+	http.StatusNotImplemented:      "unimplemented",
+	http.StatusServiceUnavailable:  "unavailable",
+	http.StatusGatewayTimeout:      "deadline_exceeded",
+	http.StatusInternalServerError: "internal",
+}
+
+// httpStatusFromGRPC maps a gRPC status code to the matching HTTP status.
+func httpStatusFromGRPC(code codes.Code) int {
+	if s, ok := httpStatusByCode[code]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// writeError writes an error response with the given HTTP status.
+func writeError(c *gin.Context, httpStatus int, message string) {
+	code, ok := errorCodeByHTTPStatus[httpStatus]
+	if !ok {
+		code = "internal"
+	}
+	c.JSON(httpStatus, errorResponse{Code: code, Error: message})
+}
+
+// httpStatusByReason overrides the HTTP status of the errors with an
+// ErrorInfo detail of these reasons.
+var httpStatusByReason = map[string]int{
+	reqmeta.VersionMismatchReason:      http.StatusPreconditionFailed,
+	reqmeta.IdempotencyKeyReusedReason: http.StatusUnprocessableEntity,
+}
+
+// writeGRPCError translates an error returned by the DB service into an HTTP
+// response. Errors that are not gRPC statuses are reported as 500.
+func writeGRPCError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	httpStatus := httpStatusFromGRPC(st.Code())
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			if s, ok := httpStatusByReason[info.Reason]; ok {
+				httpStatus = s
+			}
+		}
+	}
+	writeError(c, httpStatus, st.Message())
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+)
+
+// The ETag of a task is its version, which the DB service increments on
+// every change of the task.
+
+// etag returns the entity tag of a task at version.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseETag returns the version of an entity tag written by etag. Weak tags
+// are accepted only if weak is set.
+func parseETag(tag string, weak bool) (int64, bool) {
+	tag = strings.TrimSpace(tag)
+	if weak {
+		tag = strings.TrimPrefix(tag, "W/")
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// ifMatch makes the DB service change a task only if it is still at one of
+// the versions of the If-Match header, see reqmeta.Meta.ExpectedVersions.
+// Requests without the header, or with "*", change whatever version there
+// is. If-Match uses the strong comparison, so a list of weak tags only never
+// matches.
+func ifMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := strings.TrimSpace(c.GetHeader("If-Match"))
+		if h == "" || h == "*" {
+			c.Next()
+			return
+		}
+		var versions []int64
+		for _, tag := range strings.Split(h, ",") {
+			if _, ok := parseETag(tag, true); !ok {
+				writeError(c, http.StatusBadRequest, fmt.Sprintf("invalid If-Match %q: expected ETags of a task", h))
+				c.Abort()
+				return
+			}
+			if version, ok := parseETag(tag, false); ok {
+				versions = append(versions, version)
+			}
+		}
+		if len(versions) == 0 {
+			writeError(c, http.StatusPreconditionFailed, "weak ETags never match If-Match")
+			c.Abort()
+			return
+		}
+
+		meta := reqmeta.FromContext(c.Request.Context())
+		meta.ExpectedVersions = versions
+		c.Request = c.Request.WithContext(reqmeta.NewContext(c.Request.Context(), meta))
+
+		c.Next()
+	}
+}
+
+// rejectIfMatch answers requests with an If-Match header with 400, for the
+// routes that change many tasks, which the version of one cannot guard.
+func rejectIfMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("If-Match") != "" {
+			writeError(c, http.StatusBadRequest, "If-Match is not supported on requests that change many tasks")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// notModified reports whether the If-None-Match header of the request
+// matches the ETag of task, using the weak comparison.
+func notModified(c *gin.Context, task *pb.Task) bool {
+	h := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if h == "" {
+		return false
+	}
+	if h == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(h, ",") {
+		if version, ok := parseETag(tag, true); ok && version == task.GetVersion() {
+			return true
+		}
+	}
+	return false
+}
+
+// writeTask responds with task and its ETag.
+func writeTask(c *gin.Context, task *pb.Task) {
+	c.Header("ETag", etag(task.GetVersion()))
+	c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// readinessTimeout bounds all readiness checks of one /readyz request.
+const readinessTimeout = 2 * time.Second
+
+// readinessCheck is a dependency the API needs to serve requests.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// dbServiceCheck asks the DB service, over the shared connection, whether it
+// can serve tasks. This fails both when the connection is down and when the
+// DB service has lost Postgres or Redis.
+func dbServiceCheck(client healthpb.HealthClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: pb.TaskService_ServiceDesc.ServiceName})
+		if err != nil {
+			return err
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("db service is %s", res.Status)
+		}
+		return nil
+	}
+}
+
+// healthzHandler reports that the process is alive. It checks nothing else,
+// so that a failing dependency does not get the API restarted.
+func healthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyzHandler runs every check and answers 503 if any of them fails, with
+// the result of each check in the body.
+func readyzHandler(checks []readinessCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		code, status := http.StatusOK, "ok"
+		results := gin.H{}
+		for _, rc := range checks {
+			if err := rc.check(ctx); err != nil {
+				code, status = http.StatusServiceUnavailable, "unavailable"
+				results[rc.name] = err.Error()
+				continue
+			}
+			results[rc.name] = "ok"
+		}
+		c.JSON(code, gin.H{"status": status, "checks": results})
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// taskEvent is the JSON form of a history entry, with the field values as
+// JSON instead of strings holding it.
+type taskEvent struct {
+	ID         int64         `json:"id"`
+	TaskID     string        `json:"task_id"`
+	Type       string        `json:"type"`
+	Actor      string        `json:"actor"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Changes    []fieldChange `json:"changes"`
+}
+
+type fieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// writeEvents responds with the events of res, passing the token for the
+// next page, if any, in the X-Next-Page-Token header.
+func writeEvents(c *gin.Context, res *pb.TaskEventsResponse) {
+	if res.NextPageToken != "" {
+		c.Header("X-Next-Page-Token", res.NextPageToken)
+	}
+	events := make([]taskEvent, 0, len(res.Events))
+	for _, e := range res.Events {
+		event := taskEvent{
+			ID:         e.Id,
+			TaskID:     e.TaskId,
+			Type:       e.Type,
+			Actor:      e.Actor,
+			OccurredAt: e.OccurredAt.AsTime(),
+			Changes:    make([]fieldChange, 0, len(e.Changes)),
+		}
+		for _, ch := range e.Changes {
+			event.Changes = append(event.Changes, fieldChange{
+				Field:  ch.Field,
+				Before: json.RawMessage(ch.Before),
+				After:  json.RawMessage(ch.After),
+			})
+		}
+		events = append(events, event)
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// parsePageSize reads the page_size query parameter, zero if it is absent.
+func parsePageSize(c *gin.Context) (int32, error) {
+	v := c.Query("page_size")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid page_size %q", v)
+	}
+	return int32(n), nil
+}
+
+// historyHandler returns the changes of a task, newest first, taking
+// page_size and page_token like listHandler.
+func historyHandler(c *gin.Context) {
+	size, err := parsePageSize(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.GetHistory(ctx, &pb.GetHistoryRequest{Id: c.Param("id"), PageSize: size, PageToken: c.Query("page_token")})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeEvents(c, res)
+}
+
+// auditHandler returns the changes of every task of the workspace, newest
+// first. Besides page_size and page_token it filters by task_id, actor,
+// type (repeated or separated by commas), since and until (RFC 3339).
+func auditHandler(c *gin.Context) {
+	req := &pb.ListAuditRequest{
+		PageToken: c.Query("page_token"),
+		TaskId:    c.Query("task_id"),
+		Actor:     c.Query("actor"),
+	}
+	var err error
+	if req.PageSize, err = parsePageSize(c); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, v := range c.QueryArray("type") {
+		for _, typ := range strings.Split(v, ",") {
+			if typ = strings.TrimSpace(typ); typ != "" {
+				req.Types = append(req.Types, typ)
+			}
+		}
+	}
+	for param, dst := range map[string]**timestamppb.Timestamp{
+		"since": &req.Since,
+		"until": &req.Until,
+	} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(c, http.StatusBadRequest, fmt.Sprintf("invalid %s %q: expected RFC 3339 time", param, v))
+				return
+			}
+			*dst = timestamppb.New(t)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.ListAudit(ctx, req)
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeEvents(c, res)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// The item handlers respond with the whole task, since changing an item may
+// also complete it.
+
+func addItemHandler(c *gin.Context) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.AddItem(ctx, &pb.AddItemRequest{TaskId: c.Param("id"), Text: req.Text})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeTask(c, res.Task)
+}
+
+func updateItemHandler(c *gin.Context) {
+	var req struct {
+		Text *string `json:"text"`
+		Done *bool   `json:"done"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	item := &pb.TaskItem{}
+	mask := &fieldmaskpb.FieldMask{}
+	if req.Text != nil {
+		item.Text = *req.Text
+		mask.Paths = append(mask.Paths, "text")
+	}
+	if req.Done != nil {
+		item.Done = *req.Done
+		mask.Paths = append(mask.Paths, "done")
+	}
+	if len(mask.Paths) == 0 {
+		writeError(c, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.UpdateItem(ctx, &pb.UpdateItemRequest{
+		TaskId:     c.Param("id"),
+		ItemId:     c.Param("item_id"),
+		Item:       item,
+		UpdateMask: mask,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeTask(c, res.Task)
+}
+
+// reorderItemsHandler expects the IDs of all items of the task in the new
+// order.
+func reorderItemsHandler(c *gin.Context) {
+	var req struct {
+		ItemIDs []string `json:"item_ids"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.ReorderItems(ctx, &pb.ReorderItemsRequest{TaskId: c.Param("id"), ItemIds: req.ItemIDs})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeTask(c, res.Task)
+}
+
+func removeItemHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.RemoveItem(ctx, &pb.ItemIDRequest{TaskId: c.Param("id"), ItemId: c.Param("item_id")})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeTask(c, res.Task)
+}
@@ -2,32 +2,53 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/auth"
 	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
 	"github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/kalpovskii/checklist/internal/metrics"
+	"github.com/kalpovskii/checklist/internal/tracing"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/emptypb"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var taskClient pb.TaskServiceClient
 
+// requestTimeout bounds every call to the DB service. The context is derived
+// from the HTTP request, so a client disconnect cancels the call as well.
+const requestTimeout = 5 * time.Second
+
 func initConfig() {
 	viper.SetEnvPrefix("CHECKLIST")
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 15*time.Second)
 }
 
 func main() {
 	initConfig()
 
-  grpcURL := viper.GetString("DB_GRPC_URL")
-  apiPort := viper.GetString("API_PORT")
+	grpcURL := viper.GetString("DB_GRPC_URL")
+	apiPort := viper.GetString("API_PORT")
 	// initializing kafka
 	kafkaBroker := viper.GetString("KAFKA_BROKER")
 	kafkaTopic := viper.GetString("KAFKA_TOPIC")
@@ -36,122 +57,521 @@ func main() {
 		log.Fatal("API_PORT or DB_GRPC_URL is not configured")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  "checklist-api",
+		Exporter:     viper.GetString("OTEL_EXPORTER"),
+		OTLPEndpoint: viper.GetString("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		File:         viper.GetString("OTEL_EXPORTER_FILE"),
+	})
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+
+	jwtVerifier, err := auth.LoadJWTVerifier(
+		viper.GetString("AUTH_JWT_HS256_SECRET"),
+		viper.GetString("AUTH_JWT_RS256_PUBLIC_KEY_FILE"),
+		viper.GetString("AUTH_JWT_ISSUER"),
+		viper.GetString("AUTH_JWT_AUDIENCE"),
+	)
+	if err != nil {
+		log.Fatalf("failed to configure jwt auth: %v", err)
+	}
+
 	// connect to gRPC server
-	conn, err := grpc.Dial(grpcURL, grpc.WithInsecure())
+	conn, err := grpc.Dial(grpcURL,
+		grpc.WithInsecure(),
+		grpc.WithChainUnaryInterceptor(reqmeta.UnaryClientInterceptor(), auth.UnaryClientInterceptor()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+	)
 	if err != nil {
 		log.Fatalf("failed to connect to gRPC server: %v", err)
 	}
 	defer conn.Close()
 
 	taskClient = pb.NewTaskServiceClient(conn)
+	authClient = pb.NewAuthServiceClient(conn)
 
 	// create kafka producer
 	producer := kafka.NewProducer(kafkaBroker, kafkaTopic)
+	defer producer.Close()
 
 	log.Printf("API started on :%s", apiPort)
 	log.Printf("Connected to gRPC DB at %s", grpcURL)
 	log.Printf("Kafka producer connected to %s topic %s", kafkaBroker, kafkaTopic)
 
 	r := gin.Default()
+	r.Use(otelgin.Middleware("checklist-api", otelgin.WithFilter(tracedRequest)), metrics.GinMiddleware(), requestMeta())
+
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	r.GET("/healthz", healthzHandler)
+	r.GET("/readyz", readyzHandler([]readinessCheck{
+		{name: "db", check: dbServiceCheck(healthpb.NewHealthClient(conn))},
+		{name: "kafka", check: producer.Ping},
+	}))
+
+	authed := r.Group("/", authenticate(jwtVerifier))
+
+	authed.POST("/create", createHandler)
+	authed.POST(taskMethodsPath, rejectIfMatch(), taskMethodHandler)
+	authed.GET("/list", func(c *gin.Context) { listHandler(c, producer) })
+	authed.DELETE("/delete", ifMatch(), deleteHandler)
+	authed.PUT("/done", ifMatch(), doneHandler)
+	authed.GET("/tasks/search", func(c *gin.Context) { searchHandler(c, producer) })
+	authed.GET("/tasks/:id", func(c *gin.Context) { getHandler(c, producer) })
+	authed.PATCH("/tasks/:id", ifMatch(), updateHandler)
+	authed.POST("/tasks/:id/transition", ifMatch(), transitionHandler)
+	authed.GET("/tasks/:id/history", historyHandler)
+	authed.POST("/tasks/:id/reopen", ifMatch(), reopenHandler)
+	authed.POST("/tasks/:id/restore", ifMatch(), restoreHandler)
+	authed.GET("/tasks/:id/collaborators", listCollaboratorsHandler)
+	authed.POST("/tasks/:id/collaborators", shareHandler)
+	authed.DELETE("/tasks/:id/collaborators/:user_id", unshareHandler)
+	authed.POST("/tasks/:id/items", ifMatch(), addItemHandler)
+	authed.PUT("/tasks/:id/items/order", ifMatch(), reorderItemsHandler)
+	authed.PATCH("/tasks/:id/items/:item_id", ifMatch(), updateItemHandler)
+	authed.DELETE("/tasks/:id/items/:item_id", ifMatch(), removeItemHandler)
+
+	authed.GET("/trash", listTrashHandler)
+	authed.GET("/audit", auditHandler)
+
+	authed.GET("/workflow", getWorkflowHandler)
+	authed.PUT("/workflow", setWorkflowHandler)
+
+	authed.POST("/api-keys", createAPIKeyHandler)
+	authed.DELETE("/api-keys/:id", revokeAPIKeyHandler)
+
+	srv := &http.Server{Addr: ":" + apiPort, Handler: r}
+
+	// ctx is cancelled on SIGINT or SIGTERM, which starts the shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
 
-	r.POST("/create", func(c *gin.Context) {createHandler(c, producer)})
-	r.GET("/list", func(c *gin.Context) { listHandler(c, producer) })
-	r.DELETE("/delete", func(c *gin.Context) { deleteHandler(c, producer) })
-	r.PUT("/done", func(c *gin.Context) { doneHandler(c, producer) })
+	log.Print("shutting down")
+	timeout := viper.GetDuration("SHUTDOWN_TIMEOUT")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// Shutdown waits for the running handlers, including their Kafka writes.
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("requests still running after %s: %v", timeout, err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("failed to flush traces: %v", err)
+	}
+	log.Print("stopped")
+}
 
-	log.Fatal(r.Run(":" + apiPort))
+// tracedRequest leaves probes and metric scrapes out of traces.
+func tracedRequest(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+	return true
 }
 
-func sendKafkaEvent(producer *kafka.Producer, action string) {
-	if producer != nil {
-		producer.SendEvent(action)
+// sendKafkaEvent stamps the event with the request metadata and publishes it.
+// It is a no-op without a producer. Only reads are reported from here: the DB
+// service records the events of task changes in its outbox.
+func sendKafkaEvent(c *gin.Context, producer *kafka.Producer, event kafka.Event) {
+	if producer == nil {
+		return
+	}
+	event.Actor = c.GetString(actorKey)
+	event.CorrelationID = c.GetString(correlationIDKey)
+
+	// The request has been served, so report it even if the client is gone.
+	ctx := context.WithoutCancel(c.Request.Context())
+	if err := producer.SendEvent(ctx, event); err != nil {
+		log.Println("failed to write kafka message:", err)
 	}
 }
 
-func createHandler(c *gin.Context, producer *kafka.Producer) {
-	var req struct {
-		Title   string `json:"title"`
-		Content string `json:"content"`
+// taskDraft is the body of a request to create a task.
+type taskDraft struct {
+	Title        string     `json:"title"`
+	Content      string     `json:"content"`
+	AutoComplete bool       `json:"auto_complete"`
+	DueAt        *time.Time `json:"due_at"`
+	RemindAt     *time.Time `json:"remind_at"`
+	Recurrence   string     `json:"recurrence"`
+	TimeZone     string     `json:"time_zone"`
+}
+
+func (d taskDraft) toPB() *pb.CreateTaskRequest {
+	return &pb.CreateTaskRequest{
+		Title:        d.Title,
+		Content:      d.Content,
+		AutoComplete: d.AutoComplete,
+		DueAt:        toPBTime(d.DueAt),
+		RemindAt:     toPBTime(d.RemindAt),
+		Recurrence:   d.Recurrence,
+		TimeZone:     d.TimeZone,
 	}
+}
+
+func createHandler(c *gin.Context) {
+	var req taskDraft
 	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
 	defer cancel()
 
-	res, err := taskClient.Create(ctx, &pb.CreateTaskRequest{
-		Title:   req.Title,
-		Content: req.Content,
-	})
+	res, err := taskClient.Create(ctx, req.toPB())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
-	sendKafkaEvent(producer, "create")
-
-	c.JSON(http.StatusOK, res.Task)
+	writeTask(c, res.Task)
 }
 
+// listHandler returns one page of tasks. The token for the next page, if
+// any, is sent in the X-Next-Page-Token header.
 func listHandler(c *gin.Context, producer *kafka.Producer) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	req, err := parseListRequest(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.List(ctx, req)
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	sendKafkaEvent(c, producer, kafka.NewEvent(kafka.EventTasksListed, ""))
+
+	if res.NextPageToken != "" {
+		c.Header("X-Next-Page-Token", res.NextPageToken)
+	}
+	tasks := res.Tasks
+	if tasks == nil {
+		tasks = []*pb.Task{}
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// parseListRequest reads the list query parameters: page_size, page_token,
+// done, created_after, created_before (RFC 3339), sort (asc or desc),
+// overdue and due_within (a duration like 24h).
+func parseListRequest(c *gin.Context) (*pb.ListTasksRequest, error) {
+	req := &pb.ListTasksRequest{PageToken: c.Query("page_token")}
+
+	if v := c.Query("page_size"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid page_size %q", v)
+		}
+		req.PageSize = int32(n)
+	}
+	if v := c.Query("done"); v != "" {
+		done, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid done %q", v)
+		}
+		req.Done = &done
+	}
+	// status may be repeated or list several statuses separated by commas.
+	for _, v := range c.QueryArray("status") {
+		for _, status := range strings.Split(v, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				req.Statuses = append(req.Statuses, status)
+			}
+		}
+	}
+	if v := c.Query("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid overdue %q", v)
+		}
+		req.Overdue = overdue
+	}
+	if v := c.Query("due_within"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid due_within %q: expected a positive duration like 24h", v)
+		}
+		req.DueWithin = durationpb.New(d)
+	}
+	for param, dst := range map[string]**timestamppb.Timestamp{
+		"created_after":  &req.CreatedAfter,
+		"created_before": &req.CreatedBefore,
+	} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: expected RFC 3339 time", param, v)
+			}
+			*dst = timestamppb.New(t)
+		}
+	}
+	switch c.Query("sort") {
+	case "", "asc":
+		req.Sort = pb.SortOrder_SORT_ORDER_CREATED_ASC
+	case "desc":
+		req.Sort = pb.SortOrder_SORT_ORDER_CREATED_DESC
+	default:
+		return nil, fmt.Errorf("invalid sort %q: expected asc or desc", c.Query("sort"))
+	}
+
+	return req, nil
+}
+
+// getHandler returns a task, or 304 if it still has the ETag of the
+// If-None-Match header.
+func getHandler(c *gin.Context, producer *kafka.Producer) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
 	defer cancel()
 
-	res, err := taskClient.List(ctx, &emptypb.Empty{})
+	res, err := taskClient.Get(ctx, &pb.TaskIDRequest{Id: c.Param("id")})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
-	sendKafkaEvent(producer, "list")
+	sendKafkaEvent(c, producer, kafka.NewEvent(kafka.EventTaskViewed, res.Task.GetId()))
 
-	c.JSON(http.StatusOK, res.Tasks)
+	if notModified(c, res.Task) {
+		c.Header("ETag", etag(res.Task.GetVersion()))
+		c.Status(http.StatusNotModified)
+		return
+	}
+	writeTask(c, res.Task)
+}
+
+func searchHandler(c *gin.Context, producer *kafka.Producer) {
+	req := &pb.SearchTasksRequest{Query: c.Query("q")}
+	if req.Query == "" {
+		writeError(c, http.StatusBadRequest, "query parameter q is required")
+		return
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			writeError(c, http.StatusBadRequest, fmt.Sprintf("invalid limit %q", v))
+			return
+		}
+		req.PageSize = int32(n)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.Search(ctx, req)
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	sendKafkaEvent(c, producer, kafka.NewEvent(kafka.EventTasksSearched, ""))
+
+	results := res.Results
+	if results == nil {
+		results = []*pb.SearchResult{}
+	}
+	c.JSON(http.StatusOK, results)
 }
 
-func deleteHandler(c *gin.Context, producer *kafka.Producer) {
+func deleteHandler(c *gin.Context) {
 	var req struct {
 		ID string `json:"id"`
 	}
 	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
 	defer cancel()
 
 	res, err := taskClient.Delete(ctx, &pb.TaskIDRequest{Id: req.ID})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
-	sendKafkaEvent(producer, "delete")
-
 	c.JSON(http.StatusOK, res)
 }
 
-func doneHandler(c *gin.Context, producer *kafka.Producer) {
+func doneHandler(c *gin.Context) {
 	var req struct {
 		ID string `json:"id"`
 	}
 	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
 	defer cancel()
 
 	res, err := taskClient.MarkDone(ctx, &pb.TaskIDRequest{Id: req.ID})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeGRPCError(c, err)
 		return
 	}
 
-	sendKafkaEvent(producer, "mark_done")
+	c.JSON(http.StatusOK, res)
+}
+
+func updateHandler(c *gin.Context) {
+	var req struct {
+		Title        *string      `json:"title"`
+		Content      *string      `json:"content"`
+		AutoComplete *bool        `json:"auto_complete"`
+		DueAt        nullableTime `json:"due_at"`
+		RemindAt     nullableTime `json:"remind_at"`
+		Recurrence   *string      `json:"recurrence"`
+		TimeZone     *string      `json:"time_zone"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task := &pb.Task{}
+	mask := &fieldmaskpb.FieldMask{}
+	if req.Title != nil {
+		task.Title = *req.Title
+		mask.Paths = append(mask.Paths, "title")
+	}
+	if req.Content != nil {
+		task.Content = *req.Content
+		mask.Paths = append(mask.Paths, "content")
+	}
+	if req.AutoComplete != nil {
+		task.AutoComplete = *req.AutoComplete
+		mask.Paths = append(mask.Paths, "auto_complete")
+	}
+	if req.DueAt.Set {
+		task.DueAt = toPBTime(req.DueAt.Time)
+		mask.Paths = append(mask.Paths, "due_at")
+	}
+	if req.RemindAt.Set {
+		task.RemindAt = toPBTime(req.RemindAt.Time)
+		mask.Paths = append(mask.Paths, "remind_at")
+	}
+	if req.Recurrence != nil {
+		task.Recurrence = *req.Recurrence
+		mask.Paths = append(mask.Paths, "recurrence")
+	}
+	if req.TimeZone != nil {
+		task.TimeZone = *req.TimeZone
+		mask.Paths = append(mask.Paths, "time_zone")
+	}
+	if len(mask.Paths) == 0 {
+		writeError(c, http.StatusBadRequest, "no fields to update")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.Update(ctx, &pb.UpdateTaskRequest{
+		Id:         c.Param("id"),
+		Task:       task,
+		UpdateMask: mask,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeTask(c, res.Task)
+}
+
+// nullableTime is a JSON time that tells null, which clears the time, from
+// a missing field, which leaves it unchanged.
+type nullableTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (t *nullableTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	return json.Unmarshal(data, &t.Time)
+}
+
+func toPBTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// shareHandler shares a task with a user as a viewer or editor. Sharing with
+// a collaborator again changes their role.
+func shareHandler(c *gin.Context) {
+	var req struct {
+		UserID string `json:"user_id"`
+		Role   string `json:"role"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.Share(ctx, &pb.ShareTaskRequest{
+		Id:     c.Param("id"),
+		UserId: req.UserID,
+		Role:   req.Role,
+	})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, res)
-}
\ No newline at end of file
+}
+
+func unshareHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	_, err := taskClient.Unshare(ctx, &pb.UnshareTaskRequest{Id: c.Param("id"), UserId: c.Param("user_id")})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func listCollaboratorsHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.ListCollaborators(ctx, &pb.TaskIDRequest{Id: c.Param("id")})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	collaborators := res.Collaborators
+	if collaborators == nil {
+		collaborators = []*pb.Collaborator{}
+	}
+	c.JSON(http.StatusOK, collaborators)
+}
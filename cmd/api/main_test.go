@@ -6,27 +6,57 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kalpovskii/checklist/internal/app/auth"
 	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type taskClientStub struct {
-	createFn   func(ctx context.Context, in *pb.CreateTaskRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
-	listFn     func(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*pb.TaskListResponse, error)
-	deleteFn   func(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.StatusResponse, error)
-	markDoneFn func(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.StatusResponse, error)
+	createFn            func(ctx context.Context, in *pb.CreateTaskRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	listFn              func(ctx context.Context, in *pb.ListTasksRequest, opts ...grpc.CallOption) (*pb.TaskListResponse, error)
+	deleteFn            func(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.StatusResponse, error)
+	markDoneFn          func(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.StatusResponse, error)
+	updateFn            func(ctx context.Context, in *pb.UpdateTaskRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	getFn               func(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	searchFn            func(ctx context.Context, in *pb.SearchTasksRequest, opts ...grpc.CallOption) (*pb.SearchTasksResponse, error)
+	shareFn             func(ctx context.Context, in *pb.ShareTaskRequest, opts ...grpc.CallOption) (*pb.Collaborator, error)
+	unshareFn           func(ctx context.Context, in *pb.UnshareTaskRequest, opts ...grpc.CallOption) (*pb.StatusResponse, error)
+	listCollaboratorsFn func(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.CollaboratorsResponse, error)
+	addItemFn           func(ctx context.Context, in *pb.AddItemRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	updateItemFn        func(ctx context.Context, in *pb.UpdateItemRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	reorderItemsFn      func(ctx context.Context, in *pb.ReorderItemsRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	removeItemFn        func(ctx context.Context, in *pb.ItemIDRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	transitionFn        func(ctx context.Context, in *pb.TransitionTaskRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	getWorkflowFn       func(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*pb.Workflow, error)
+	setWorkflowFn       func(ctx context.Context, in *pb.SetWorkflowRequest, opts ...grpc.CallOption) (*pb.Workflow, error)
+	restoreFn           func(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	listTrashFn         func(ctx context.Context, in *pb.ListTrashRequest, opts ...grpc.CallOption) (*pb.TaskListResponse, error)
+	reopenFn            func(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error)
+	getHistoryFn        func(ctx context.Context, in *pb.GetHistoryRequest, opts ...grpc.CallOption) (*pb.TaskEventsResponse, error)
+	listAuditFn         func(ctx context.Context, in *pb.ListAuditRequest, opts ...grpc.CallOption) (*pb.TaskEventsResponse, error)
+	batchCreateFn       func(ctx context.Context, in *pb.BatchCreateRequest, opts ...grpc.CallOption) (*pb.BatchResponse, error)
+	batchMarkDoneFn     func(ctx context.Context, in *pb.BatchTaskIDsRequest, opts ...grpc.CallOption) (*pb.BatchResponse, error)
+	batchDeleteFn       func(ctx context.Context, in *pb.BatchTaskIDsRequest, opts ...grpc.CallOption) (*pb.BatchResponse, error)
 }
 
 func (s *taskClientStub) Create(ctx context.Context, in *pb.CreateTaskRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
 	return s.createFn(ctx, in, opts...)
 }
 
-func (s *taskClientStub) List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*pb.TaskListResponse, error) {
+func (s *taskClientStub) List(ctx context.Context, in *pb.ListTasksRequest, opts ...grpc.CallOption) (*pb.TaskListResponse, error) {
 	return s.listFn(ctx, in, opts...)
 }
 
@@ -38,6 +68,90 @@ func (s *taskClientStub) MarkDone(ctx context.Context, in *pb.TaskIDRequest, opt
 	return s.markDoneFn(ctx, in, opts...)
 }
 
+func (s *taskClientStub) Update(ctx context.Context, in *pb.UpdateTaskRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.updateFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) Get(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.getFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) Search(ctx context.Context, in *pb.SearchTasksRequest, opts ...grpc.CallOption) (*pb.SearchTasksResponse, error) {
+	return s.searchFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) Share(ctx context.Context, in *pb.ShareTaskRequest, opts ...grpc.CallOption) (*pb.Collaborator, error) {
+	return s.shareFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) Unshare(ctx context.Context, in *pb.UnshareTaskRequest, opts ...grpc.CallOption) (*pb.StatusResponse, error) {
+	return s.unshareFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) ListCollaborators(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.CollaboratorsResponse, error) {
+	return s.listCollaboratorsFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) AddItem(ctx context.Context, in *pb.AddItemRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.addItemFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) UpdateItem(ctx context.Context, in *pb.UpdateItemRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.updateItemFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) ReorderItems(ctx context.Context, in *pb.ReorderItemsRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.reorderItemsFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) RemoveItem(ctx context.Context, in *pb.ItemIDRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.removeItemFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) Transition(ctx context.Context, in *pb.TransitionTaskRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.transitionFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) GetWorkflow(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*pb.Workflow, error) {
+	return s.getWorkflowFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) SetWorkflow(ctx context.Context, in *pb.SetWorkflowRequest, opts ...grpc.CallOption) (*pb.Workflow, error) {
+	return s.setWorkflowFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) Restore(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.restoreFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) ListTrash(ctx context.Context, in *pb.ListTrashRequest, opts ...grpc.CallOption) (*pb.TaskListResponse, error) {
+	return s.listTrashFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) Reopen(ctx context.Context, in *pb.TaskIDRequest, opts ...grpc.CallOption) (*pb.TaskResponse, error) {
+	return s.reopenFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) GetHistory(ctx context.Context, in *pb.GetHistoryRequest, opts ...grpc.CallOption) (*pb.TaskEventsResponse, error) {
+	return s.getHistoryFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) ListAudit(ctx context.Context, in *pb.ListAuditRequest, opts ...grpc.CallOption) (*pb.TaskEventsResponse, error) {
+	return s.listAuditFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) BatchCreate(ctx context.Context, in *pb.BatchCreateRequest, opts ...grpc.CallOption) (*pb.BatchResponse, error) {
+	return s.batchCreateFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) BatchMarkDone(ctx context.Context, in *pb.BatchTaskIDsRequest, opts ...grpc.CallOption) (*pb.BatchResponse, error) {
+	return s.batchMarkDoneFn(ctx, in, opts...)
+}
+
+func (s *taskClientStub) BatchDelete(ctx context.Context, in *pb.BatchTaskIDsRequest, opts ...grpc.CallOption) (*pb.BatchResponse, error) {
+	return s.batchDeleteFn(ctx, in, opts...)
+}
+
 func setupTestRouter(stub *taskClientStub) (*gin.Engine, func()) {
 	gin.SetMode(gin.TestMode)
 
@@ -45,10 +159,29 @@ func setupTestRouter(stub *taskClientStub) (*gin.Engine, func()) {
 	taskClient = stub
 
 	router := gin.Default()
-	router.POST("/create", func(c *gin.Context) { createHandler(c, nil) })
+	router.POST("/create", createHandler)
+	router.POST(taskMethodsPath, rejectIfMatch(), taskMethodHandler)
 	router.GET("/list", func(c *gin.Context) { listHandler(c, nil) })
-	router.DELETE("/delete", func(c *gin.Context) { deleteHandler(c, nil) })
-	router.PUT("/done", func(c *gin.Context) { doneHandler(c, nil) })
+	router.DELETE("/delete", ifMatch(), deleteHandler)
+	router.PUT("/done", ifMatch(), doneHandler)
+	router.GET("/tasks/search", func(c *gin.Context) { searchHandler(c, nil) })
+	router.GET("/tasks/:id", func(c *gin.Context) { getHandler(c, nil) })
+	router.PATCH("/tasks/:id", ifMatch(), updateHandler)
+	router.GET("/tasks/:id/collaborators", listCollaboratorsHandler)
+	router.POST("/tasks/:id/collaborators", shareHandler)
+	router.DELETE("/tasks/:id/collaborators/:user_id", unshareHandler)
+	router.POST("/tasks/:id/items", ifMatch(), addItemHandler)
+	router.PUT("/tasks/:id/items/order", ifMatch(), reorderItemsHandler)
+	router.PATCH("/tasks/:id/items/:item_id", ifMatch(), updateItemHandler)
+	router.DELETE("/tasks/:id/items/:item_id", ifMatch(), removeItemHandler)
+	router.POST("/tasks/:id/transition", ifMatch(), transitionHandler)
+	router.GET("/workflow", getWorkflowHandler)
+	router.PUT("/workflow", setWorkflowHandler)
+	router.POST("/tasks/:id/reopen", ifMatch(), reopenHandler)
+	router.POST("/tasks/:id/restore", ifMatch(), restoreHandler)
+	router.GET("/trash", listTrashHandler)
+	router.GET("/tasks/:id/history", historyHandler)
+	router.GET("/audit", auditHandler)
 
 	cleanup := func() {
 		taskClient = prevClient
@@ -87,7 +220,7 @@ func TestCreateHandlerSuccess(t *testing.T) {
 	}
 
 	if got.Id != "1" || got.Title != "title" || got.Content != "content" {
-		t.Fatalf("unexpected task response: %+v", got)
+		t.Fatalf("unexpected task response: %+v", &got)
 	}
 }
 
@@ -136,7 +269,7 @@ func TestCreateHandlerGrpcError(t *testing.T) {
 
 func TestListHandlerSuccess(t *testing.T) {
 	stub := &taskClientStub{
-		listFn: func(ctx context.Context, in *emptypb.Empty, _ ...grpc.CallOption) (*pb.TaskListResponse, error) {
+		listFn: func(ctx context.Context, in *pb.ListTasksRequest, _ ...grpc.CallOption) (*pb.TaskListResponse, error) {
 			return &pb.TaskListResponse{
 				Tasks: []*pb.Task{
 					{Id: "1", Title: "t1"},
@@ -197,7 +330,7 @@ func TestDeleteHandlerSuccess(t *testing.T) {
 	}
 
 	if got.Status != "ok" {
-		t.Fatalf("unexpected status response: %+v", got)
+		t.Fatalf("unexpected status response: %+v", &got)
 	}
 }
 
@@ -231,6 +364,1277 @@ func TestDoneHandlerSuccess(t *testing.T) {
 	}
 
 	if got.Status != "done" {
-		t.Fatalf("unexpected status response: %+v", got)
+		t.Fatalf("unexpected status response: %+v", &got)
+	}
+}
+
+func TestUpdateHandlerSendsOnlyProvidedFields(t *testing.T) {
+	stub := &taskClientStub{
+		updateFn: func(ctx context.Context, in *pb.UpdateTaskRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			if in.Id != "42" {
+				t.Fatalf("unexpected id: %s", in.Id)
+			}
+			paths := in.UpdateMask.GetPaths()
+			if len(paths) != 1 || paths[0] != "title" {
+				t.Fatalf("unexpected update mask: %v", paths)
+			}
+			return &pb.TaskResponse{Task: &pb.Task{Id: in.Id, Title: in.Task.Title, Content: "old"}}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	body := `{"title":"new"}`
+	req := httptest.NewRequest(http.MethodPatch, "/tasks/42", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+
+	var got pb.Task
+	if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if got.Title != "new" || got.Content != "old" {
+		t.Fatalf("unexpected task response: %+v", &got)
+	}
+}
+
+func TestUpdateHandlerDueTimesAndRecurrence(t *testing.T) {
+	stub := &taskClientStub{
+		updateFn: func(ctx context.Context, in *pb.UpdateTaskRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			paths := in.UpdateMask.GetPaths()
+			if len(paths) != 3 || paths[0] != "due_at" || paths[1] != "remind_at" || paths[2] != "recurrence" {
+				t.Fatalf("unexpected update mask: %v", paths)
+			}
+			if in.Task.DueAt == nil || !in.Task.DueAt.AsTime().Equal(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected due_at: %v", in.Task.DueAt)
+			}
+			if in.Task.RemindAt != nil {
+				t.Fatalf("null remind_at must clear it, got %v", in.Task.RemindAt)
+			}
+			if in.Task.Recurrence != "" {
+				t.Fatalf("empty recurrence must be sent as is, got %q", in.Task.Recurrence)
+			}
+			return &pb.TaskResponse{Task: &pb.Task{Id: in.Id, DueAt: in.Task.DueAt}}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	body := `{"due_at":"2026-03-01T12:00:00+03:00","remind_at":null,"recurrence":""}`
+	req := httptest.NewRequest(http.MethodPatch, "/tasks/42", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestUpdateHandlerNoFields(t *testing.T) {
+	stub := &taskClientStub{
+		updateFn: func(ctx context.Context, in *pb.UpdateTaskRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			t.Fatal("update must not be called without fields")
+			return nil, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPatch, "/tasks/42", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.Code)
+	}
+}
+
+func TestGetHandlerSuccess(t *testing.T) {
+	stub := &taskClientStub{
+		getFn: func(ctx context.Context, in *pb.TaskIDRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			if in.Id != "7" {
+				t.Fatalf("unexpected id: %s", in.Id)
+			}
+			return &pb.TaskResponse{Task: &pb.Task{Id: "7", Title: "t7"}}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/7", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+
+	var got pb.Task
+	if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if got.Id != "7" || got.Title != "t7" {
+		t.Fatalf("unexpected task response: %+v", &got)
+	}
+}
+
+func TestGetHandlerNotFound(t *testing.T) {
+	stub := &taskClientStub{
+		getFn: func(ctx context.Context, in *pb.TaskIDRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			return nil, status.Error(codes.NotFound, "task not found")
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/missing", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", resp.Code)
+	}
+}
+
+func TestGrpcErrorsMapToHTTPStatus(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"not found", status.Error(codes.NotFound, "task not found"), http.StatusNotFound, "not_found"},
+		{"invalid argument", status.Error(codes.InvalidArgument, "malformed task id"), http.StatusBadRequest, "invalid_argument"},
+		{"conflict", status.Error(codes.AlreadyExists, "task already exists"), http.StatusConflict, "conflict"},
+		{"permission denied", status.Error(codes.PermissionDenied, "owner role required"), http.StatusForbidden, "permission_denied"},
+		{"failed precondition", status.Error(codes.FailedPrecondition, "transition not allowed"), http.StatusConflict, "conflict"},
+		{"version mismatch", versionMismatchError(t), http.StatusPreconditionFailed, "precondition_failed"},
+		{"idempotency key reused", idempotencyKeyReusedError(t), http.StatusUnprocessableEntity, "unprocessable_entity"},
+		{"plain error", errors.New("boom"), http.StatusInternalServerError, "internal"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &taskClientStub{
+				deleteFn: func(ctx context.Context, in *pb.TaskIDRequest, _ ...grpc.CallOption) (*pb.StatusResponse, error) {
+					return nil, tc.err
+				},
+			}
+
+			router, cleanup := setupTestRouter(stub)
+			defer cleanup()
+
+			req := httptest.NewRequest(http.MethodDelete, "/delete", strings.NewReader(`{"id":"1"}`))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, resp.Code)
+			}
+
+			var got errorResponse
+			if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if got.Code != tc.wantCode || got.Error == "" {
+				t.Fatalf("unexpected error body: %+v", got)
+			}
+		})
+	}
+}
+
+func versionMismatchError(t *testing.T) error {
+	t.Helper()
+	st, err := status.New(codes.FailedPrecondition, "version mismatch").
+		WithDetails(&errdetails.ErrorInfo{Reason: reqmeta.VersionMismatchReason})
+	if err != nil {
+		t.Fatalf("failed to build status: %v", err)
+	}
+	return st.Err()
+}
+
+func idempotencyKeyReusedError(t *testing.T) error {
+	t.Helper()
+	st, err := status.New(codes.InvalidArgument, "idempotency key was already used for another request").
+		WithDetails(&errdetails.ErrorInfo{Reason: reqmeta.IdempotencyKeyReusedReason})
+	if err != nil {
+		t.Fatalf("failed to build status: %v", err)
+	}
+	return st.Err()
+}
+
+func TestETags(t *testing.T) {
+	var expected []int64
+	stub := &taskClientStub{
+		getFn: func(ctx context.Context, in *pb.TaskIDRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			return &pb.TaskResponse{Task: &pb.Task{Id: in.Id, Version: 3}}, nil
+		},
+		updateFn: func(ctx context.Context, in *pb.UpdateTaskRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			expected = reqmeta.FromContext(ctx).ExpectedVersions
+			if len(expected) > 0 && !slices.Contains(expected, 3) {
+				return nil, versionMismatchError(t)
+			}
+			return &pb.TaskResponse{Task: &pb.Task{Id: in.Id, Title: in.Task.Title, Version: 4}}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	serve := func(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		var req *http.Request
+		if body == "" {
+			req = httptest.NewRequest(method, path, nil)
+		} else {
+			req = httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("get returns the version as ETag", func(t *testing.T) {
+		resp := serve(http.MethodGet, "/tasks/7", "", nil)
+		if resp.Code != http.StatusOK || resp.Header().Get("ETag") != `"3"` {
+			t.Fatalf("expected 200 with ETag \"3\", got %d %q", resp.Code, resp.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("If-None-Match", func(t *testing.T) {
+		cases := []struct {
+			header string
+			want   int
+		}{
+			{`"3"`, http.StatusNotModified},
+			{`W/"3"`, http.StatusNotModified},
+			{`"1", "3"`, http.StatusNotModified},
+			{`*`, http.StatusNotModified},
+			{`"2"`, http.StatusOK},
+		}
+		for _, tc := range cases {
+			resp := serve(http.MethodGet, "/tasks/7", "", http.Header{"If-None-Match": {tc.header}})
+			if resp.Code != tc.want {
+				t.Errorf("If-None-Match %s: expected status %d, got %d", tc.header, tc.want, resp.Code)
+			}
+			if resp.Code == http.StatusNotModified && resp.Body.Len() != 0 {
+				t.Errorf("If-None-Match %s: 304 must have no body, got %q", tc.header, resp.Body.String())
+			}
+		}
+	})
+
+	t.Run("If-Match is forwarded as the expected version", func(t *testing.T) {
+		resp := serve(http.MethodPatch, "/tasks/7", `{"title":"new"}`, http.Header{"If-Match": {`"3"`}})
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.Code)
+		}
+		if !slices.Equal(expected, []int64{3}) {
+			t.Errorf("expected version 3 to be forwarded, got %v", expected)
+		}
+		if resp.Header().Get("ETag") != `"4"` {
+			t.Errorf("expected the ETag of the new version, got %q", resp.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("without If-Match any version is changed", func(t *testing.T) {
+		for _, header := range []http.Header{nil, {"If-Match": {"*"}}} {
+			resp := serve(http.MethodPatch, "/tasks/7", `{"title":"new"}`, header)
+			if resp.Code != http.StatusOK || expected != nil {
+				t.Errorf("%v: expected 200 without expected version, got %d and %v", header, resp.Code, expected)
+			}
+		}
+	})
+
+	t.Run("If-Match lists match any of their ETags", func(t *testing.T) {
+		resp := serve(http.MethodPatch, "/tasks/7", `{"title":"new"}`, http.Header{"If-Match": {`"2", W/"3", "3"`}})
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.Code)
+		}
+		if !slices.Equal(expected, []int64{2, 3}) {
+			t.Errorf("expected versions 2 and 3 to be forwarded, got %v", expected)
+		}
+	})
+
+	t.Run("stale If-Match fails with 412", func(t *testing.T) {
+		for _, header := range []string{`"2"`, `"1", "2"`, `W/"3"`} {
+			resp := serve(http.MethodPatch, "/tasks/7", `{"title":"new"}`, http.Header{"If-Match": {header}})
+			if resp.Code != http.StatusPreconditionFailed {
+				t.Errorf("If-Match %s: expected status 412, got %d", header, resp.Code)
+			}
+		}
+	})
+
+	t.Run("malformed If-Match", func(t *testing.T) {
+		for _, header := range []string{`3`, `"abc"`, `"1", 2`} {
+			resp := serve(http.MethodPatch, "/tasks/7", `{"title":"new"}`, http.Header{"If-Match": {header}})
+			if resp.Code != http.StatusBadRequest {
+				t.Errorf("If-Match %s: expected status 400, got %d", header, resp.Code)
+			}
+		}
+	})
+}
+
+func TestCollaboratorHandlers(t *testing.T) {
+	stub := &taskClientStub{
+		shareFn: func(ctx context.Context, in *pb.ShareTaskRequest, _ ...grpc.CallOption) (*pb.Collaborator, error) {
+			if in.Id != "1" || in.UserId != "bob" || in.Role != "editor" {
+				t.Fatalf("unexpected share request: %+v", in)
+			}
+			return &pb.Collaborator{UserId: in.UserId, Role: in.Role}, nil
+		},
+		unshareFn: func(ctx context.Context, in *pb.UnshareTaskRequest, _ ...grpc.CallOption) (*pb.StatusResponse, error) {
+			if in.Id != "1" || in.UserId != "bob" {
+				t.Fatalf("unexpected unshare request: %+v", in)
+			}
+			return &pb.StatusResponse{Status: "unshared"}, nil
+		},
+		listCollaboratorsFn: func(ctx context.Context, in *pb.TaskIDRequest, _ ...grpc.CallOption) (*pb.CollaboratorsResponse, error) {
+			if in.Id == "forbidden" {
+				return nil, status.Error(codes.PermissionDenied, "viewer role required")
+			}
+			return &pb.CollaboratorsResponse{}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	t.Run("share", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/tasks/1/collaborators", strings.NewReader(`{"user_id":"bob","role":"editor"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.Code)
+		}
+		var got pb.Collaborator
+		if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if got.UserId != "bob" || got.Role != "editor" {
+			t.Fatalf("unexpected collaborator: %+v", &got)
+		}
+	})
+
+	t.Run("unshare", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/tasks/1/collaborators/bob", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusNoContent {
+			t.Fatalf("expected status 204, got %d", resp.Code)
+		}
+	})
+
+	t.Run("empty list is an array", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/1/collaborators", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusOK || strings.TrimSpace(resp.Body.String()) != "[]" {
+			t.Fatalf("expected 200 with [], got %d %s", resp.Code, resp.Body.String())
+		}
+	})
+
+	t.Run("permission denied is 403", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/forbidden/collaborators", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusForbidden {
+			t.Fatalf("expected status 403, got %d", resp.Code)
+		}
+	})
+}
+
+func TestItemHandlers(t *testing.T) {
+	task := &pb.Task{Id: "1", Items: []*pb.TaskItem{{Id: "a", Text: "milk"}, {Id: "b", Text: "bread", Position: 1}}}
+	stub := &taskClientStub{
+		addItemFn: func(ctx context.Context, in *pb.AddItemRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			if in.TaskId != "1" || in.Text != "milk" {
+				t.Fatalf("unexpected add request: %+v", in)
+			}
+			return &pb.TaskResponse{Task: task}, nil
+		},
+		updateItemFn: func(ctx context.Context, in *pb.UpdateItemRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			if in.TaskId != "1" || in.ItemId != "a" {
+				t.Fatalf("unexpected ids: %s, %s", in.TaskId, in.ItemId)
+			}
+			if len(in.UpdateMask.Paths) != 1 || in.UpdateMask.Paths[0] != "done" || !in.Item.Done {
+				t.Fatalf("only done should be sent, got mask %v and item %+v", in.UpdateMask.Paths, in.Item)
+			}
+			return &pb.TaskResponse{Task: task}, nil
+		},
+		reorderItemsFn: func(ctx context.Context, in *pb.ReorderItemsRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			if strings.Join(in.ItemIds, ",") != "b,a" {
+				t.Fatalf("unexpected order: %v", in.ItemIds)
+			}
+			return &pb.TaskResponse{Task: task}, nil
+		},
+		removeItemFn: func(ctx context.Context, in *pb.ItemIDRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			if in.TaskId != "1" || in.ItemId != "b" {
+				t.Fatalf("unexpected remove request: %+v", in)
+			}
+			return &pb.TaskResponse{Task: task}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	cases := []struct {
+		method, path, body string
+		wantStatus         int
+	}{
+		{http.MethodPost, "/tasks/1/items", `{"text":"milk"}`, http.StatusOK},
+		{http.MethodPatch, "/tasks/1/items/a", `{"done":true}`, http.StatusOK},
+		{http.MethodPatch, "/tasks/1/items/a", `{}`, http.StatusBadRequest},
+		{http.MethodPut, "/tasks/1/items/order", `{"item_ids":["b","a"]}`, http.StatusOK},
+		{http.MethodDelete, "/tasks/1/items/b", "", http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, resp.Code)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var got pb.Task
+			if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if len(got.Items) != 2 {
+				t.Fatalf("the task should embed its items, got %+v", &got)
+			}
+		})
+	}
+}
+
+func TestListHandlerForwardsQuery(t *testing.T) {
+	stub := &taskClientStub{
+		listFn: func(ctx context.Context, in *pb.ListTasksRequest, _ ...grpc.CallOption) (*pb.TaskListResponse, error) {
+			if in.PageSize != 10 || in.PageToken != "abc" {
+				t.Fatalf("unexpected paging: %+v", in)
+			}
+			if in.Done == nil || !*in.Done {
+				t.Fatalf("expected done filter, got %+v", in)
+			}
+			if in.CreatedAfter == nil || in.CreatedAfter.AsTime().Year() != 2025 || in.CreatedBefore != nil {
+				t.Fatalf("unexpected created_at range: %+v", in)
+			}
+			if in.Sort != pb.SortOrder_SORT_ORDER_CREATED_DESC {
+				t.Fatalf("unexpected sort: %v", in.Sort)
+			}
+			return &pb.TaskListResponse{Tasks: []*pb.Task{{Id: "1"}}, NextPageToken: "next"}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/list?page_size=10&page_token=abc&done=true&created_after=2025-01-01T00:00:00Z&sort=desc", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+
+	if got := resp.Header().Get("X-Next-Page-Token"); got != "next" {
+		t.Fatalf("unexpected next page token: %q", got)
+	}
+}
+
+func TestListHandlerForwardsDueFilters(t *testing.T) {
+	stub := &taskClientStub{
+		listFn: func(ctx context.Context, in *pb.ListTasksRequest, _ ...grpc.CallOption) (*pb.TaskListResponse, error) {
+			if in.Overdue || in.DueWithin.AsDuration() != 36*time.Hour {
+				t.Fatalf("unexpected due filters: %+v", in)
+			}
+			return &pb.TaskListResponse{}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/list?overdue=false&due_within=36h", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+}
+
+func TestListHandlerForwardsStatuses(t *testing.T) {
+	stub := &taskClientStub{
+		listFn: func(ctx context.Context, in *pb.ListTasksRequest, _ ...grpc.CallOption) (*pb.TaskListResponse, error) {
+			if strings.Join(in.Statuses, ",") != "todo,in_progress,blocked" {
+				t.Fatalf("unexpected statuses: %v", in.Statuses)
+			}
+			return &pb.TaskListResponse{}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/list?status=todo,in_progress&status=blocked", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+}
+
+func TestListHandlerBadQuery(t *testing.T) {
+	stub := &taskClientStub{
+		listFn: func(ctx context.Context, in *pb.ListTasksRequest, _ ...grpc.CallOption) (*pb.TaskListResponse, error) {
+			t.Fatal("list must not be called with a bad query")
+			return nil, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	for _, query := range []string{"page_size=-1", "done=maybe", "created_before=yesterday", "sort=random", "overdue=soon", "due_within=-1h", "due_within=tomorrow"} {
+		req := httptest.NewRequest(http.MethodGet, "/list?"+query, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", query, resp.Code)
+		}
+	}
+}
+
+func TestSearchHandlerSuccess(t *testing.T) {
+	stub := &taskClientStub{
+		searchFn: func(ctx context.Context, in *pb.SearchTasksRequest, _ ...grpc.CallOption) (*pb.SearchTasksResponse, error) {
+			if in.Query != "report" || in.PageSize != 5 {
+				t.Fatalf("unexpected search request: %+v", in)
+			}
+			return &pb.SearchTasksResponse{Results: []*pb.SearchResult{
+				{Task: &pb.Task{Id: "1", Title: "report"}, Rank: 0.5, TitleSnippet: "<b>report</b>"},
+			}}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/search?q=report&limit=5", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+
+	var got []*pb.SearchResult
+	if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(got) != 1 || got[0].Task.Id != "1" || got[0].TitleSnippet != "<b>report</b>" {
+		t.Fatalf("unexpected search response: %+v", got)
+	}
+}
+
+func TestSearchHandlerMissingQuery(t *testing.T) {
+	stub := &taskClientStub{
+		searchFn: func(ctx context.Context, in *pb.SearchTasksRequest, _ ...grpc.CallOption) (*pb.SearchTasksResponse, error) {
+			t.Fatal("search must not be called without a query")
+			return nil, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/search", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.Code)
+	}
+}
+
+func TestHandlersUseRequestContext(t *testing.T) {
+	stub := &taskClientStub{
+		listFn: func(ctx context.Context, in *pb.ListTasksRequest, _ ...grpc.CallOption) (*pb.TaskListResponse, error) {
+			deadline, ok := ctx.Deadline()
+			if !ok || time.Until(deadline) > requestTimeout {
+				t.Fatalf("expected a deadline within %v, got %v", requestTimeout, deadline)
+			}
+			if ctx.Err() != context.Canceled {
+				t.Fatalf("expected the request cancellation to propagate, got %v", ctx.Err())
+			}
+			return nil, status.FromContextError(ctx.Err()).Err()
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "/list", nil).WithContext(ctx)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", resp.Code)
+	}
+}
+
+func TestRequestMetaCorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(requestMeta())
+	router.GET("/meta", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"actor":          c.GetString(actorKey),
+			"correlation_id": c.GetString(correlationIDKey),
+			"forwarded":      reqmeta.FromContext(c.Request.Context()).Actor,
+			"idempotency":    reqmeta.FromContext(c.Request.Context()).IdempotencyKey,
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/meta", nil)
+	req.Header.Set(correlationIDHeader, "corr-1")
+	req.Header.Set(actorHeader, "alice")
+	req.Header.Set(idempotencyKeyHeader, "key-1")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if got := resp.Header().Get(correlationIDHeader); got != "corr-1" {
+		t.Fatalf("expected the client correlation id to be echoed, got %q", got)
+	}
+	if !strings.Contains(resp.Body.String(), `"actor":"alice"`) || !strings.Contains(resp.Body.String(), `"forwarded":"alice"`) {
+		t.Fatalf("unexpected request metadata: %s", resp.Body.String())
+	}
+	if !strings.Contains(resp.Body.String(), `"idempotency":"key-1"`) {
+		t.Fatalf("expected the idempotency key to be forwarded: %s", resp.Body.String())
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/meta", nil))
+
+	if resp.Header().Get(correlationIDHeader) == "" {
+		t.Fatal("expected a generated correlation id")
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/healthz", healthzHandler)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var kafkaErr error
+	router := gin.New()
+	router.GET("/readyz", readyzHandler([]readinessCheck{
+		{name: "db", check: func(ctx context.Context) error { return nil }},
+		{name: "kafka", check: func(ctx context.Context) error { return kafkaErr }},
+	}))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	kafkaErr = errors.New("connection refused")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if resp.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", resp.Code)
+	}
+
+	var body struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Status != "unavailable" || body.Checks["db"] != "ok" || body.Checks["kafka"] != "connection refused" {
+		t.Fatalf("unexpected readiness report: %+v", body)
+	}
+}
+
+type authClientStub struct {
+	authenticateFn func(ctx context.Context, in *pb.AuthenticateRequest, opts ...grpc.CallOption) (*pb.Principal, error)
+	createKeyFn    func(ctx context.Context, in *pb.CreateAPIKeyRequest, opts ...grpc.CallOption) (*pb.APIKey, error)
+	revokeKeyFn    func(ctx context.Context, in *pb.APIKeyIDRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+func (s *authClientStub) Authenticate(ctx context.Context, in *pb.AuthenticateRequest, opts ...grpc.CallOption) (*pb.Principal, error) {
+	return s.authenticateFn(ctx, in, opts...)
+}
+
+func (s *authClientStub) CreateAPIKey(ctx context.Context, in *pb.CreateAPIKeyRequest, opts ...grpc.CallOption) (*pb.APIKey, error) {
+	return s.createKeyFn(ctx, in, opts...)
+}
+
+func (s *authClientStub) RevokeAPIKey(ctx context.Context, in *pb.APIKeyIDRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	return s.revokeKeyFn(ctx, in, opts...)
+}
+
+func setupAuthRouter(stub *authClientStub) (*gin.Engine, func()) {
+	gin.SetMode(gin.TestMode)
+
+	prevClient := authClient
+	authClient = stub
+
+	router := gin.New()
+	router.Use(requestMeta())
+	authed := router.Group("/", authenticate(auth.NewJWTVerifier(auth.JWTConfig{HS256Secret: []byte("secret")})))
+	authed.GET("/whoami", func(c *gin.Context) {
+		cred, _ := auth.CredentialFromContext(c.Request.Context())
+		c.JSON(http.StatusOK, gin.H{
+			"subject":    auth.FromContext(c.Request.Context()).Subject,
+			"actor":      reqmeta.FromContext(c.Request.Context()).Actor,
+			"credential": cred.Scheme,
+		})
+	})
+	authed.POST("/api-keys", createAPIKeyHandler)
+	authed.DELETE("/api-keys/:id", revokeAPIKeyHandler)
+
+	return router, func() { authClient = prevClient }
+}
+
+func signToken(t *testing.T, secret, subject string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   subject,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthenticateMiddleware(t *testing.T) {
+	stub := &authClientStub{
+		authenticateFn: func(ctx context.Context, in *pb.AuthenticateRequest, _ ...grpc.CallOption) (*pb.Principal, error) {
+			cred, _ := auth.CredentialFromContext(ctx)
+			if cred.Value != "ck_valid" {
+				return nil, status.Error(codes.Unauthenticated, "unknown or revoked api key")
+			}
+			return &pb.Principal{Subject: "bob", Scheme: auth.SchemeAPIKey}, nil
+		},
+	}
+	router, cleanup := setupAuthRouter(stub)
+	defer cleanup()
+
+	tests := []struct {
+		name        string
+		header      string
+		value       string
+		wantStatus  int
+		wantSubject string
+	}{
+		{"valid jwt", "Authorization", "Bearer " + signToken(t, "secret", "alice"), http.StatusOK, "alice"},
+		{"jwt with wrong secret", "Authorization", "Bearer " + signToken(t, "other", "alice"), http.StatusUnauthorized, ""},
+		{"api key header", apiKeyHeader, "ck_valid", http.StatusOK, "bob"},
+		{"api key scheme", "Authorization", "ApiKey ck_valid", http.StatusOK, "bob"},
+		{"revoked api key", apiKeyHeader, "ck_revoked", http.StatusUnauthorized, ""},
+		{"unsupported scheme", "Authorization", "Basic YWxpY2U6c2VjcmV0", http.StatusUnauthorized, ""},
+		{"no credentials", "", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			req.Header.Set(actorHeader, "spoofed")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, resp.Code, resp.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body["subject"] != tc.wantSubject || body["actor"] != tc.wantSubject {
+				t.Fatalf("expected subject and actor %q, got %v", tc.wantSubject, body)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandlers(t *testing.T) {
+	stub := &authClientStub{
+		authenticateFn: func(ctx context.Context, in *pb.AuthenticateRequest, _ ...grpc.CallOption) (*pb.Principal, error) {
+			if cred, _ := auth.CredentialFromContext(ctx); cred.Value == "ck_valid" {
+				return &pb.Principal{Subject: "alice", Scheme: auth.SchemeAPIKey}, nil
+			}
+			return nil, status.Error(codes.Unauthenticated, "unexpected api key")
+		},
+		createKeyFn: func(ctx context.Context, in *pb.CreateAPIKeyRequest, _ ...grpc.CallOption) (*pb.APIKey, error) {
+			if in.Name != "ci" {
+				t.Fatalf("unexpected payload: %+v", in)
+			}
+			cred, ok := auth.CredentialFromContext(ctx)
+			if !ok {
+				t.Fatalf("expected the caller credential to be forwarded")
+			}
+			if cred.Scheme != auth.SchemeBearer {
+				return nil, status.Error(codes.PermissionDenied, "api keys can only be created with a bearer token")
+			}
+			return &pb.APIKey{Id: "key-1", Name: in.Name, Prefix: "ck_abcde", Key: "ck_abcdefgh"}, nil
+		},
+		revokeKeyFn: func(ctx context.Context, in *pb.APIKeyIDRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+			if in.Id != "key-1" {
+				return nil, status.Error(codes.NotFound, "api key not found")
+			}
+			return &emptypb.Empty{}, nil
+		},
+	}
+	router, cleanup := setupAuthRouter(stub)
+	defer cleanup()
+
+	bearer := "Bearer " + signToken(t, "secret", "alice")
+
+	req := httptest.NewRequest(http.MethodPost, "/api-keys", strings.NewReader(`{"name":"ci"}`))
+	req.Header.Set("Authorization", bearer)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if !strings.Contains(resp.Body.String(), `"key":"ck_abcdefgh"`) {
+		t.Fatalf("expected the key in the response, got %s", resp.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api-keys", strings.NewReader(`{"name":"ci"}`))
+	req.Header.Set(apiKeyHeader, "ck_valid")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusForbidden {
+		t.Fatalf("api key principal: expected status 403, got %d: %s", resp.Code, resp.Body.String())
+	}
+
+	for id, want := range map[string]int{"key-1": http.StatusNoContent, "key-2": http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodDelete, "/api-keys/"+id, nil)
+		req.Header.Set("Authorization", bearer)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != want {
+			t.Fatalf("revoke %s: expected status %d, got %d", id, want, resp.Code)
+		}
+	}
+}
+
+func TestWorkflowHandlers(t *testing.T) {
+	stub := &taskClientStub{
+		transitionFn: func(ctx context.Context, in *pb.TransitionTaskRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			if in.Id != "1" || in.Status != "in_progress" {
+				t.Fatalf("unexpected transition request: %+v", in)
+			}
+			return &pb.TaskResponse{Task: &pb.Task{Id: "1", Status: in.Status, StatusChangedBy: "alice"}}, nil
+		},
+		getWorkflowFn: func(ctx context.Context, in *emptypb.Empty, _ ...grpc.CallOption) (*pb.Workflow, error) {
+			return &pb.Workflow{WorkspaceId: "team", Transitions: []*pb.WorkflowTransition{{From: "todo", To: "done"}}}, nil
+		},
+		setWorkflowFn: func(ctx context.Context, in *pb.SetWorkflowRequest, _ ...grpc.CallOption) (*pb.Workflow, error) {
+			if len(in.Transitions) != 2 || in.Transitions[1].From != "done" || in.Transitions[1].To != "todo" {
+				t.Fatalf("unexpected workflow: %+v", in.Transitions)
+			}
+			return &pb.Workflow{WorkspaceId: "team", Transitions: in.Transitions}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	cases := []struct {
+		name, method, path, body, want string
+	}{
+		{"transition", http.MethodPost, "/tasks/1/transition", `{"status":"in_progress"}`, `"status":"in_progress"`},
+		{"get workflow", http.MethodGet, "/workflow", "", `"transitions":[{"from":"todo","to":"done"}]`},
+		{"set workflow", http.MethodPut, "/workflow", `{"transitions":[{"from":"todo","to":"done"},{"from":"done","to":"todo"}]}`, `"workspace_id":"team"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+			}
+			if !strings.Contains(resp.Body.String(), tc.want) {
+				t.Fatalf("expected %s in %s", tc.want, resp.Body.String())
+			}
+		})
+	}
+
+	t.Run("transition bad json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/tasks/1/transition", strings.NewReader("{"))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400, got %d", resp.Code)
+		}
+	})
+}
+
+func TestTrashHandlers(t *testing.T) {
+	stub := &taskClientStub{
+		listTrashFn: func(ctx context.Context, in *pb.ListTrashRequest, _ ...grpc.CallOption) (*pb.TaskListResponse, error) {
+			if in.PageSize != 2 || in.PageToken != "tok" {
+				t.Fatalf("unexpected trash request: %+v", in)
+			}
+			return &pb.TaskListResponse{
+				Tasks:         []*pb.Task{{Id: "1", DeletedAt: timestamppb.Now()}},
+				NextPageToken: "next",
+			}, nil
+		},
+		restoreFn: func(ctx context.Context, in *pb.TaskIDRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			if in.Id == "2" {
+				return nil, status.Error(codes.NotFound, "task not found")
+			}
+			return &pb.TaskResponse{Task: &pb.Task{Id: in.Id, Title: "restored"}}, nil
+		},
+		reopenFn: func(ctx context.Context, in *pb.TaskIDRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			return &pb.TaskResponse{Task: &pb.Task{Id: in.Id, Status: "todo"}}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	t.Run("list trash", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/trash?page_size=2&page_token=tok", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+		}
+		if resp.Header().Get("X-Next-Page-Token") != "next" {
+			t.Fatalf("expected next page token, got %q", resp.Header().Get("X-Next-Page-Token"))
+		}
+		if !strings.Contains(resp.Body.String(), `"deleted_at"`) {
+			t.Fatalf("expected deleted_at in %s", resp.Body.String())
+		}
+	})
+
+	t.Run("invalid page size", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/trash?page_size=-1", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400, got %d", resp.Code)
+		}
+	})
+
+	cases := []struct {
+		name, path string
+		code       int
+		want       string
+	}{
+		{"restore", "/tasks/1/restore", http.StatusOK, `"title":"restored"`},
+		{"restore missing", "/tasks/2/restore", http.StatusNotFound, "task not found"},
+		{"reopen", "/tasks/1/reopen", http.StatusOK, `"status":"todo"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != tc.code {
+				t.Fatalf("expected status %d, got %d: %s", tc.code, resp.Code, resp.Body.String())
+			}
+			if !strings.Contains(resp.Body.String(), tc.want) {
+				t.Fatalf("expected %s in %s", tc.want, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestHistoryHandlers(t *testing.T) {
+	event := &pb.TaskEvent{
+		Id:         7,
+		TaskId:     "1",
+		Type:       "task.updated",
+		Actor:      "alice",
+		OccurredAt: timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Changes:    []*pb.FieldChange{{Field: "title", Before: `"Old"`, After: `"New"`}, {Field: "due_at", Before: `"2024-01-01T00:00:00Z"`}},
+	}
+	stub := &taskClientStub{
+		getHistoryFn: func(ctx context.Context, in *pb.GetHistoryRequest, _ ...grpc.CallOption) (*pb.TaskEventsResponse, error) {
+			if in.Id != "1" || in.PageSize != 5 || in.PageToken != "tok" {
+				t.Fatalf("unexpected history request: %+v", in)
+			}
+			return &pb.TaskEventsResponse{Events: []*pb.TaskEvent{event}, NextPageToken: "next"}, nil
+		},
+		listAuditFn: func(ctx context.Context, in *pb.ListAuditRequest, _ ...grpc.CallOption) (*pb.TaskEventsResponse, error) {
+			if in.Actor == "mallory" {
+				return nil, status.Error(codes.PermissionDenied, "permission denied")
+			}
+			if in.TaskId != "1" || in.Actor != "alice" || len(in.Types) != 2 || in.Types[1] != "task.done" {
+				t.Fatalf("unexpected audit request: %+v", in)
+			}
+			if in.Since == nil || !in.Since.AsTime().Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || in.Until != nil {
+				t.Fatalf("unexpected audit bounds: %v %v", in.Since, in.Until)
+			}
+			return &pb.TaskEventsResponse{}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	t.Run("history", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/1/history?page_size=5&page_token=tok", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+		}
+		if resp.Header().Get("X-Next-Page-Token") != "next" {
+			t.Fatalf("expected next page token, got %q", resp.Header().Get("X-Next-Page-Token"))
+		}
+		var events []struct {
+			ID      int64  `json:"id"`
+			Actor   string `json:"actor"`
+			Changes []struct {
+				Field  string `json:"field"`
+				Before any    `json:"before"`
+				After  any    `json:"after"`
+			} `json:"changes"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &events); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(events) != 1 || events[0].ID != 7 || events[0].Actor != "alice" || len(events[0].Changes) != 2 {
+			t.Fatalf("unexpected events: %s", resp.Body.String())
+		}
+		if c := events[0].Changes[0]; c.Before != "Old" || c.After != "New" {
+			t.Errorf("expected values as JSON, got %s", resp.Body.String())
+		}
+		if c := events[0].Changes[1]; c.After != nil {
+			t.Errorf("expected no value of an unset field, got %v", c.After)
+		}
+	})
+
+	t.Run("audit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/audit?task_id=1&actor=alice&type=task.updated,task.done&since=2024-01-01T00:00:00Z", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+		}
+		if resp.Body.String() != "[]" {
+			t.Fatalf("expected an empty array, got %s", resp.Body.String())
+		}
+	})
+
+	cases := []struct {
+		name, path string
+		code       int
+	}{
+		{"audit forbidden", "/audit?actor=mallory", http.StatusForbidden},
+		{"audit bad since", "/audit?since=yesterday", http.StatusBadRequest},
+		{"history bad page size", "/tasks/1/history?page_size=x", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			if resp.Code != tc.code {
+				t.Fatalf("expected status %d, got %d: %s", tc.code, resp.Code, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestBatchHandler(t *testing.T) {
+	stub := &taskClientStub{
+		batchCreateFn: func(ctx context.Context, in *pb.BatchCreateRequest, _ ...grpc.CallOption) (*pb.BatchResponse, error) {
+			if len(in.Tasks) != 2 || in.Tasks[1].Title != "b" || in.Mode != pb.BatchMode_BATCH_MODE_ALL_OR_NOTHING {
+				t.Fatalf("unexpected batch create request: %+v", in)
+			}
+			return &pb.BatchResponse{Results: []*pb.BatchItemResult{
+				{Id: "1", Task: &pb.Task{Id: "1", Title: "a"}},
+				{Id: "2", Task: &pb.Task{Id: "2", Title: "b"}},
+			}}, nil
+		},
+		batchMarkDoneFn: func(ctx context.Context, in *pb.BatchTaskIDsRequest, _ ...grpc.CallOption) (*pb.BatchResponse, error) {
+			if len(in.Ids) != 2 || in.Mode != pb.BatchMode_BATCH_MODE_BEST_EFFORT {
+				t.Fatalf("unexpected batch done request: %+v", in)
+			}
+			return &pb.BatchResponse{Results: []*pb.BatchItemResult{
+				{Id: "1", Task: &pb.Task{Id: "1", Status: "done"}},
+				{Id: "2", Code: int32(codes.NotFound), Error: "task not found"},
+			}}, nil
+		},
+		batchDeleteFn: func(ctx context.Context, in *pb.BatchTaskIDsRequest, _ ...grpc.CallOption) (*pb.BatchResponse, error) {
+			return nil, status.Error(codes.InvalidArgument, "a batch holds at most 100 items")
+		},
+		getFn: func(ctx context.Context, in *pb.TaskIDRequest, _ ...grpc.CallOption) (*pb.TaskResponse, error) {
+			return &pb.TaskResponse{Task: &pb.Task{Id: in.Id}}, nil
+		},
+	}
+
+	router, cleanup := setupTestRouter(stub)
+	defer cleanup()
+
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	type result struct {
+		ID     string   `json:"id"`
+		Status int      `json:"status"`
+		Task   *pb.Task `json:"task"`
+		Code   string   `json:"code"`
+		Error  string   `json:"error"`
+	}
+	decode := func(t *testing.T, resp *httptest.ResponseRecorder) []result {
+		t.Helper()
+		if resp.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", resp.Code, resp.Body.String())
+		}
+		var body struct {
+			Results []result `json:"results"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return body.Results
+	}
+
+	t.Run("create", func(t *testing.T) {
+		results := decode(t, serve("/tasks:batch", `{"operation":"create","tasks":[{"title":"a"},{"title":"b"}]}`))
+		if len(results) != 2 || results[1].Status != http.StatusOK || results[1].Task.GetTitle() != "b" {
+			t.Fatalf("unexpected results: %+v", results)
+		}
+	})
+
+	t.Run("done with a failed item", func(t *testing.T) {
+		results := decode(t, serve("/tasks:batch", `{"operation":"done","mode":"best_effort","ids":["1","2"]}`))
+		if len(results) != 2 || results[0].Status != http.StatusOK || results[0].Task.GetStatus() != "done" {
+			t.Fatalf("unexpected results: %+v", results)
+		}
+		if r := results[1]; r.ID != "2" || r.Status != http.StatusNotFound || r.Code != "not_found" || r.Task != nil {
+			t.Errorf("expected a not found item, got %+v", r)
+		}
+	})
+
+	t.Run("If-Match is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/tasks:batch", strings.NewReader(`{"operation":"done","ids":["1"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", resp.Code)
+		}
+	})
+
+	t.Run("routes", func(t *testing.T) {
+		if resp := serve("/tasks:archive", `{}`); resp.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for an unknown method, got %d", resp.Code)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("expected status 200 for a task, got %d", resp.Code)
+		}
+	})
+
+	cases := []struct {
+		name, body string
+		code       int
+	}{
+		{"rejected batch", `{"operation":"delete","ids":["1"]}`, http.StatusBadRequest},
+		{"unknown operation", `{"operation":"archive","ids":["1"]}`, http.StatusBadRequest},
+		{"unknown mode", `{"operation":"done","mode":"some","ids":["1"]}`, http.StatusBadRequest},
+		{"ids for create", `{"operation":"create","ids":["1"]}`, http.StatusBadRequest},
+		{"tasks for delete", `{"operation":"delete","tasks":[{"title":"a"}]}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if resp := serve("/tasks:batch", tc.body); resp.Code != tc.code {
+				t.Fatalf("expected status %d, got %d: %s", tc.code, resp.Code, resp.Body.String())
+			}
+		})
 	}
 }
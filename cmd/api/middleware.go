@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+)
+
+const (
+	correlationIDHeader  = "X-Correlation-ID"
+	actorHeader          = "X-Actor"
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// gin context keys set by requestMeta.
+	correlationIDKey = "correlation_id"
+	actorKey         = "actor"
+)
+
+// requestMeta gives every request a correlation ID, reusing the one sent by
+// the client if any, and records who made the request. Both are also put
+// into the request context to be forwarded to the DB service, along with
+// the Idempotency-Key header.
+func requestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(correlationIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		c.Set(correlationIDKey, id)
+		c.Header(correlationIDHeader, id)
+
+		actor := c.GetHeader(actorHeader)
+		if actor == "" {
+			actor = "anonymous"
+		}
+		c.Set(actorKey, actor)
+
+		ctx := reqmeta.NewContext(c.Request.Context(), reqmeta.Meta{
+			Actor:          actor,
+			CorrelationID:  id,
+			IdempotencyKey: c.GetHeader(idempotencyKeyHeader),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+)
+
+// listTrashHandler returns one page of the deleted tasks of the caller,
+// taking page_size and page_token like listHandler.
+func listTrashHandler(c *gin.Context) {
+	size, err := parsePageSize(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	req := &pb.ListTrashRequest{PageSize: size, PageToken: c.Query("page_token")}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.ListTrash(ctx, req)
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	if res.NextPageToken != "" {
+		c.Header("X-Next-Page-Token", res.NextPageToken)
+	}
+	tasks := res.Tasks
+	if tasks == nil {
+		tasks = []*pb.Task{}
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+func restoreHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.Restore(ctx, &pb.TaskIDRequest{Id: c.Param("id")})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeTask(c, res.Task)
+}
+
+func reopenHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.Reopen(ctx, &pb.TaskIDRequest{Id: c.Param("id")})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeTask(c, res.Task)
+}
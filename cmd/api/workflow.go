@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func transitionHandler(c *gin.Context) {
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.Transition(ctx, &pb.TransitionTaskRequest{Id: c.Param("id"), Status: req.Status})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	writeTask(c, res.Task)
+}
+
+func getWorkflowHandler(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.GetWorkflow(ctx, &emptypb.Empty{})
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// setWorkflowHandler replaces the transitions of the workspace. An empty
+// list restores the default workflow.
+func setWorkflowHandler(c *gin.Context) {
+	var req struct {
+		Transitions []struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		writeError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	in := &pb.SetWorkflowRequest{}
+	for _, t := range req.Transitions {
+		in.Transitions = append(in.Transitions, &pb.WorkflowTransition{From: t.From, To: t.To})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestTimeout)
+	defer cancel()
+
+	res, err := taskClient.SetWorkflow(ctx, in)
+	if err != nil {
+		writeGRPCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AuthServer serves AuthService. Every call has already been authenticated
+// by auth.UnaryServerInterceptor, so the principal is in the context.
+type AuthServer struct {
+	pb.UnimplementedAuthServiceServer
+	service *services.AuthService
+}
+
+func (s *AuthServer) Authenticate(ctx context.Context, req *pb.AuthenticateRequest) (*pb.Principal, error) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return nil, toStatusError(services.ErrUnauthenticated)
+	}
+	return &pb.Principal{Subject: p.Subject, WorkspaceId: p.WorkspaceID, Scheme: p.Scheme, Admin: p.Admin}, nil
+}
+
+func (s *AuthServer) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.APIKey, error) {
+	key, secret, err := s.service.CreateAPIKey(ctx, req.Name)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.APIKey{
+		Id:        key.ID.String(),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		CreatedAt: timestamppb.New(key.CreatedAt),
+		Key:       secret,
+	}, nil
+}
+
+func (s *AuthServer) RevokeAPIKey(ctx context.Context, req *pb.APIKeyIDRequest) (*emptypb.Empty, error) {
+	id, err := services.ParseAPIKeyID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	if err := s.service.RevokeAPIKey(ctx, id); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
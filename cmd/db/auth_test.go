@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockAPIKeyRepository struct {
+	created []*models.APIKey
+}
+
+func (m *mockAPIKeyRepository) Create(ctx context.Context, key *models.APIKey, hash []byte) error {
+	key.ID = uuid.New()
+	m.created = append(m.created, key)
+	return nil
+}
+
+func (m *mockAPIKeyRepository) GetActiveByHash(ctx context.Context, hash []byte) (*models.APIKey, error) {
+	return nil, repositories.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID, owner string) error {
+	return repositories.ErrAPIKeyNotFound
+}
+
+func TestAuthServer_CreateAPIKey(t *testing.T) {
+	repo := &mockAPIKeyRepository{}
+	server := &AuthServer{service: services.NewAuthService(repo, nil)}
+	principal := func(scheme string) context.Context {
+		return auth.NewContext(context.Background(), &auth.Principal{Subject: "alice", WorkspaceID: "ws-1", Scheme: scheme})
+	}
+
+	t.Run("ключ выдаётся по bearer-токену", func(t *testing.T) {
+		resp, err := server.CreateAPIKey(principal(auth.SchemeBearer), &pb.CreateAPIKeyRequest{Name: "ci"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if resp.Key == "" || len(repo.created) != 1 || repo.created[0].Owner != "alice" {
+			t.Errorf("ожидался ключ alice, получено %+v", resp)
+		}
+	})
+
+	t.Run("ключ не выдаётся по другому ключу", func(t *testing.T) {
+		_, err := server.CreateAPIKey(principal(auth.SchemeAPIKey), &pb.CreateAPIKeyRequest{Name: "ci"})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("ожидался код PermissionDenied, получено %v", err)
+		}
+		if len(repo.created) != 1 {
+			t.Errorf("ключ не должен создаваться, создано %d", len(repo.created))
+		}
+	})
+}
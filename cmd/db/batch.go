@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/grpc/status"
+)
+
+func (s *TaskServer) BatchCreate(ctx context.Context, req *pb.BatchCreateRequest) (*pb.BatchResponse, error) {
+	drafts := make([]models.Task, len(req.Tasks))
+	for i, t := range req.Tasks {
+		drafts[i] = fromPBDraft(t)
+	}
+	results, err := s.service.BatchCreate(ctx, drafts, fromPBBatchMode(req.Mode))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPBBatch(nil, results), nil
+}
+
+func (s *TaskServer) BatchMarkDone(ctx context.Context, req *pb.BatchTaskIDsRequest) (*pb.BatchResponse, error) {
+	ids, err := parseIDs(req.Ids)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	results, err := s.service.BatchMarkDone(ctx, ids, fromPBBatchMode(req.Mode))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPBBatch(req.Ids, results), nil
+}
+
+func (s *TaskServer) BatchDelete(ctx context.Context, req *pb.BatchTaskIDsRequest) (*pb.BatchResponse, error) {
+	ids, err := parseIDs(req.Ids)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	results, err := s.service.BatchDelete(ctx, ids, fromPBBatchMode(req.Mode))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPBBatch(req.Ids, results), nil
+}
+
+// parseIDs parses the task IDs of a batch. One malformed ID fails the batch.
+func parseIDs(raw []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(raw))
+	for i, r := range raw {
+		id, err := services.ParseID(r)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func fromPBBatchMode(mode pb.BatchMode) models.BatchMode {
+	if mode == pb.BatchMode_BATCH_MODE_BEST_EFFORT {
+		return models.BatchBestEffort
+	}
+	return models.BatchAllOrNothing
+}
+
+// toPBBatch converts the results of a batch about the tasks with the given
+// IDs, or of one that created tasks if ids is nil.
+func toPBBatch(ids []string, results []models.BatchResult) *pb.BatchResponse {
+	resp := &pb.BatchResponse{Results: make([]*pb.BatchItemResult, len(results))}
+	for i, r := range results {
+		item := &pb.BatchItemResult{}
+		if ids != nil {
+			item.Id = ids[i]
+		}
+		if r.Task != nil {
+			item.Id = r.Task.ID.String()
+			item.Task = toPBTask(r.Task)
+		}
+		if r.Err != nil {
+			st := status.Convert(toStatusError(r.Err))
+			item.Code, item.Error = int32(st.Code()), st.Message()
+		}
+		resp.Results[i] = item
+	}
+	return resp
+}
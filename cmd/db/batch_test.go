@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/repositories/memory"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTaskServer_Batch(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	var invalidations [][]string
+	cache := &mockTaskCache{
+		deleteTasksFn: func(ctx context.Context, ids ...string) error {
+			invalidations = append(invalidations, ids)
+			return nil
+		},
+	}
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), cache)
+	server := &TaskServer{service: service}
+	alice := userContext("alice", "ws-1")
+	bob := userContext("bob", "ws-1")
+
+	codesOf := func(resp *pb.BatchResponse) []codes.Code {
+		var got []codes.Code
+		for _, r := range resp.Results {
+			got = append(got, codes.Code(r.Code))
+		}
+		return got
+	}
+	listed := func() int {
+		page, err := server.List(alice, &pb.ListTasksRequest{})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		return len(page.Tasks)
+	}
+
+	var ids []string
+	t.Run("создание", func(t *testing.T) {
+		resp, err := server.BatchCreate(alice, &pb.BatchCreateRequest{Tasks: []*pb.CreateTaskRequest{{Title: "Первая"}, {Title: "Вторая"}}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		for _, r := range resp.Results {
+			if r.Code != 0 || r.Task == nil || r.Id != r.Task.Id {
+				t.Fatalf("ожидалась созданная задача, получено %v", r)
+			}
+			ids = append(ids, r.Id)
+		}
+		if len(invalidations) != 1 {
+			t.Errorf("ожидалась одна инвалидация кеша, получено %d", len(invalidations))
+		}
+	})
+
+	t.Run("неверный элемент отменяет пакет", func(t *testing.T) {
+		resp, err := server.BatchCreate(alice, &pb.BatchCreateRequest{Tasks: []*pb.CreateTaskRequest{{Title: "Третья"}, {Title: " "}}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got := codesOf(resp); got[0] != codes.Aborted || got[1] != codes.InvalidArgument {
+			t.Errorf("ожидались Aborted и InvalidArgument, получено %v", got)
+		}
+		if n := listed(); n != 2 {
+			t.Errorf("ожидалось 2 задачи, получено %d", n)
+		}
+	})
+
+	t.Run("лучшее усилие", func(t *testing.T) {
+		invalidations = nil
+		missing := uuid.NewString()
+		resp, err := server.BatchMarkDone(alice, &pb.BatchTaskIDsRequest{
+			Ids:  []string{ids[0], missing},
+			Mode: pb.BatchMode_BATCH_MODE_BEST_EFFORT,
+		})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got := codesOf(resp); got[0] != codes.OK || got[1] != codes.NotFound {
+			t.Errorf("ожидались OK и NotFound, получено %v", got)
+		}
+		if resp.Results[0].Task.GetStatus() != "done" || resp.Results[1].Id != missing {
+			t.Errorf("неожиданные результаты: %v", resp.Results)
+		}
+		if len(invalidations) != 1 || len(invalidations[0]) != 2 {
+			t.Errorf("ожидалась одна инвалидация двух задач, получено %v", invalidations)
+		}
+	})
+
+	t.Run("удалять может только владелец", func(t *testing.T) {
+		if _, err := server.Share(alice, &pb.ShareTaskRequest{Id: ids[1], UserId: "bob", Role: "editor"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		resp, err := server.BatchDelete(bob, &pb.BatchTaskIDsRequest{Ids: ids, Mode: pb.BatchMode_BATCH_MODE_BEST_EFFORT})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got := codesOf(resp); got[0] != codes.NotFound || got[1] != codes.PermissionDenied {
+			t.Errorf("ожидались NotFound и PermissionDenied, получено %v", got)
+		}
+		resp, err = server.BatchDelete(bob, &pb.BatchTaskIDsRequest{Ids: []string{ids[1], ids[0]}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got := codesOf(resp); got[0] != codes.PermissionDenied || got[1] != codes.Aborted {
+			t.Errorf("ожидались PermissionDenied и Aborted, получено %v", got)
+		}
+
+		resp, err = server.BatchDelete(alice, &pb.BatchTaskIDsRequest{Ids: ids})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got := codesOf(resp); got[0] != codes.OK || got[1] != codes.OK {
+			t.Errorf("ожидалось удаление обеих задач, получено %v", got)
+		}
+		if n := listed(); n != 0 {
+			t.Errorf("ожидалось 0 задач, получено %d", n)
+		}
+	})
+
+	t.Run("неверный пакет", func(t *testing.T) {
+		tooMany := make([]string, 101)
+		for i := range tooMany {
+			tooMany[i] = uuid.NewString()
+		}
+		cases := map[string]*pb.BatchTaskIDsRequest{
+			"пустой":          {},
+			"неверный id":     {Ids: []string{"не uuid"}},
+			"слишком большой": {Ids: tooMany},
+		}
+		for name, req := range cases {
+			if _, err := server.BatchDelete(alice, req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%s: ожидался код InvalidArgument, получено: %v", name, err)
+			}
+		}
+	})
+}
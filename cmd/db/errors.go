@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatusError translates domain errors from the services layer into gRPC
+// status errors. Errors it does not recognise are returned unchanged and end
+// up as codes.Unknown on the client side.
+func toStatusError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, services.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, services.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, services.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrVersionMismatch):
+		return versionMismatch(err)
+	case errors.Is(err, services.ErrFailedPrecondition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrBatchAborted):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	}
+	return err
+}
+
+// versionMismatch is codes.FailedPrecondition with an ErrorInfo detail of
+// reason reqmeta.VersionMismatchReason, so that clients can tell it from a
+// workflow that does not allow a transition.
+func versionMismatch(err error) error {
+	st, detailErr := status.New(codes.FailedPrecondition, err.Error()).
+		WithDetails(&errdetails.ErrorInfo{Reason: reqmeta.VersionMismatchReason})
+	if detailErr != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return st.Err()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthCheckTimeout bounds a single dependency check.
+const healthCheckTimeout = 2 * time.Second
+
+// dependency is something the DB service cannot serve without.
+type dependency struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// watchHealth checks the dependencies every interval and reports the result
+// through the standard gRPC health service, both for the server as a whole
+// ("") and for the task service. It returns when ctx is cancelled.
+func watchHealth(ctx context.Context, hs *health.Server, interval time.Duration, deps []dependency) {
+	var failing map[string]bool
+	for {
+		now := checkDependencies(ctx, deps)
+		for _, d := range deps {
+			if now[d.name] != failing[d.name] && ctx.Err() == nil {
+				if now[d.name] {
+					log.Printf("health: %s is unavailable", d.name)
+				} else if failing != nil {
+					log.Printf("health: %s is available again", d.name)
+				}
+			}
+		}
+		failing = now
+
+		status := healthpb.HealthCheckResponse_SERVING
+		if len(failing) > 0 {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		hs.SetServingStatus("", status)
+		hs.SetServingStatus(pb.TaskService_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// checkDependencies returns the names of the dependencies that failed.
+func checkDependencies(ctx context.Context, deps []dependency) map[string]bool {
+	failing := map[string]bool{}
+	for _, d := range deps {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		if err := d.check(checkCtx); err != nil {
+			failing[d.name] = true
+		}
+		cancel()
+	}
+	return failing
+}
+
+// probeHealth asks the DB service listening on port of this host whether it
+// is serving. It is run as "db healthcheck" by the healthcheck of the
+// container, whose image has no gRPC client of its own.
+func probeHealth(port string) error {
+	conn, err := grpc.NewClient("localhost:"+port, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("db service is %s", resp.Status)
+	}
+	return nil
+}
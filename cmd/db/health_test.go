@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestWatchHealth(t *testing.T) {
+	var redisDown atomic.Bool
+	deps := []dependency{
+		{name: "postgres", check: func(ctx context.Context) error { return nil }},
+		{name: "redis", check: func(ctx context.Context) error {
+			if redisDown.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		}},
+	}
+
+	hs := health.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watchHealth(ctx, hs, time.Millisecond, deps)
+
+	waitStatus := func(service string, want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			res, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+			if err == nil && res.Status == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("статус %q: ожидался %v, получено %v (ошибка %v)", service, want, res.GetStatus(), err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("все зависимости доступны", func(t *testing.T) {
+		waitStatus("", healthpb.HealthCheckResponse_SERVING)
+		waitStatus(pb.TaskService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	})
+
+	t.Run("недоступный redis переводит сервис в NOT_SERVING", func(t *testing.T) {
+		redisDown.Store(true)
+		waitStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		waitStatus(pb.TaskService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	})
+
+	t.Run("сервис восстанавливается вместе с redis", func(t *testing.T) {
+		redisDown.Store(false)
+		waitStatus("", healthpb.HealthCheckResponse_SERVING)
+	})
+}
+
+func TestProbeHealth(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("не удалось открыть порт: %v", err)
+	}
+	hs := health.NewServer()
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(lis)
+	defer srv.Stop()
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+
+	if err := probeHealth(port); err != nil {
+		t.Errorf("здоровый сервис: неожиданная ошибка: %v", err)
+	}
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	if err := probeHealth(port); err == nil {
+		t.Error("сервис в NOT_SERVING: ожидалась ошибка")
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toPBEvents(page *models.TaskEventPage) *pb.TaskEventsResponse {
+	resp := &pb.TaskEventsResponse{NextPageToken: page.NextPageToken}
+	for _, e := range page.Events {
+		event := &pb.TaskEvent{
+			Id:         e.ID,
+			TaskId:     e.TaskID.String(),
+			Type:       e.Type,
+			Actor:      e.Actor,
+			OccurredAt: timestamppb.New(e.OccurredAt),
+		}
+		for _, c := range e.Changes {
+			event.Changes = append(event.Changes, &pb.FieldChange{Field: c.Field, Before: string(c.Before), After: string(c.After)})
+		}
+		resp.Events = append(resp.Events, event)
+	}
+	return resp
+}
+
+func (s *TaskServer) GetHistory(ctx context.Context, req *pb.GetHistoryRequest) (*pb.TaskEventsResponse, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	page, err := s.service.History(ctx, id, models.HistoryQuery{PageSize: int(req.PageSize), PageToken: req.PageToken})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPBEvents(page), nil
+}
+
+func (s *TaskServer) ListAudit(ctx context.Context, req *pb.ListAuditRequest) (*pb.TaskEventsResponse, error) {
+	q := models.HistoryQuery{
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+		Actor:     req.Actor,
+		Types:     req.Types,
+	}
+	if req.TaskId != "" {
+		id, err := services.ParseID(req.TaskId)
+		if err != nil {
+			return nil, toStatusError(err)
+		}
+		q.TaskID = &id
+	}
+	for dst, src := range map[**time.Time]*timestamppb.Timestamp{&q.Since: req.Since, &q.Until: req.Until} {
+		if src != nil {
+			t := src.AsTime()
+			*dst = &t
+		}
+	}
+
+	page, err := s.service.Audit(ctx, q)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPBEvents(page), nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	// idempotencyPendingTTL is how long a key stays reserved by a call that
+	// neither completes nor fails, such as one of a server that crashed.
+	idempotencyPendingTTL = time.Minute
+)
+
+// idempotentMethods are the calls that change tasks. Reads are safe to
+// retry as they are.
+var idempotentMethods = map[string]bool{
+	pb.TaskService_Create_FullMethodName:        true,
+	pb.TaskService_Delete_FullMethodName:        true,
+	pb.TaskService_Restore_FullMethodName:       true,
+	pb.TaskService_MarkDone_FullMethodName:      true,
+	pb.TaskService_Transition_FullMethodName:    true,
+	pb.TaskService_Reopen_FullMethodName:        true,
+	pb.TaskService_Update_FullMethodName:        true,
+	pb.TaskService_Share_FullMethodName:         true,
+	pb.TaskService_Unshare_FullMethodName:       true,
+	pb.TaskService_AddItem_FullMethodName:       true,
+	pb.TaskService_UpdateItem_FullMethodName:    true,
+	pb.TaskService_ReorderItems_FullMethodName:  true,
+	pb.TaskService_RemoveItem_FullMethodName:    true,
+	pb.TaskService_SetWorkflow_FullMethodName:   true,
+	pb.TaskService_BatchCreate_FullMethodName:   true,
+	pb.TaskService_BatchMarkDone_FullMethodName: true,
+	pb.TaskService_BatchDelete_FullMethodName:   true,
+}
+
+// idempotencyInterceptor runs the calls of idempotentMethods made with an
+// idempotency key once per key, see reqmeta.Meta.IdempotencyKey. A retry
+// with the same request gets the response to the first call, kept for ttl.
+// A key reused with another request fails with codes.InvalidArgument, and a
+// retry while the first call is still running with codes.Aborted. Failed
+// calls are forgotten, so that they can be retried. It must run after the
+// principal of the call is known.
+func idempotencyInterceptor(store repositories.IdempotencyStore, ttl time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := reqmeta.FromContext(ctx).IdempotencyKey
+		if key == "" || !idempotentMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, status.Errorf(codes.InvalidArgument, "idempotency key longer than %d bytes", maxIdempotencyKeyLength)
+		}
+		fingerprint, err := requestFingerprint(ctx, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+
+		token := uuid.NewString()
+		rec, err := store.Reserve(ctx, key, fingerprint, token, idempotencyPendingTTL)
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to reserve idempotency key: %v", err)
+		}
+		if rec != nil {
+			return replay(rec, fingerprint)
+		}
+
+		resp, err := handler(ctx, req)
+
+		// The call is over, so settle the key even if the client is gone.
+		ctx = context.WithoutCancel(ctx)
+		if err != nil {
+			if err := store.Release(ctx, key, fingerprint, token); err != nil {
+				log.Printf("failed to release idempotency key: %v", err)
+			}
+			return nil, err
+		}
+		if err := complete(ctx, store, key, fingerprint, token, resp, ttl); err != nil {
+			// The change is made; a retry will run into the reserved key,
+			// or, if the reservation expired, repeat it.
+			log.Printf("failed to store response for idempotency key: %v", err)
+		}
+		return resp, nil
+	}
+}
+
+// requestFingerprint identifies a call by its method, request and the
+// versions it expects the task at, see reqmeta.Meta.ExpectedVersions: a
+// retry with another If-Match is another request.
+func requestFingerprint(ctx context.Context, method string, req any) (string, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return "", status.Errorf(codes.Internal, "request of %s is not a protobuf message", method)
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to marshal request: %v", err)
+	}
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write(data)
+	for _, v := range reqmeta.FromContext(ctx).ExpectedVersions {
+		h.Write(binary.BigEndian.AppendUint64([]byte{0}, uint64(v)))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// replay answers a call with a key that was used before.
+func replay(rec *repositories.IdempotencyRecord, fingerprint string) (any, error) {
+	switch {
+	case rec.Fingerprint != fingerprint:
+		return nil, idempotencyKeyReused()
+	case rec.Response == nil:
+		return nil, status.Error(codes.Aborted, "a request with this idempotency key is still running")
+	}
+	var a anypb.Any
+	if err := proto.Unmarshal(rec.Response, &a); err != nil {
+		return nil, status.Errorf(codes.Internal, "malformed stored response: %v", err)
+	}
+	resp, err := a.UnmarshalNew()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "malformed stored response: %v", err)
+	}
+	return resp, nil
+}
+
+// complete stores resp as the response to the call that reserved key with
+// token.
+func complete(ctx context.Context, store repositories.IdempotencyStore, key, fingerprint, token string, resp any, ttl time.Duration) error {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return fmt.Errorf("response of type %T is not a protobuf message", resp)
+	}
+	a, err := anypb.New(msg)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return store.Complete(ctx, key, fingerprint, token, data, ttl)
+}
+
+// idempotencyKeyReused is codes.InvalidArgument with an ErrorInfo detail of
+// reason reqmeta.IdempotencyKeyReusedReason.
+func idempotencyKeyReused() error {
+	const msg = "idempotency key was already used for another request"
+	st, err := status.New(codes.InvalidArgument, msg).
+		WithDetails(&errdetails.ErrorInfo{Reason: reqmeta.IdempotencyKeyReusedReason})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, msg)
+	}
+	return st.Err()
+}
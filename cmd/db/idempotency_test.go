@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	"github.com/kalpovskii/checklist/internal/app/repositories/memory"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIdempotencyInterceptor(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+	server := &TaskServer{
+		service: service,
+	}
+	store := memory.NewIdempotencyStore()
+	interceptor := idempotencyInterceptor(store, time.Hour)
+
+	calls := 0
+	create := func(ctx context.Context, req *pb.CreateTaskRequest) (*pb.TaskResponse, error) {
+		info := &grpc.UnaryServerInfo{FullMethod: pb.TaskService_Create_FullMethodName}
+		resp, err := interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			calls++
+			return server.Create(ctx, req.(*pb.CreateTaskRequest))
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.(*pb.TaskResponse), nil
+	}
+	withKey := func(ctx context.Context, key string) context.Context {
+		return reqmeta.NewContext(ctx, reqmeta.Meta{IdempotencyKey: key})
+	}
+	listed := func() int {
+		page, err := server.List(userContext("alice", "ws-1"), &pb.ListTasksRequest{})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		return len(page.Tasks)
+	}
+
+	alice := withKey(userContext("alice", "ws-1"), "key-1")
+
+	t.Run("повтор возвращает первый ответ", func(t *testing.T) {
+		first, err := create(alice, &pb.CreateTaskRequest{Title: "Отчёт"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		again, err := create(alice, &pb.CreateTaskRequest{Title: "Отчёт"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if again.Task.Id != first.Task.Id || calls != 1 {
+			t.Errorf("повтор не должен создавать задачу: %s и %s, вызовов %d", first.Task.Id, again.Task.Id, calls)
+		}
+		if n := listed(); n != 1 {
+			t.Errorf("ожидалась одна задача, получено %d", n)
+		}
+	})
+
+	t.Run("ключ с другим запросом", func(t *testing.T) {
+		_, err := create(alice, &pb.CreateTaskRequest{Title: "Другой отчёт"})
+		st := status.Convert(err)
+		if st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+			t.Fatalf("ожидался код InvalidArgument с причиной, получено: %v", err)
+		}
+		if info, ok := st.Details()[0].(*errdetails.ErrorInfo); !ok || info.Reason != reqmeta.IdempotencyKeyReusedReason {
+			t.Errorf("ожидалась причина IDEMPOTENCY_KEY_REUSED, получено: %v", st.Details()[0])
+		}
+	})
+
+	t.Run("ключ с другой ожидаемой версией", func(t *testing.T) {
+		meta := reqmeta.FromContext(alice)
+		meta.ExpectedVersions = []int64{1}
+		_, err := create(reqmeta.NewContext(alice, meta), &pb.CreateTaskRequest{Title: "Отчёт"})
+		if status.Code(err) != codes.InvalidArgument || calls != 1 {
+			t.Errorf("ожидался код InvalidArgument без вызова, получено: %v, вызовов %d", err, calls)
+		}
+	})
+
+	t.Run("ключи разных пользователей не пересекаются", func(t *testing.T) {
+		if _, err := create(withKey(userContext("bob", "ws-1"), "key-1"), &pb.CreateTaskRequest{Title: "Другой отчёт"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if calls != 2 {
+			t.Errorf("ожидалось 2 вызова, получено %d", calls)
+		}
+	})
+
+	t.Run("неудачный запрос можно повторить", func(t *testing.T) {
+		ctx := withKey(userContext("alice", "ws-1"), "key-2")
+		if _, err := create(ctx, &pb.CreateTaskRequest{Title: " "}); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("ожидался код InvalidArgument, получено: %v", err)
+		}
+		if _, err := create(ctx, &pb.CreateTaskRequest{Title: "Исправленный"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if n := listed(); n != 2 {
+			t.Errorf("ожидалось 2 задачи, получено %d", n)
+		}
+	})
+
+	t.Run("повтор во время выполнения", func(t *testing.T) {
+		ctx := withKey(userContext("alice", "ws-1"), "key-3")
+		req := &pb.CreateTaskRequest{Title: "Долгий"}
+		fingerprint, err := requestFingerprint(ctx, pb.TaskService_Create_FullMethodName, req)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := store.Reserve(ctx, "key-3", fingerprint, "другой вызов", time.Minute); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := create(ctx, req); status.Code(err) != codes.Aborted {
+			t.Errorf("ожидался код Aborted, получено: %v", err)
+		}
+	})
+
+	t.Run("чужой резерв не меняется", func(t *testing.T) {
+		ctx := userContext("alice", "ws-1")
+		if _, err := store.Reserve(ctx, "key-4", "запрос", "второй", time.Minute); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if err := store.Complete(ctx, "key-4", "запрос", "первый", []byte("ответ"), time.Hour); !errors.Is(err, repositories.ErrIdempotencyKeyLost) {
+			t.Errorf("ожидалась ошибка ErrIdempotencyKeyLost, получено: %v", err)
+		}
+		if err := store.Release(ctx, "key-4", "запрос", "первый"); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		rec, err := store.Reserve(ctx, "key-4", "запрос", "третий", time.Minute)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if rec == nil || rec.Token != "второй" || rec.Response != nil {
+			t.Fatalf("ожидался резерв второго вызова, получено %+v", rec)
+		}
+		if err := store.Complete(ctx, "key-4", "запрос", "второй", []byte("ответ"), time.Hour); err != nil {
+			t.Errorf("неожиданная ошибка: %v", err)
+		}
+	})
+
+	t.Run("без ключа запрос выполняется каждый раз", func(t *testing.T) {
+		ctx := userContext("alice", "ws-1")
+		before := calls
+		for range 2 {
+			if _, err := create(ctx, &pb.CreateTaskRequest{Title: "Без ключа"}); err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+		}
+		if calls != before+2 {
+			t.Errorf("ожидалось 2 вызова, получено %d", calls-before)
+		}
+	})
+}
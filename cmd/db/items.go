@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toPBItems(items []models.TaskItem) []*pb.TaskItem {
+	res := make([]*pb.TaskItem, 0, len(items))
+	for _, it := range items {
+		res = append(res, &pb.TaskItem{
+			Id:        it.ID.String(),
+			Position:  int32(it.Position),
+			Text:      it.Text,
+			Done:      it.Done,
+			CreatedAt: timestamppb.New(it.CreatedAt),
+		})
+	}
+	return res
+}
+
+func (s *TaskServer) AddItem(ctx context.Context, req *pb.AddItemRequest) (*pb.TaskResponse, error) {
+	taskID, err := services.ParseID(req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	task, err := s.service.AddItem(ctx, taskID, req.Text)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+func (s *TaskServer) UpdateItem(ctx context.Context, req *pb.UpdateItemRequest) (*pb.TaskResponse, error) {
+	taskID, itemID, err := parseItemIDs(req.TaskId, req.ItemId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	patch, err := itemPatchFromMask(req.Item, req.UpdateMask)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	task, err := s.service.UpdateItem(ctx, taskID, itemID, patch)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+func (s *TaskServer) ReorderItems(ctx context.Context, req *pb.ReorderItemsRequest) (*pb.TaskResponse, error) {
+	taskID, err := services.ParseID(req.TaskId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	itemIDs := make([]uuid.UUID, len(req.ItemIds))
+	for i, raw := range req.ItemIds {
+		if itemIDs[i], err = services.ParseItemID(raw); err != nil {
+			return nil, toStatusError(err)
+		}
+	}
+	task, err := s.service.ReorderItems(ctx, taskID, itemIDs)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+func (s *TaskServer) RemoveItem(ctx context.Context, req *pb.ItemIDRequest) (*pb.TaskResponse, error) {
+	taskID, itemID, err := parseItemIDs(req.TaskId, req.ItemId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	task, err := s.service.RemoveItem(ctx, taskID, itemID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+func parseItemIDs(rawTaskID, rawItemID string) (uuid.UUID, uuid.UUID, error) {
+	taskID, err := services.ParseID(rawTaskID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	itemID, err := services.ParseItemID(rawItemID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return taskID, itemID, nil
+}
+
+// itemPatchFromMask copies the fields listed in mask from item into a patch.
+func itemPatchFromMask(item *pb.TaskItem, mask *fieldmaskpb.FieldMask) (models.TaskItemPatch, error) {
+	var patch models.TaskItemPatch
+	if len(mask.GetPaths()) == 0 {
+		return patch, fmt.Errorf("%w: update_mask must not be empty", services.ErrInvalidArgument)
+	}
+	for _, path := range mask.GetPaths() {
+		switch path {
+		case "text":
+			text := item.GetText()
+			patch.Text = &text
+		case "done":
+			done := item.GetDone()
+			patch.Done = &done
+		default:
+			return patch, fmt.Errorf("%w: unsupported update_mask path %q", services.ErrInvalidArgument, path)
+		}
+	}
+	return patch, nil
+}
@@ -2,118 +2,404 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
 	"github.com/kalpovskii/checklist/internal/app/pb"
 	"github.com/kalpovskii/checklist/internal/app/repositories"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
 	"github.com/kalpovskii/checklist/internal/app/services"
+	events "github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/kalpovskii/checklist/internal/metrics"
+	"github.com/kalpovskii/checklist/internal/tracing"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+func toPBTask(t *models.Task) *pb.Task {
+	return &pb.Task{
+		Id:              t.ID.String(),
+		Title:           t.Title,
+		Content:         t.Content,
+		Done:            t.Done,
+		CreatedAt:       timestamppb.New(t.CreatedAt),
+		OwnerId:         t.OwnerID,
+		WorkspaceId:     t.WorkspaceID,
+		AutoComplete:    t.AutoComplete,
+		Items:           toPBItems(t.Items),
+		DueAt:           toPBTime(t.DueAt),
+		RemindAt:        toPBTime(t.RemindAt),
+		Recurrence:      t.Recurrence,
+		TimeZone:        t.TimeZone,
+		Status:          string(t.Status),
+		StatusChangedAt: toPBTime(t.StatusChangedAt),
+		StatusChangedBy: t.StatusChangedBy,
+		DeletedAt:       toPBTime(t.DeletedAt),
+		Version:         t.Version,
+	}
+}
+
+// toPBTime converts an optional time, nil staying nil.
+func toPBTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromPBTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
 type TaskServer struct {
 	pb.UnimplementedTaskServiceServer
 	service *services.TaskService
 }
 
 func (s *TaskServer) Create(ctx context.Context, req *pb.CreateTaskRequest) (*pb.TaskResponse, error) {
-	task, err := s.service.Create(req.Title, req.Content)
-	if err != nil {
-		return nil, err
-	}
-	return &pb.TaskResponse{
-		Task: &pb.Task{
-			Id:        task.ID.String(),
-			Title:     task.Title,
-			Content:   task.Content,
-			Done:      task.Done,
-			CreatedAt: timestamppb.New(task.CreatedAt),
-		},
-	}, nil
-}
-
-func (s *TaskServer) List(ctx context.Context, req *emptypb.Empty) (*pb.TaskListResponse, error) {
-    tasks, err := s.service.List()
-    if err != nil {
-        return nil, err
-    }
-
-    resp := &pb.TaskListResponse{}
-    for _, t := range tasks {
-        resp.Tasks = append(resp.Tasks, &pb.Task{
-            Id:        t.ID.String(),
-            Title:     t.Title,
-            Content:   t.Content,
-            Done:      t.Done,
-            CreatedAt: timestamppb.New(t.CreatedAt),
-        })
-    }
-    return resp, nil
+	task, err := s.service.Create(ctx, fromPBDraft(req))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+// fromPBDraft returns the draft of a task to create.
+func fromPBDraft(req *pb.CreateTaskRequest) models.Task {
+	return models.Task{
+		Title:        req.Title,
+		Content:      req.Content,
+		AutoComplete: req.AutoComplete,
+		DueAt:        fromPBTime(req.DueAt),
+		RemindAt:     fromPBTime(req.RemindAt),
+		Recurrence:   req.Recurrence,
+		TimeZone:     req.TimeZone,
+	}
+}
+
+func (s *TaskServer) List(ctx context.Context, req *pb.ListTasksRequest) (*pb.TaskListResponse, error) {
+	q := models.TaskListQuery{
+		PageSize:   int(req.PageSize),
+		PageToken:  req.PageToken,
+		Done:       req.Done,
+		Descending: req.Sort == pb.SortOrder_SORT_ORDER_CREATED_DESC,
+		Overdue:    req.Overdue,
+	}
+	if req.CreatedAfter != nil {
+		t := req.CreatedAfter.AsTime()
+		q.CreatedAfter = &t
+	}
+	if req.CreatedBefore != nil {
+		t := req.CreatedBefore.AsTime()
+		q.CreatedBefore = &t
+	}
+	if req.DueWithin != nil {
+		q.DueWithin = req.DueWithin.AsDuration()
+	}
+	for _, status := range req.Statuses {
+		q.Statuses = append(q.Statuses, models.Status(status))
+	}
+
+	page, err := s.service.List(ctx, q)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.TaskListResponse{NextPageToken: page.NextPageToken}
+	for _, t := range page.Tasks {
+		resp.Tasks = append(resp.Tasks, toPBTask(&t))
+	}
+	return resp, nil
+}
+
+func (s *TaskServer) Get(ctx context.Context, req *pb.TaskIDRequest) (*pb.TaskResponse, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	task, err := s.service.Get(ctx, id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+func (s *TaskServer) Search(ctx context.Context, req *pb.SearchTasksRequest) (*pb.SearchTasksResponse, error) {
+	results, err := s.service.Search(ctx, req.Query, int(req.PageSize))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.SearchTasksResponse{}
+	for _, r := range results {
+		resp.Results = append(resp.Results, &pb.SearchResult{
+			Task:           toPBTask(&r.Task),
+			Rank:           r.Rank,
+			TitleSnippet:   r.TitleSnippet,
+			ContentSnippet: r.ContentSnippet,
+		})
+	}
+	return resp, nil
 }
 
 func (s *TaskServer) Delete(ctx context.Context, req *pb.TaskIDRequest) (*pb.StatusResponse, error) {
-	id, err := uuid.Parse(req.Id)
-  if err != nil {
-      return nil, err
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
 	}
-	
-	err = s.service.Delete(id)
+
+	err = s.service.Delete(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	return &pb.StatusResponse{Status: "deleted"}, nil
 }
 
 func (s *TaskServer) MarkDone(ctx context.Context, req *pb.TaskIDRequest) (*pb.StatusResponse, error) {
-	id, err := uuid.Parse(req.Id)
+	id, err := services.ParseID(req.Id)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
-	err = s.service.MarkDone(id)
+	err = s.service.MarkDone(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 	return &pb.StatusResponse{Status: "done"}, nil
 }
 
+func (s *TaskServer) Update(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.TaskResponse, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	patch, err := taskPatchFromMask(req.Task, req.UpdateMask)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	task, err := s.service.Update(ctx, id, patch)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+func (s *TaskServer) Share(ctx context.Context, req *pb.ShareTaskRequest) (*pb.Collaborator, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	c, err := s.service.Share(ctx, id, req.UserId, models.Role(req.Role))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPBCollaborator(c), nil
+}
+
+func (s *TaskServer) Unshare(ctx context.Context, req *pb.UnshareTaskRequest) (*pb.StatusResponse, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	if err := s.service.Unshare(ctx, id, req.UserId); err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.StatusResponse{Status: "unshared"}, nil
+}
+
+func (s *TaskServer) ListCollaborators(ctx context.Context, req *pb.TaskIDRequest) (*pb.CollaboratorsResponse, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	collaborators, err := s.service.ListCollaborators(ctx, id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.CollaboratorsResponse{}
+	for _, c := range collaborators {
+		resp.Collaborators = append(resp.Collaborators, toPBCollaborator(&c))
+	}
+	return resp, nil
+}
+
+func toPBCollaborator(c *models.Collaborator) *pb.Collaborator {
+	return &pb.Collaborator{
+		UserId:    c.UserID,
+		Role:      string(c.Role),
+		CreatedAt: timestamppb.New(c.CreatedAt),
+	}
+}
+
+// taskPatchFromMask copies the fields listed in mask from task into a patch.
+func taskPatchFromMask(task *pb.Task, mask *fieldmaskpb.FieldMask) (models.TaskPatch, error) {
+	var patch models.TaskPatch
+	if len(mask.GetPaths()) == 0 {
+		return patch, fmt.Errorf("%w: update_mask must not be empty", services.ErrInvalidArgument)
+	}
+	for _, path := range mask.GetPaths() {
+		switch path {
+		case "title":
+			title := task.GetTitle()
+			patch.Title = &title
+		case "content":
+			content := task.GetContent()
+			patch.Content = &content
+		case "auto_complete":
+			autoComplete := task.GetAutoComplete()
+			patch.AutoComplete = &autoComplete
+		case "due_at":
+			// An unset time clears it.
+			patch.DueAt = &time.Time{}
+			if t := fromPBTime(task.GetDueAt()); t != nil {
+				patch.DueAt = t
+			}
+		case "remind_at":
+			patch.RemindAt = &time.Time{}
+			if t := fromPBTime(task.GetRemindAt()); t != nil {
+				patch.RemindAt = t
+			}
+		case "recurrence":
+			recurrence := task.GetRecurrence()
+			patch.Recurrence = &recurrence
+		case "time_zone":
+			timeZone := task.GetTimeZone()
+			patch.TimeZone = &timeZone
+		default:
+			return patch, fmt.Errorf("%w: unsupported update_mask path %q", services.ErrInvalidArgument, path)
+		}
+	}
+	return patch, nil
+}
+
 func main() {
 	viper.SetEnvPrefix("CHECKLIST")
 	viper.AutomaticEnv()
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", time.Second)
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("SCHEDULER_POLL_INTERVAL", 10*time.Second)
+	viper.SetDefault("SCHEDULER_BATCH_SIZE", 100)
+	viper.SetDefault("TRASH_RETENTION", 30*24*time.Hour)
+	viper.SetDefault("OUTBOX_RETENTION", 7*24*time.Hour)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 15*time.Second)
+	viper.SetDefault("HEALTH_CHECK_INTERVAL", 5*time.Second)
+	viper.SetDefault("DB_METRICS_PORT", "9090")
+	viper.SetDefault("IDEMPOTENCY_TTL", 24*time.Hour)
+
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		if err := probeHealth(viper.GetString("DB_GRPC_PORT")); err != nil {
+			log.Fatalf("healthcheck: %v", err)
+		}
+		return
+	}
 
 	redisAddr := viper.GetString("REDIS_ADDR")
 	if redisAddr == "" {
 		log.Fatal("REDIS_ADDR is not configured")
 	}
 	port := viper.GetString("DB_GRPC_PORT")
-  dsn  := viper.GetString("DB_POSTGRES_DSN")
+	dsn := viper.GetString("DB_POSTGRES_DSN")
 	if dsn == "" || port == "" {
 		log.Fatal("DB_POSTGRES_DSN or DB_GRPC_PORT is not configured")
 	}
+	// A full batch makes the scheduler poll again at once, and an empty one
+	// would always be full.
+	if viper.GetInt("SCHEDULER_BATCH_SIZE") < 1 {
+		log.Fatal("SCHEDULER_BATCH_SIZE must be at least 1")
+	}
 
-	repo, err := repositories.NewPostgresTaskRepo(dsn)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  "checklist-db",
+		Exporter:     viper.GetString("OTEL_EXPORTER"),
+		OTLPEndpoint: viper.GetString("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		File:         viper.GetString("OTEL_EXPORTER_FILE"),
+	})
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), time.Minute)
+	db, err := repositories.OpenPostgres(migrateCtx, dsn)
+	cancelMigrate()
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer db.Close()
+	metrics.RegisterDBStats(db, "postgres")
+	repo := repositories.NewPostgresTaskRepo(db)
 
 	rdb := redis.NewClient(&redis.Options{
-	  Addr: redisAddr,
-  })
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-  defer cancel()
-
-  if err := rdb.Ping(ctx).Err(); err != nil {
-	 log.Fatal("redis connection failed:", err)
-  }
+		Addr: redisAddr,
+	})
+	defer rdb.Close()
+	pingCtx, cancelPing := context.WithTimeout(context.Background(), 2*time.Second)
+	err = rdb.Ping(pingCtx).Err()
+	cancelPing()
+	if err != nil {
+		log.Fatal("redis connection failed:", err)
+	}
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		log.Fatalf("failed to trace redis: %v", err)
+	}
 	cache := repositories.NewRedisTaskRepository(rdb)
+	idempotency := repositories.NewRedisIdempotencyStore(rdb)
 
+	kafkaBroker := viper.GetString("KAFKA_BROKER")
+	kafkaTopic := viper.GetString("KAFKA_TOPIC")
+	if kafkaBroker == "" || kafkaTopic == "" {
+		log.Fatal("KAFKA_BROKER or KAFKA_TOPIC is not configured")
+	}
+	producer := events.NewProducer(kafkaBroker, kafkaTopic)
+	defer producer.Close()
 
-	service := services.NewTaskService(repo, cache)
+	relay := &outboxRelay{
+		store:     repositories.NewPostgresOutbox(db),
+		publisher: producer,
+		interval:  viper.GetDuration("OUTBOX_POLL_INTERVAL"),
+		batchSize: viper.GetInt("OUTBOX_BATCH_SIZE"),
+	}
+	scheduler := &taskScheduler{
+		store:           repositories.NewPostgresScheduler(db),
+		interval:        viper.GetDuration("SCHEDULER_POLL_INTERVAL"),
+		batchSize:       viper.GetInt("SCHEDULER_BATCH_SIZE"),
+		retention:       viper.GetDuration("TRASH_RETENTION"),
+		outboxRetention: viper.GetDuration("OUTBOX_RETENTION"),
+	}
+
+	jwtVerifier, err := auth.LoadJWTVerifier(
+		viper.GetString("AUTH_JWT_HS256_SECRET"),
+		viper.GetString("AUTH_JWT_RS256_PUBLIC_KEY_FILE"),
+		viper.GetString("AUTH_JWT_ISSUER"),
+		viper.GetString("AUTH_JWT_AUDIENCE"),
+	)
+	if err != nil {
+		log.Fatalf("failed to configure jwt auth: %v", err)
+	}
+	authService := services.NewAuthService(repositories.NewPostgresAPIKeyRepo(db), jwtVerifier)
+
+	service := services.NewTaskService(repo, repositories.NewPostgresPermissionRepo(db), repositories.NewPostgresWorkflowRepo(db),
+		repositories.NewPostgresHistoryRepo(db), cache)
 	server := &TaskServer{service: service}
 
 	lis, err := net.Listen("tcp", ":"+port)
@@ -121,11 +407,94 @@ func main() {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.ChainUnaryInterceptor(
+			metrics.UnaryServerInterceptor(),
+			reqmeta.UnaryServerInterceptor(),
+			auth.UnaryServerInterceptor(authService, auth.HealthCheckMethod),
+			idempotencyInterceptor(idempotency, viper.GetDuration("IDEMPOTENCY_TTL")),
+		),
+	)
 	pb.RegisterTaskServiceServer(grpcServer, server)
+	pb.RegisterAuthServiceServer(grpcServer, &AuthServer{service: authService})
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	// ctx is cancelled on SIGINT or SIGTERM, which starts the shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	relayDone := make(chan struct{})
+	go func() {
+		relay.run(ctx)
+		close(relayDone)
+	}()
+	schedulerDone := make(chan struct{})
+	go func() {
+		scheduler.run(ctx)
+		close(schedulerDone)
+	}()
+
+	go watchHealth(ctx, healthServer, viper.GetDuration("HEALTH_CHECK_INTERVAL"), []dependency{
+		{name: "postgres", check: db.PingContext},
+		{name: "redis", check: func(ctx context.Context) error { return rdb.Ping(ctx).Err() }},
+	})
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", metrics.Handler())
+	metricsSrv := &http.Server{Addr: ":" + viper.GetString("DB_METRICS_PORT"), Handler: metricsMux}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- grpcServer.Serve(lis)
+	}()
+	go func() {
+		if err := metricsSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
 	log.Printf("gRPC server listening on %s", port)
-	if err := grpcServer.Serve(lis); err != nil {
+
+	select {
+	case err := <-serveErr:
 		log.Fatalf("failed to serve: %v", err)
+	case <-ctx.Done():
+	}
+
+	log.Print("shutting down")
+	// Health checks report NOT_SERVING from now on, so that balancers stop
+	// sending new calls while the running ones drain.
+	healthServer.Shutdown()
+	timeout := viper.GetDuration("SHUTDOWN_TIMEOUT")
+	shutdown(grpcServer, timeout)
+	// Messages the relay was publishing stay pending and go out after restart,
+	// and so do the notifications of the scheduler.
+	<-relayDone
+	<-schedulerDone
+	metricsSrv.Close()
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), timeout)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Printf("failed to flush traces: %v", err)
+	}
+	log.Print("stopped")
+}
+
+// shutdown stops accepting new calls and waits for the running ones to
+// finish. Calls still running after timeout are cancelled.
+func shutdown(grpcServer *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		log.Printf("calls still running after %s, cancelling them", timeout)
+		grpcServer.Stop()
 	}
-}
\ No newline at end of file
+}
@@ -3,58 +3,176 @@ package main
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
 	"github.com/kalpovskii/checklist/internal/app/models"
 	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	"github.com/kalpovskii/checklist/internal/app/repositories/memory"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
 	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type mockTaskRepository struct {
-	createFn   func(task *models.Task) error
-	listFn     func() ([]models.Task, error)
-	deleteFn   func(id uuid.UUID) error
-	markDoneFn func(id uuid.UUID) error
+	createFn     func(ctx context.Context, task *models.Task) error
+	listFn       func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error)
+	deleteFn     func(ctx context.Context, id uuid.UUID) error
+	transitionFn func(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error)
+	updateFn     func(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error)
+	getByIDFn    func(ctx context.Context, id uuid.UUID) (*models.Task, error)
+	searchFn     func(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
 }
 
-func (m *mockTaskRepository) Create(task *models.Task) error {
+func (m *mockTaskRepository) Create(ctx context.Context, task *models.Task) error {
 	if m.createFn != nil {
-		return m.createFn(task)
+		return m.createFn(ctx, task)
 	}
 	return nil
 }
 
-func (m *mockTaskRepository) List() ([]models.Task, error) {
+func (m *mockTaskRepository) List(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
 	if m.listFn != nil {
-		return m.listFn()
+		return m.listFn(ctx, q)
 	}
-	return []models.Task{}, nil
+	return &models.TaskPage{Tasks: []models.Task{}}, nil
 }
 
-func (m *mockTaskRepository) Delete(id uuid.UUID) error {
+func (m *mockTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	if m.deleteFn != nil {
-		return m.deleteFn(id)
+		return m.deleteFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockTaskRepository) MarkDone(id uuid.UUID) error {
-	if m.markDoneFn != nil {
-		return m.markDoneFn(id)
+func (m *mockTaskRepository) Restore(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	return &models.Task{ID: id}, nil
+}
+
+func (m *mockTaskRepository) Transition(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+	if m.transitionFn != nil {
+		return m.transitionFn(ctx, id, to, wf, next)
+	}
+	return &models.Task{ID: id, Status: to}, nil
+}
+
+func (m *mockTaskRepository) Reopen(ctx context.Context, id uuid.UUID, wf *models.Workflow) (*models.Task, error) {
+	return &models.Task{ID: id, Status: models.StatusTodo}, nil
+}
+
+func (m *mockTaskRepository) Update(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, id, patch)
+	}
+	return &models.Task{ID: id}, nil
+}
+
+func (m *mockTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	if m.getByIDFn != nil {
+		return m.getByIDFn(ctx, id)
+	}
+	return &models.Task{ID: id}, nil
+}
+
+func (m *mockTaskRepository) Search(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	if m.searchFn != nil {
+		return m.searchFn(ctx, query, limit)
+	}
+	return []models.SearchResult{}, nil
+}
+
+// Пункты чек-листа проверяются на in-memory репозитории, здесь они
+// ничего не делают.
+func (m *mockTaskRepository) AddItem(ctx context.Context, taskID uuid.UUID, item *models.TaskItem) (*models.Task, error) {
+	return &models.Task{ID: taskID}, nil
+}
+
+func (m *mockTaskRepository) UpdateItem(ctx context.Context, taskID, itemID uuid.UUID, patch models.TaskItemPatch, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+	return &models.Task{ID: taskID}, nil
+}
+
+func (m *mockTaskRepository) ReorderItems(ctx context.Context, taskID uuid.UUID, itemIDs []uuid.UUID) (*models.Task, error) {
+	return &models.Task{ID: taskID}, nil
+}
+
+func (m *mockTaskRepository) RemoveItem(ctx context.Context, taskID, itemID uuid.UUID, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+	return &models.Task{ID: taskID}, nil
+}
+
+// Пакетные операции тоже проверяются на in-memory репозитории.
+func (m *mockTaskRepository) BatchCreate(ctx context.Context, tasks []*models.Task, mode models.BatchMode) ([]models.BatchResult, error) {
+	return make([]models.BatchResult, len(tasks)), nil
+}
+
+func (m *mockTaskRepository) BatchTransition(ctx context.Context, ids []uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence, mode models.BatchMode) ([]models.BatchResult, error) {
+	return make([]models.BatchResult, len(ids)), nil
+}
+
+func (m *mockTaskRepository) BatchDelete(ctx context.Context, ids []uuid.UUID, mode models.BatchMode) ([]models.BatchResult, error) {
+	return make([]models.BatchResult, len(ids)), nil
+}
+
+// mockPermissionRepository по умолчанию считает вызывающего владельцем
+// любой задачи.
+type mockPermissionRepository struct {
+	roleFn func(ctx context.Context, taskID uuid.UUID) (models.Role, error)
+}
+
+func (m *mockPermissionRepository) Role(ctx context.Context, taskID uuid.UUID) (models.Role, error) {
+	if m.roleFn != nil {
+		return m.roleFn(ctx, taskID)
 	}
+	return models.RoleOwner, nil
+}
+
+func (m *mockPermissionRepository) Share(ctx context.Context, c *models.Collaborator) error {
+	return nil
+}
+
+func (m *mockPermissionRepository) Unshare(ctx context.Context, taskID uuid.UUID, userID string) error {
 	return nil
 }
 
+func (m *mockPermissionRepository) ListCollaborators(ctx context.Context, taskID uuid.UUID) ([]models.Collaborator, error) {
+	return []models.Collaborator{}, nil
+}
+
+// mockWorkflowRepository отдаёт процесс по умолчанию.
+type mockWorkflowRepository struct{}
+
+func (m *mockWorkflowRepository) Workflow(ctx context.Context) (*models.Workflow, error) {
+	return models.DefaultWorkflow(""), nil
+}
+
+func (m *mockWorkflowRepository) SetWorkflow(ctx context.Context, transitions []models.Transition) (*models.Workflow, error) {
+	return &models.Workflow{Transitions: transitions}, nil
+}
+
+type mockHistoryRepository struct{}
+
+func (m *mockHistoryRepository) Events(ctx context.Context, q models.HistoryQuery) (*models.TaskEventPage, error) {
+	return &models.TaskEventPage{Events: []models.TaskEvent{}}, nil
+}
+
 type mockTaskCache struct {
 	getTaskFn     func(ctx context.Context, id string) (*models.Task, error)
 	setTaskFn     func(ctx context.Context, task *models.Task, ttl time.Duration) error
-	getTaskListFn func(ctx context.Context) ([]models.Task, error)
-	setTaskListFn func(ctx context.Context, tasks []models.Task, ttl time.Duration) error
+	getTaskListFn func(ctx context.Context, key string) (*models.TaskPage, error)
+	setTaskListFn func(ctx context.Context, key string, page *models.TaskPage, ttl time.Duration) error
 	deleteTaskFn  func(ctx context.Context, id string) error
 	deleteListFn  func(ctx context.Context) error
+	deleteTasksFn func(ctx context.Context, ids ...string) error
 }
 
 func (m *mockTaskCache) GetTask(ctx context.Context, id string) (*models.Task, error) {
@@ -71,16 +189,16 @@ func (m *mockTaskCache) SetTask(ctx context.Context, task *models.Task, ttl time
 	return nil
 }
 
-func (m *mockTaskCache) GetTaskList(ctx context.Context) ([]models.Task, error) {
+func (m *mockTaskCache) GetTaskList(ctx context.Context, key string) (*models.TaskPage, error) {
 	if m.getTaskListFn != nil {
-		return m.getTaskListFn(ctx)
+		return m.getTaskListFn(ctx, key)
 	}
 	return nil, nil
 }
 
-func (m *mockTaskCache) SetTaskList(ctx context.Context, tasks []models.Task, ttl time.Duration) error {
+func (m *mockTaskCache) SetTaskList(ctx context.Context, key string, page *models.TaskPage, ttl time.Duration) error {
 	if m.setTaskListFn != nil {
-		return m.setTaskListFn(ctx, tasks, ttl)
+		return m.setTaskListFn(ctx, key, page, ttl)
 	}
 	return nil
 }
@@ -98,13 +216,20 @@ func (m *mockTaskCache) DeleteTaskList(ctx context.Context) error {
 	}
 	return nil
 }
+
+func (m *mockTaskCache) DeleteTasks(ctx context.Context, ids ...string) error {
+	if m.deleteTasksFn != nil {
+		return m.deleteTasksFn(ctx, ids...)
+	}
+	return nil
+}
 func TestTaskServer_Create(t *testing.T) {
 	t.Run("успешное создание задачи", func(t *testing.T) {
 		taskID := uuid.New()
 		createdAt := time.Now()
 
 		mockRepo := &mockTaskRepository{
-			createFn: func(task *models.Task) error {
+			createFn: func(ctx context.Context, task *models.Task) error {
 				task.ID = taskID
 				task.CreatedAt = createdAt
 				return nil
@@ -113,7 +238,7 @@ func TestTaskServer_Create(t *testing.T) {
 
 		mockCache := &mockTaskCache{}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 
 		server := &TaskServer{
 			service: service,
@@ -159,14 +284,14 @@ func TestTaskServer_Create(t *testing.T) {
 		expectedError := errors.New("ошибка базы данных")
 
 		mockRepo := &mockTaskRepository{
-			createFn: func(task *models.Task) error {
+			createFn: func(ctx context.Context, task *models.Task) error {
 				return expectedError
 			},
 		}
 
 		mockCache := &mockTaskCache{}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
@@ -216,23 +341,23 @@ func TestTaskServer_List(t *testing.T) {
 		}
 
 		mockRepo := &mockTaskRepository{
-			listFn: func() ([]models.Task, error) {
-				return expectedTasks, nil
+			listFn: func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+				return &models.TaskPage{Tasks: expectedTasks}, nil
 			},
 		}
 
 		mockCache := &mockTaskCache{
-			getTaskListFn: func(ctx context.Context) ([]models.Task, error) {
+			getTaskListFn: func(ctx context.Context, key string) (*models.TaskPage, error) {
 				return nil, nil
 			},
 		}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
 
-		resp, err := server.List(context.Background(), &emptypb.Empty{})
+		resp, err := server.List(context.Background(), &pb.ListTasksRequest{})
 
 		if err != nil {
 			t.Fatalf("неожиданная ошибка: %v", err)
@@ -269,23 +394,23 @@ func TestTaskServer_List(t *testing.T) {
 
 	t.Run("пустой список задач", func(t *testing.T) {
 		mockRepo := &mockTaskRepository{
-			listFn: func() ([]models.Task, error) {
-				return []models.Task{}, nil
+			listFn: func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+				return &models.TaskPage{Tasks: []models.Task{}}, nil
 			},
 		}
 
 		mockCache := &mockTaskCache{
-			getTaskListFn: func(ctx context.Context) ([]models.Task, error) {
+			getTaskListFn: func(ctx context.Context, key string) (*models.TaskPage, error) {
 				return nil, nil
 			},
 		}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
 
-		resp, err := server.List(context.Background(), &emptypb.Empty{})
+		resp, err := server.List(context.Background(), &pb.ListTasksRequest{})
 
 		if err != nil {
 			t.Fatalf("неожиданная ошибка: %v", err)
@@ -304,23 +429,23 @@ func TestTaskServer_List(t *testing.T) {
 		expectedError := errors.New("ошибка базы данных")
 
 		mockRepo := &mockTaskRepository{
-			listFn: func() ([]models.Task, error) {
+			listFn: func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
 				return nil, expectedError
 			},
 		}
 
 		mockCache := &mockTaskCache{
-			getTaskListFn: func(ctx context.Context) ([]models.Task, error) {
+			getTaskListFn: func(ctx context.Context, key string) (*models.TaskPage, error) {
 				return nil, nil
 			},
 		}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
 
-		resp, err := server.List(context.Background(), &emptypb.Empty{})
+		resp, err := server.List(context.Background(), &pb.ListTasksRequest{})
 
 		if err == nil {
 			t.Fatal("ожидалась ошибка, но её не было")
@@ -337,7 +462,7 @@ func TestTaskServer_Delete(t *testing.T) {
 		taskID := uuid.New()
 
 		mockRepo := &mockTaskRepository{
-			deleteFn: func(id uuid.UUID) error {
+			deleteFn: func(ctx context.Context, id uuid.UUID) error {
 				if id != taskID {
 					t.Errorf("неожиданный ID: ожидалось %s, получено %s", taskID, id)
 				}
@@ -347,7 +472,7 @@ func TestTaskServer_Delete(t *testing.T) {
 
 		mockCache := &mockTaskCache{}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
@@ -375,7 +500,7 @@ func TestTaskServer_Delete(t *testing.T) {
 		mockRepo := &mockTaskRepository{}
 		mockCache := &mockTaskCache{}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
@@ -405,14 +530,14 @@ func TestTaskServer_Delete(t *testing.T) {
 		expectedError := errors.New("задача не найдена")
 
 		mockRepo := &mockTaskRepository{
-			deleteFn: func(id uuid.UUID) error {
+			deleteFn: func(ctx context.Context, id uuid.UUID) error {
 				return expectedError
 			},
 		}
 
 		mockCache := &mockTaskCache{}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
@@ -438,17 +563,20 @@ func TestTaskServer_MarkDone(t *testing.T) {
 		taskID := uuid.New()
 
 		mockRepo := &mockTaskRepository{
-			markDoneFn: func(id uuid.UUID) error {
+			transitionFn: func(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
 				if id != taskID {
 					t.Errorf("неожиданный ID: ожидалось %s, получено %s", taskID, id)
 				}
-				return nil
+				if to != models.StatusDone {
+					t.Errorf("неожиданный статус: ожидалось done, получено %s", to)
+				}
+				return &models.Task{ID: id, Status: to, Done: true}, nil
 			},
 		}
 
 		mockCache := &mockTaskCache{}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
@@ -476,7 +604,7 @@ func TestTaskServer_MarkDone(t *testing.T) {
 		mockRepo := &mockTaskRepository{}
 		mockCache := &mockTaskCache{}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
@@ -501,14 +629,14 @@ func TestTaskServer_MarkDone(t *testing.T) {
 		expectedError := errors.New("задача не найдена")
 
 		mockRepo := &mockTaskRepository{
-			markDoneFn: func(id uuid.UUID) error {
-				return expectedError
+			transitionFn: func(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+				return nil, expectedError
 			},
 		}
 
 		mockCache := &mockTaskCache{}
 
-		service := services.NewTaskService(mockRepo, mockCache)
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
 		server := &TaskServer{
 			service: service,
 		}
@@ -528,3 +656,1687 @@ func TestTaskServer_MarkDone(t *testing.T) {
 		}
 	})
 }
+
+
+func TestTaskServer_Update(t *testing.T) {
+	t.Run("обновление только указанных полей", func(t *testing.T) {
+		taskID := uuid.New()
+		deletedKeys := 0
+
+		mockRepo := &mockTaskRepository{
+			updateFn: func(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error) {
+				if id != taskID {
+					t.Errorf("неожиданный ID: ожидалось %s, получено %s", taskID, id)
+				}
+				if patch.Title == nil || *patch.Title != "Новый заголовок" {
+					t.Errorf("неожиданный Title в патче: %v", patch.Title)
+				}
+				if patch.Content != nil {
+					t.Errorf("Content не должен обновляться, получено %q", *patch.Content)
+				}
+				return &models.Task{ID: id, Title: *patch.Title, Content: "Старое описание"}, nil
+			},
+		}
+
+		mockCache := &mockTaskCache{
+			deleteTaskFn: func(ctx context.Context, id string) error {
+				deletedKeys++
+				return nil
+			},
+			deleteListFn: func(ctx context.Context) error {
+				deletedKeys++
+				return nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
+		server := &TaskServer{
+			service: service,
+		}
+
+		req := &pb.UpdateTaskRequest{
+			Id:         taskID.String(),
+			Task:       &pb.Task{Title: "Новый заголовок", Content: "игнорируется"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+		}
+
+		resp, err := server.Update(context.Background(), req)
+
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if resp.Task.Title != "Новый заголовок" || resp.Task.Content != "Старое описание" {
+			t.Errorf("неожиданная задача: %+v", resp.Task)
+		}
+
+		if deletedKeys != 2 {
+			t.Errorf("ожидалась инвалидация задачи и списка в кеше, удалено ключей: %d", deletedKeys)
+		}
+	})
+
+	t.Run("сроки задаются и снимаются через маску", func(t *testing.T) {
+		due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+		mockRepo := &mockTaskRepository{
+			updateFn: func(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error) {
+				if patch.DueAt == nil || !patch.DueAt.Equal(due) {
+					t.Errorf("неожиданный DueAt в патче: %v", patch.DueAt)
+				}
+				if patch.RemindAt == nil || !patch.RemindAt.IsZero() {
+					t.Errorf("RemindAt без значения должен сниматься, получено %v", patch.RemindAt)
+				}
+				return &models.Task{ID: id, DueAt: patch.DueAt}, nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		req := &pb.UpdateTaskRequest{
+			Id:         uuid.New().String(),
+			Task:       &pb.Task{DueAt: timestamppb.New(due)},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"due_at", "remind_at"}},
+		}
+
+		resp, err := server.Update(context.Background(), req)
+
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if !resp.Task.DueAt.AsTime().Equal(due) || resp.Task.RemindAt != nil {
+			t.Errorf("неожиданная задача: %+v", resp.Task)
+		}
+	})
+
+	t.Run("неизвестное поле в маске", func(t *testing.T) {
+		mockRepo := &mockTaskRepository{
+			updateFn: func(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error) {
+				t.Fatal("репозиторий не должен вызываться")
+				return nil, nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		req := &pb.UpdateTaskRequest{
+			Id:         uuid.New().String(),
+			Task:       &pb.Task{Done: true},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"done"}},
+		}
+
+		resp, err := server.Update(context.Background(), req)
+
+		if err == nil {
+			t.Fatal("ожидалась ошибка, но её не было")
+		}
+
+		if resp != nil {
+			t.Error("ответ должен быть nil при ошибке")
+		}
+	})
+
+	t.Run("пустая маска", func(t *testing.T) {
+		service := services.NewTaskService(&mockTaskRepository{}, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		req := &pb.UpdateTaskRequest{
+			Id:   uuid.New().String(),
+			Task: &pb.Task{Title: "Заголовок"},
+		}
+
+		if _, err := server.Update(context.Background(), req); err == nil {
+			t.Fatal("ожидалась ошибка, но её не было")
+		}
+	})
+}
+
+// userContext возвращает контекст запроса пользователя subject из
+// workspace, как после проверки учётных данных.
+func userContext(subject, workspace string) context.Context {
+	return auth.NewContext(context.Background(), &auth.Principal{Subject: subject, WorkspaceID: workspace})
+}
+
+func TestTaskServer_Get(t *testing.T) {
+	t.Run("задача берётся из кеша", func(t *testing.T) {
+		taskID := uuid.New()
+
+		mockRepo := &mockTaskRepository{
+			getByIDFn: func(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+				t.Fatal("при попадании в кеш репозиторий не должен вызываться")
+				return nil, nil
+			},
+		}
+
+		mockCache := &mockTaskCache{
+			getTaskFn: func(ctx context.Context, id string) (*models.Task, error) {
+				if id != taskID.String() {
+					t.Errorf("неожиданный ключ кеша: %s", id)
+				}
+				return &models.Task{ID: taskID, Title: "Из кеша", OwnerID: "alice", WorkspaceID: "ws-1"}, nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
+		server := &TaskServer{
+			service: service,
+		}
+
+		resp, err := server.Get(userContext("alice", "ws-1"), &pb.TaskIDRequest{Id: taskID.String()})
+
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if resp.Task.Title != "Из кеша" {
+			t.Errorf("неожиданный Title: %s", resp.Task.Title)
+		}
+	})
+
+	t.Run("чужая задача из кеша не отдаётся", func(t *testing.T) {
+		taskID := uuid.New()
+
+		mockRepo := &mockTaskRepository{
+			getByIDFn: func(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+				return nil, repositories.ErrNotFound
+			},
+		}
+
+		mockCache := &mockTaskCache{
+			getTaskFn: func(ctx context.Context, id string) (*models.Task, error) {
+				return &models.Task{ID: taskID, Title: "Чужая", OwnerID: "alice", WorkspaceID: "ws-1"}, nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
+		server := &TaskServer{
+			service: service,
+		}
+
+		resp, err := server.Get(userContext("bob", "ws-1"), &pb.TaskIDRequest{Id: taskID.String()})
+
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("ожидался код NotFound, получено: %v", err)
+		}
+
+		if resp != nil {
+			t.Error("ответ должен быть nil при ошибке")
+		}
+	})
+
+	t.Run("промах кеша заполняет кеш", func(t *testing.T) {
+		taskID := uuid.New()
+		var cached *models.Task
+
+		mockRepo := &mockTaskRepository{
+			getByIDFn: func(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+				return &models.Task{ID: id, Title: "Из базы"}, nil
+			},
+		}
+
+		mockCache := &mockTaskCache{
+			setTaskFn: func(ctx context.Context, task *models.Task, ttl time.Duration) error {
+				cached = task
+				return nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
+		server := &TaskServer{
+			service: service,
+		}
+
+		resp, err := server.Get(context.Background(), &pb.TaskIDRequest{Id: taskID.String()})
+
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if resp.Task.Title != "Из базы" {
+			t.Errorf("неожиданный Title: %s", resp.Task.Title)
+		}
+
+		if cached == nil || cached.ID != taskID {
+			t.Error("задача должна быть записана в кеш")
+		}
+	})
+
+	t.Run("задача не найдена", func(t *testing.T) {
+		mockRepo := &mockTaskRepository{
+			getByIDFn: func(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+				return nil, repositories.ErrNotFound
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		resp, err := server.Get(context.Background(), &pb.TaskIDRequest{Id: uuid.New().String()})
+
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("ожидался код NotFound, получено: %v", err)
+		}
+
+		if resp != nil {
+			t.Error("ответ должен быть nil при ошибке")
+		}
+	})
+}
+
+func TestTaskServer_StatusCodes(t *testing.T) {
+	t.Run("невалидный UUID даёт InvalidArgument", func(t *testing.T) {
+		service := services.NewTaskService(&mockTaskRepository{}, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		_, err := server.MarkDone(context.Background(), &pb.TaskIDRequest{Id: "невалидный-uuid"})
+
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("ожидался код InvalidArgument, получено: %v", err)
+		}
+	})
+
+	t.Run("удаление несуществующей задачи даёт NotFound", func(t *testing.T) {
+		mockRepo := &mockTaskRepository{
+			deleteFn: func(ctx context.Context, id uuid.UUID) error {
+				return repositories.ErrNotFound
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		_, err := server.Delete(context.Background(), &pb.TaskIDRequest{Id: uuid.New().String()})
+
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("ожидался код NotFound, получено: %v", err)
+		}
+	})
+
+	t.Run("отметка несуществующей задачи даёт NotFound", func(t *testing.T) {
+		mockRepo := &mockTaskRepository{
+			transitionFn: func(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+				return nil, repositories.ErrNotFound
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		_, err := server.MarkDone(context.Background(), &pb.TaskIDRequest{Id: uuid.New().String()})
+
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("ожидался код NotFound, получено: %v", err)
+		}
+	})
+
+	t.Run("конфликт при создании даёт AlreadyExists", func(t *testing.T) {
+		mockRepo := &mockTaskRepository{
+			createFn: func(ctx context.Context, task *models.Task) error {
+				return repositories.ErrConflict
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		_, err := server.Create(context.Background(), &pb.CreateTaskRequest{Title: "Задача"})
+
+		if status.Code(err) != codes.AlreadyExists {
+			t.Fatalf("ожидался код AlreadyExists, получено: %v", err)
+		}
+	})
+
+	t.Run("несовпадение версии даёт FailedPrecondition", func(t *testing.T) {
+		mockRepo := &mockTaskRepository{
+			updateFn: func(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error) {
+				return nil, repositories.ErrVersionMismatch
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		_, err := server.Update(context.Background(), &pb.UpdateTaskRequest{
+			Id:         uuid.New().String(),
+			Task:       &pb.Task{Title: "Задача"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+		})
+
+		st := status.Convert(err)
+		if st.Code() != codes.FailedPrecondition {
+			t.Fatalf("ожидался код FailedPrecondition, получено: %v", err)
+		}
+		if len(st.Details()) != 1 {
+			t.Fatalf("ожидалась причина VERSION_MISMATCH, получено: %v", st.Details())
+		}
+		if info, ok := st.Details()[0].(*errdetails.ErrorInfo); !ok || info.Reason != reqmeta.VersionMismatchReason {
+			t.Errorf("ожидалась причина VERSION_MISMATCH, получено: %v", st.Details()[0])
+		}
+	})
+
+	t.Run("пустой заголовок даёт InvalidArgument", func(t *testing.T) {
+		service := services.NewTaskService(&mockTaskRepository{}, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		_, err := server.Create(context.Background(), &pb.CreateTaskRequest{Title: "   "})
+
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("ожидался код InvalidArgument, получено: %v", err)
+		}
+	})
+}
+
+func TestTaskServer_ListQuery(t *testing.T) {
+	t.Run("параметры запроса передаются в репозиторий", func(t *testing.T) {
+		after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		done := true
+
+		mockRepo := &mockTaskRepository{
+			listFn: func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+				if q.PageSize != 20 || q.PageToken != "token" || !q.Descending {
+					t.Errorf("неожиданные параметры страницы: %+v", q)
+				}
+				if q.Done == nil || !*q.Done {
+					t.Errorf("ожидался фильтр done=true: %+v", q)
+				}
+				if q.CreatedAfter == nil || !q.CreatedAfter.Equal(after) || q.CreatedBefore != nil {
+					t.Errorf("неожиданный диапазон created_at: %+v", q)
+				}
+				return &models.TaskPage{NextPageToken: "next"}, nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		resp, err := server.List(context.Background(), &pb.ListTasksRequest{
+			PageSize:     20,
+			PageToken:    "token",
+			Done:         &done,
+			CreatedAfter: timestamppb.New(after),
+			Sort:         pb.SortOrder_SORT_ORDER_CREATED_DESC,
+		})
+
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if resp.NextPageToken != "next" {
+			t.Errorf("неожиданный next_page_token: %q", resp.NextPageToken)
+		}
+	})
+
+	t.Run("размер страницы по умолчанию и ограничение сверху", func(t *testing.T) {
+		var sizes []int
+
+		mockRepo := &mockTaskRepository{
+			listFn: func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+				sizes = append(sizes, q.PageSize)
+				return &models.TaskPage{}, nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		for _, size := range []int32{0, 100000} {
+			if _, err := server.List(context.Background(), &pb.ListTasksRequest{PageSize: size}); err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+		}
+
+		if len(sizes) != 2 || sizes[0] != 50 || sizes[1] != 500 {
+			t.Errorf("неожиданные размеры страниц: %v", sizes)
+		}
+	})
+
+	t.Run("разные фильтры кешируются под разными ключами", func(t *testing.T) {
+		keys := map[string]bool{}
+
+		mockCache := &mockTaskCache{
+			setTaskListFn: func(ctx context.Context, key string, page *models.TaskPage, ttl time.Duration) error {
+				keys[key] = true
+				return nil
+			},
+		}
+
+		service := services.NewTaskService(&mockTaskRepository{}, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
+		server := &TaskServer{
+			service: service,
+		}
+
+		done, notDone := true, false
+		for _, req := range []*pb.ListTasksRequest{
+			{}, {Done: &done}, {Done: &notDone}, {Done: &done, PageSize: 10},
+			{Statuses: []string{"todo"}}, {Statuses: []string{"todo", "blocked"}}, {Statuses: []string{"blocked", "todo"}},
+		} {
+			if _, err := server.List(context.Background(), req); err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+		}
+
+		// Порядок статусов на выборку не влияет, поэтому ключ у них общий.
+		if len(keys) != 6 {
+			t.Errorf("ожидалось 6 разных ключей кеша, получено %d", len(keys))
+		}
+	})
+
+	t.Run("запросы по сроку не кешируются", func(t *testing.T) {
+		cached := 0
+		mockCache := &mockTaskCache{
+			getTaskListFn: func(ctx context.Context, key string) (*models.TaskPage, error) {
+				cached++
+				return &models.TaskPage{NextPageToken: "устаревшая"}, nil
+			},
+			setTaskListFn: func(ctx context.Context, key string, page *models.TaskPage, ttl time.Duration) error {
+				cached++
+				return nil
+			},
+		}
+
+		service := services.NewTaskService(&mockTaskRepository{}, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
+		server := &TaskServer{
+			service: service,
+		}
+
+		for _, req := range []*pb.ListTasksRequest{{Overdue: true}, {DueWithin: durationpb.New(time.Hour)}} {
+			resp, err := server.List(context.Background(), req)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if resp.NextPageToken != "" {
+				t.Errorf("страница не должна браться из кеша: %v", req)
+			}
+		}
+		if cached != 0 {
+			t.Errorf("кеш не должен использоваться, обращений: %d", cached)
+		}
+	})
+
+	t.Run("фильтры по сроку передаются в репозиторий", func(t *testing.T) {
+		var queries []models.TaskListQuery
+
+		mockRepo := &mockTaskRepository{
+			listFn: func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+				queries = append(queries, q)
+				return &models.TaskPage{}, nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		for _, req := range []*pb.ListTasksRequest{{Overdue: true}, {DueWithin: durationpb.New(24 * time.Hour)}} {
+			if _, err := server.List(context.Background(), req); err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+		}
+
+		if len(queries) != 2 || !queries[0].Overdue || queries[1].Overdue || queries[1].DueWithin != 24*time.Hour {
+			t.Errorf("неожиданные запросы: %+v", queries)
+		}
+	})
+
+	t.Run("просроченные и скоро истекающие вместе нельзя", func(t *testing.T) {
+		service := services.NewTaskService(&mockTaskRepository{}, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		for _, req := range []*pb.ListTasksRequest{
+			{Overdue: true, DueWithin: durationpb.New(time.Hour)},
+			{DueWithin: durationpb.New(-time.Hour)},
+		} {
+			if _, err := server.List(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%+v: ожидался код InvalidArgument, получено: %v", req, err)
+			}
+		}
+	})
+
+	t.Run("невалидный токен страницы", func(t *testing.T) {
+		mockRepo := &mockTaskRepository{
+			listFn: func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+				return nil, repositories.ErrInvalidCursor
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		_, err := server.List(context.Background(), &pb.ListTasksRequest{PageToken: "мусор"})
+
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("ожидался код InvalidArgument, получено: %v", err)
+		}
+	})
+}
+
+func TestTaskServer_Search(t *testing.T) {
+	t.Run("поиск по in-memory репозиторию", func(t *testing.T) {
+		repo := memory.NewTaskRepo()
+		service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		ctx := userContext("alice", "ws-1")
+		for _, title := range []string{"Написать тесты", "Починить тесты", "Сходить в магазин"} {
+			if _, err := server.Create(ctx, &pb.CreateTaskRequest{Title: title}); err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+		}
+
+		resp, err := server.Search(ctx, &pb.SearchTasksRequest{Query: "тесты"})
+
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if len(resp.Results) != 2 {
+			t.Fatalf("ожидалось 2 результата, получено %d", len(resp.Results))
+		}
+
+		for _, r := range resp.Results {
+			if !strings.Contains(r.TitleSnippet, "<b>тесты</b>") {
+				t.Errorf("совпадение не подсвечено: %s", r.TitleSnippet)
+			}
+		}
+	})
+
+	t.Run("пустой запрос", func(t *testing.T) {
+		mockRepo := &mockTaskRepository{
+			searchFn: func(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+				t.Fatal("репозиторий не должен вызываться")
+				return nil, nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		_, err := server.Search(context.Background(), &pb.SearchTasksRequest{Query: "  "})
+
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("ожидался код InvalidArgument, получено: %v", err)
+		}
+	})
+}
+
+func TestTaskServer_ContextCancellation(t *testing.T) {
+	// slowList ведёт себя как долгий SQL-запрос: завершается только по
+	// отмене контекста или через 10 секунд.
+	slowList := func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+			return &models.TaskPage{}, nil
+		}
+	}
+
+	t.Run("таймаут прерывает медленный запрос", func(t *testing.T) {
+		service := services.NewTaskService(&mockTaskRepository{listFn: slowList}, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := server.List(ctx, &pb.ListTasksRequest{})
+
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Fatalf("ожидался код DeadlineExceeded, получено: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("запрос должен был прерваться по таймауту, прошло %v", elapsed)
+		}
+	})
+
+	t.Run("отмена контекста доходит до репозитория", func(t *testing.T) {
+		started := make(chan struct{})
+		mockRepo := &mockTaskRepository{
+			listFn: func(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+				close(started)
+				return slowList(ctx, q)
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, &mockTaskCache{})
+		server := &TaskServer{
+			service: service,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-started
+			cancel()
+		}()
+
+		_, err := server.List(ctx, &pb.ListTasksRequest{})
+
+		if status.Code(err) != codes.Canceled {
+			t.Fatalf("ожидался код Canceled, получено: %v", err)
+		}
+	})
+
+	t.Run("инвалидация кеша не зависит от отмены", func(t *testing.T) {
+		invalidated := false
+		mockCache := &mockTaskCache{
+			deleteListFn: func(ctx context.Context) error {
+				if ctx.Err() != nil {
+					t.Errorf("контекст инвалидации не должен быть отменён: %v", ctx.Err())
+				}
+				invalidated = true
+				return nil
+			},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		mockRepo := &mockTaskRepository{
+			deleteFn: func(ctx context.Context, id uuid.UUID) error {
+				cancel()
+				return nil
+			},
+		}
+
+		service := services.NewTaskService(mockRepo, &mockPermissionRepository{}, &mockWorkflowRepository{}, &mockHistoryRepository{}, mockCache)
+		server := &TaskServer{
+			service: service,
+		}
+
+		if _, err := server.Delete(ctx, &pb.TaskIDRequest{Id: uuid.New().String()}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if !invalidated {
+			t.Error("список в кеше должен быть инвалидирован")
+		}
+	})
+}
+
+func TestTaskServer_TenantIsolation(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+	server := &TaskServer{
+		service: service,
+	}
+
+	alice := userContext("alice", "ws-1")
+	created, err := server.Create(alice, &pb.CreateTaskRequest{Title: "Задача Алисы"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	id := created.Task.Id
+
+	if created.Task.OwnerId != "alice" || created.Task.WorkspaceId != "ws-1" {
+		t.Errorf("неожиданный владелец: %s/%s", created.Task.WorkspaceId, created.Task.OwnerId)
+	}
+
+	for name, ctx := range map[string]context.Context{
+		"другой пользователь того же workspace":  userContext("bob", "ws-1"),
+		"тот же пользователь в другом workspace": userContext("alice", "ws-2"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := server.Get(ctx, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.NotFound {
+				t.Errorf("Get: ожидался код NotFound, получено: %v", err)
+			}
+
+			req := &pb.UpdateTaskRequest{
+				Id:         id,
+				Task:       &pb.Task{Title: "Чужое"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+			}
+			if _, err := server.Update(ctx, req); status.Code(err) != codes.NotFound {
+				t.Errorf("Update: ожидался код NotFound, получено: %v", err)
+			}
+
+			if _, err := server.MarkDone(ctx, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.NotFound {
+				t.Errorf("MarkDone: ожидался код NotFound, получено: %v", err)
+			}
+
+			if _, err := server.Delete(ctx, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.NotFound {
+				t.Errorf("Delete: ожидался код NotFound, получено: %v", err)
+			}
+
+			list, err := server.List(ctx, &pb.ListTasksRequest{})
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if len(list.Tasks) != 0 {
+				t.Errorf("список должен быть пуст, получено %d задач", len(list.Tasks))
+			}
+		})
+	}
+
+	t.Run("владелец видит задачу неизменной", func(t *testing.T) {
+		resp, err := server.Get(alice, &pb.TaskIDRequest{Id: id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if resp.Task.Title != "Задача Алисы" || resp.Task.Done {
+			t.Errorf("задача изменена другим арендатором: %+v", resp.Task)
+		}
+	})
+
+	t.Run("запрос без пользователя", func(t *testing.T) {
+		if _, err := server.Get(context.Background(), &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("ожидался код Unauthenticated, получено: %v", err)
+		}
+	})
+}
+
+func TestTaskServer_Permissions(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+	server := &TaskServer{
+		service: service,
+	}
+
+	alice := userContext("alice", "ws-1")
+	bob := userContext("bob", "ws-1")
+	carol := userContext("carol", "ws-1")
+
+	created, err := server.Create(alice, &pb.CreateTaskRequest{Title: "Общий список"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	id := created.Task.Id
+
+	for user, role := range map[string]string{"bob": "viewer", "carol": "editor"} {
+		if _, err := server.Share(alice, &pb.ShareTaskRequest{Id: id, UserId: user, Role: role}); err != nil {
+			t.Fatalf("не удалось поделиться задачей с %s: %v", user, err)
+		}
+	}
+
+	rename := func(title string) *pb.UpdateTaskRequest {
+		return &pb.UpdateTaskRequest{
+			Id:         id,
+			Task:       &pb.Task{Title: title},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+		}
+	}
+
+	t.Run("читатель видит задачу, но не меняет её", func(t *testing.T) {
+		if _, err := server.Get(bob, &pb.TaskIDRequest{Id: id}); err != nil {
+			t.Errorf("Get: неожиданная ошибка: %v", err)
+		}
+
+		list, err := server.List(bob, &pb.ListTasksRequest{})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(list.Tasks) != 1 {
+			t.Errorf("ожидалась 1 задача в списке, получено %d", len(list.Tasks))
+		}
+
+		if _, err := server.ListCollaborators(bob, &pb.TaskIDRequest{Id: id}); err != nil {
+			t.Errorf("ListCollaborators: неожиданная ошибка: %v", err)
+		}
+
+		if _, err := server.MarkDone(bob, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("MarkDone: ожидался код PermissionDenied, получено: %v", err)
+		}
+		if _, err := server.Update(bob, rename("Читатель")); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Update: ожидался код PermissionDenied, получено: %v", err)
+		}
+		if _, err := server.Delete(bob, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Delete: ожидался код PermissionDenied, получено: %v", err)
+		}
+	})
+
+	t.Run("редактор меняет задачу, но не удаляет её", func(t *testing.T) {
+		if _, err := server.Update(carol, rename("Редактор")); err != nil {
+			t.Errorf("Update: неожиданная ошибка: %v", err)
+		}
+		if _, err := server.MarkDone(carol, &pb.TaskIDRequest{Id: id}); err != nil {
+			t.Errorf("MarkDone: неожиданная ошибка: %v", err)
+		}
+		if _, err := server.Delete(carol, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Delete: ожидался код PermissionDenied, получено: %v", err)
+		}
+		_, err := server.Share(carol, &pb.ShareTaskRequest{Id: id, UserId: "dave", Role: "viewer"})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Share: ожидался код PermissionDenied, получено: %v", err)
+		}
+	})
+
+	t.Run("без доступа задача не найдена", func(t *testing.T) {
+		for _, ctx := range []context.Context{userContext("dave", "ws-1"), userContext("bob", "ws-2")} {
+			if _, err := server.Get(ctx, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.NotFound {
+				t.Errorf("Get: ожидался код NotFound, получено: %v", err)
+			}
+		}
+	})
+
+	t.Run("неверная роль или владелец как участник", func(t *testing.T) {
+		for _, req := range []*pb.ShareTaskRequest{
+			{Id: id, UserId: "dave", Role: "owner"},
+			{Id: id, UserId: "dave", Role: ""},
+			{Id: id, UserId: "alice", Role: "viewer"},
+		} {
+			if _, err := server.Share(alice, req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%+v: ожидался код InvalidArgument, получено: %v", req, err)
+			}
+		}
+	})
+
+	t.Run("список участников", func(t *testing.T) {
+		resp, err := server.ListCollaborators(alice, &pb.TaskIDRequest{Id: id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		roles := map[string]string{}
+		for _, c := range resp.Collaborators {
+			roles[c.UserId] = c.Role
+		}
+		if len(roles) != 2 || roles["bob"] != "viewer" || roles["carol"] != "editor" {
+			t.Errorf("неожиданные участники: %v", roles)
+		}
+	})
+
+	t.Run("после отзыва доступа задача не видна", func(t *testing.T) {
+		if _, err := server.Unshare(alice, &pb.UnshareTaskRequest{Id: id, UserId: "bob"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := server.Get(bob, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.NotFound {
+			t.Errorf("ожидался код NotFound, получено: %v", err)
+		}
+		if _, err := server.Unshare(alice, &pb.UnshareTaskRequest{Id: id, UserId: "bob"}); status.Code(err) != codes.NotFound {
+			t.Errorf("повторный отзыв: ожидался код NotFound, получено: %v", err)
+		}
+	})
+
+	t.Run("владелец удаляет задачу", func(t *testing.T) {
+		if _, err := server.Delete(alice, &pb.TaskIDRequest{Id: id}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := server.Get(carol, &pb.TaskIDRequest{Id: id}); status.Code(err) != codes.NotFound {
+			t.Errorf("ожидался код NotFound, получено: %v", err)
+		}
+	})
+}
+
+func TestTaskServer_Items(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+	server := &TaskServer{
+		service: service,
+	}
+	alice := userContext("alice", "ws-1")
+
+	created, err := server.Create(alice, &pb.CreateTaskRequest{Title: "Сборы в поход", AutoComplete: true})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	taskID := created.Task.Id
+
+	var task *pb.Task
+	for _, text := range []string{"Палатка", "Спальник", "Котелок"} {
+		resp, err := server.AddItem(alice, &pb.AddItemRequest{TaskId: taskID, Text: text})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		task = resp.Task
+	}
+
+	texts := func(task *pb.Task) []string {
+		var res []string
+		for i, it := range task.Items {
+			if int(it.Position) != i {
+				t.Errorf("позиции должны идти подряд: %d на месте %d", it.Position, i)
+			}
+			res = append(res, it.Text)
+		}
+		return res
+	}
+	check := func(task *pb.Task) {
+		t.Helper()
+		resp, err := server.Get(alice, &pb.TaskIDRequest{Id: taskID})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got, want := strings.Join(texts(resp.Task), ","), strings.Join(texts(task), ","); got != want {
+			t.Errorf("Get вернул пункты %s, ожидалось %s", got, want)
+		}
+	}
+
+	t.Run("пункты добавляются в конец", func(t *testing.T) {
+		if got := strings.Join(texts(task), ","); got != "Палатка,Спальник,Котелок" {
+			t.Errorf("неожиданные пункты: %s", got)
+		}
+		check(task)
+	})
+
+	ids := []string{task.Items[0].Id, task.Items[1].Id, task.Items[2].Id}
+
+	t.Run("перестановка", func(t *testing.T) {
+		resp, err := server.ReorderItems(alice, &pb.ReorderItemsRequest{TaskId: taskID, ItemIds: []string{ids[2], ids[0], ids[1]}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got := strings.Join(texts(resp.Task), ","); got != "Котелок,Палатка,Спальник" {
+			t.Errorf("неожиданный порядок: %s", got)
+		}
+		check(resp.Task)
+	})
+
+	t.Run("перестановка должна перечислить все пункты", func(t *testing.T) {
+		for _, order := range [][]string{{ids[0], ids[1]}, {ids[0], ids[0], ids[1]}, {ids[0], ids[1], uuid.New().String()}} {
+			_, err := server.ReorderItems(alice, &pb.ReorderItemsRequest{TaskId: taskID, ItemIds: order})
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("ожидался код InvalidArgument, получено: %v", err)
+			}
+		}
+	})
+
+	markDone := func(itemID string) (*pb.TaskResponse, error) {
+		return server.UpdateItem(alice, &pb.UpdateItemRequest{
+			TaskId:     taskID,
+			ItemId:     itemID,
+			Item:       &pb.TaskItem{Done: true},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"done"}},
+		})
+	}
+
+	t.Run("задача не завершается, пока есть открытые пункты", func(t *testing.T) {
+		resp, err := markDone(ids[0])
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if resp.Task.Done {
+			t.Error("задача не должна быть выполнена")
+		}
+	})
+
+	t.Run("удаление пункта сдвигает остальные", func(t *testing.T) {
+		resp, err := server.RemoveItem(alice, &pb.ItemIDRequest{TaskId: taskID, ItemId: ids[2]})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got := strings.Join(texts(resp.Task), ","); got != "Палатка,Спальник" {
+			t.Errorf("неожиданные пункты: %s", got)
+		}
+		if _, err := server.RemoveItem(alice, &pb.ItemIDRequest{TaskId: taskID, ItemId: ids[2]}); status.Code(err) != codes.NotFound {
+			t.Errorf("ожидался код NotFound, получено: %v", err)
+		}
+	})
+
+	t.Run("последний выполненный пункт завершает задачу", func(t *testing.T) {
+		before, err := server.Get(alice, &pb.TaskIDRequest{Id: taskID})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		// Ожидаемая версия относится к изменению пункта, а не к завершению задачи.
+		ctx := reqmeta.NewContext(alice, reqmeta.Meta{ExpectedVersions: []int64{before.Task.Version}})
+		resp, err := server.UpdateItem(ctx, &pb.UpdateItemRequest{
+			TaskId:     taskID,
+			ItemId:     ids[1],
+			Item:       &pb.TaskItem{Done: true},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"done"}},
+		})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !resp.Task.Done {
+			t.Error("задача должна быть выполнена")
+		}
+		if resp.Task.Version != before.Task.Version+2 {
+			t.Errorf("ожидалась версия %d, получено %d", before.Task.Version+2, resp.Task.Version)
+		}
+		got, err := server.Get(alice, &pb.TaskIDRequest{Id: taskID})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !got.Task.Done {
+			t.Error("выполнение задачи должно сохраниться")
+		}
+	})
+
+	t.Run("без auto_complete задача не завершается", func(t *testing.T) {
+		created, err := server.Create(alice, &pb.CreateTaskRequest{Title: "Вручную"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		added, err := server.AddItem(alice, &pb.AddItemRequest{TaskId: created.Task.Id, Text: "Единственный"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		resp, err := server.UpdateItem(alice, &pb.UpdateItemRequest{
+			TaskId:     created.Task.Id,
+			ItemId:     added.Task.Items[0].Id,
+			Item:       &pb.TaskItem{Done: true},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"done"}},
+		})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if resp.Task.Done {
+			t.Error("задача без auto_complete не должна завершаться")
+		}
+	})
+
+	t.Run("читатель не меняет пункты", func(t *testing.T) {
+		if _, err := server.Share(alice, &pb.ShareTaskRequest{Id: taskID, UserId: "bob", Role: "viewer"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		bob := userContext("bob", "ws-1")
+		_, err := server.AddItem(bob, &pb.AddItemRequest{TaskId: taskID, Text: "Гитара"})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("ожидался код PermissionDenied, получено: %v", err)
+		}
+	})
+
+	t.Run("пустой текст", func(t *testing.T) {
+		_, err := server.AddItem(alice, &pb.AddItemRequest{TaskId: taskID, Text: " "})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ожидался код InvalidArgument, получено: %v", err)
+		}
+	})
+}
+
+func TestTaskServer_Recurrence(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+	server := &TaskServer{
+		service: service,
+	}
+
+	alice := userContext("alice", "ws-1")
+	carol := userContext("carol", "ws-1")
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("не удалось загрузить часовой пояс: %v", err)
+	}
+	due := time.Date(2026, 1, 31, 18, 0, 0, 0, moscow)
+
+	openTasks := func(t *testing.T) []*pb.Task {
+		t.Helper()
+		notDone := false
+		resp, err := server.List(alice, &pb.ListTasksRequest{Done: &notDone})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		return resp.Tasks
+	}
+
+	created, err := server.Create(alice, &pb.CreateTaskRequest{
+		Title:      "Месячный отчёт",
+		DueAt:      timestamppb.New(due),
+		RemindAt:   timestamppb.New(due.Add(-2 * time.Hour)),
+		Recurrence: "freq=monthly;bymonthday=-1;count=2",
+		TimeZone:   "Europe/Moscow",
+	})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if created.Task.Recurrence != "FREQ=MONTHLY;COUNT=2;BYMONTHDAY=-1" {
+		t.Errorf("правило должно сохраняться в каноническом виде, получено %q", created.Task.Recurrence)
+	}
+	if _, err := server.AddItem(alice, &pb.AddItemRequest{TaskId: created.Task.Id, Text: "Собрать цифры"}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if _, err := server.Share(alice, &pb.ShareTaskRequest{Id: created.Task.Id, UserId: "carol", Role: "editor"}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	var next *pb.Task
+	t.Run("выполнение создаёт следующее повторение", func(t *testing.T) {
+		if _, err := server.MarkDone(carol, &pb.TaskIDRequest{Id: created.Task.Id}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		tasks := openTasks(t)
+		if len(tasks) != 1 {
+			t.Fatalf("ожидалась одна открытая задача, получено %d", len(tasks))
+		}
+		next = tasks[0]
+
+		wantDue := time.Date(2026, 2, 28, 18, 0, 0, 0, moscow)
+		if !next.DueAt.AsTime().Equal(wantDue) || !next.RemindAt.AsTime().Equal(wantDue.Add(-2*time.Hour)) {
+			t.Errorf("неожиданные сроки: due %v, remind %v", next.DueAt.AsTime(), next.RemindAt.AsTime())
+		}
+		if next.Recurrence != "FREQ=MONTHLY;COUNT=1;BYMONTHDAY=-1" || next.TimeZone != "Europe/Moscow" {
+			t.Errorf("неожиданное правило повторения: %q в %q", next.Recurrence, next.TimeZone)
+		}
+		if next.OwnerId != "alice" {
+			t.Errorf("повторение должно принадлежать владельцу, получено %q", next.OwnerId)
+		}
+		if len(next.Items) != 1 || next.Items[0].Text != "Собрать цифры" || next.Items[0].Done {
+			t.Errorf("пункты должны переноситься невыполненными: %+v", next.Items)
+		}
+		if _, err := server.Get(carol, &pb.TaskIDRequest{Id: next.Id}); err != nil {
+			t.Errorf("повторение должно быть доступно участникам: %v", err)
+		}
+	})
+
+	t.Run("повторное выполнение не создаёт дубликат", func(t *testing.T) {
+		if _, err := server.MarkDone(alice, &pb.TaskIDRequest{Id: created.Task.Id}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if tasks := openTasks(t); len(tasks) != 1 {
+			t.Errorf("ожидалась одна открытая задача, получено %d", len(tasks))
+		}
+	})
+
+	t.Run("переоткрытие и выполнение не создают дубликат", func(t *testing.T) {
+		if _, err := server.Transition(alice, &pb.TransitionTaskRequest{Id: created.Task.Id, Status: "todo"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := server.MarkDone(alice, &pb.TaskIDRequest{Id: created.Task.Id}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if tasks := openTasks(t); len(tasks) != 1 || tasks[0].Id != next.Id {
+			t.Errorf("ожидалось только первое повторение, получено %d задач", len(tasks))
+		}
+	})
+
+	t.Run("последнее повторение по COUNT не повторяется", func(t *testing.T) {
+		if _, err := server.MarkDone(alice, &pb.TaskIDRequest{Id: next.Id}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if tasks := openTasks(t); len(tasks) != 0 {
+			t.Errorf("открытых задач не должно остаться, получено %d", len(tasks))
+		}
+	})
+
+	t.Run("неверное правило, часовой пояс или нет срока", func(t *testing.T) {
+		for _, req := range []*pb.CreateTaskRequest{
+			{Title: "Плохое правило", DueAt: timestamppb.New(due), Recurrence: "FREQ=HOURLY"},
+			{Title: "Плохой пояс", DueAt: timestamppb.New(due), Recurrence: "FREQ=DAILY", TimeZone: "Mars/Olympus"},
+			{Title: "Без срока", Recurrence: "FREQ=DAILY"},
+		} {
+			if _, err := server.Create(alice, req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%s: ожидался код InvalidArgument, получено: %v", req.Title, err)
+			}
+		}
+	})
+
+	t.Run("изменение не оставляет повторение без срока", func(t *testing.T) {
+		plain, err := server.Create(alice, &pb.CreateTaskRequest{Title: "Без срока"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		recurring, err := server.Get(alice, &pb.TaskIDRequest{Id: next.Id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		for name, req := range map[string]*pb.UpdateTaskRequest{
+			"правило без срока": {
+				Id:         plain.Task.Id,
+				Task:       &pb.Task{Recurrence: "FREQ=DAILY"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"recurrence"}},
+			},
+			"снятие срока": {
+				Id:         next.Id,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"due_at"}},
+			},
+		} {
+			if _, err := server.Update(alice, req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%s: ожидался код InvalidArgument, получено: %v", name, err)
+			}
+		}
+
+		got, err := server.Get(alice, &pb.TaskIDRequest{Id: next.Id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.Task.DueAt == nil || got.Task.Version != recurring.Task.Version {
+			t.Errorf("задача не должна меняться: %v", got.Task)
+		}
+	})
+}
+
+func TestTaskServer_Workflow(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+	server := &TaskServer{
+		service: service,
+	}
+
+	alice := userContext("alice", "ws-1")
+	admin := auth.NewContext(context.Background(), &auth.Principal{Subject: "alice", WorkspaceID: "ws-1", Admin: true})
+	bob := userContext("bob", "ws-1")
+
+	create := func(t *testing.T, req *pb.CreateTaskRequest) *pb.Task {
+		t.Helper()
+		resp, err := server.Create(alice, req)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		return resp.Task
+	}
+	move := func(ctx context.Context, id, to string) (*pb.Task, error) {
+		resp, err := server.Transition(ctx, &pb.TransitionTaskRequest{Id: id, Status: to})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Task, nil
+	}
+
+	task := create(t, &pb.CreateTaskRequest{Title: "Ревью"})
+	if task.Status != "todo" || task.Done || task.StatusChangedAt != nil {
+		t.Fatalf("новая задача должна быть в todo, получено %q", task.Status)
+	}
+
+	t.Run("переход записывает автора и время", func(t *testing.T) {
+		moved, err := move(alice, task.Id, "in_progress")
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if moved.Status != "in_progress" || moved.Done || moved.StatusChangedBy != "alice" || moved.StatusChangedAt == nil {
+			t.Errorf("неожиданная задача после перехода: %+v", moved)
+		}
+	})
+
+	t.Run("фильтр по статусам", func(t *testing.T) {
+		create(t, &pb.CreateTaskRequest{Title: "Ещё не начата"})
+		resp, err := server.List(alice, &pb.ListTasksRequest{Statuses: []string{"in_progress", "blocked"}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(resp.Tasks) != 1 || resp.Tasks[0].Id != task.Id {
+			t.Errorf("ожидалась только задача в работе, получено %d задач", len(resp.Tasks))
+		}
+		if _, err := server.List(alice, &pb.ListTasksRequest{Statuses: []string{"archived"}}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ожидался код InvalidArgument, получено: %v", err)
+		}
+	})
+
+	t.Run("MarkDone переводит в done, задачу можно переоткрыть", func(t *testing.T) {
+		if _, err := server.MarkDone(alice, &pb.TaskIDRequest{Id: task.Id}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		got, err := server.Get(alice, &pb.TaskIDRequest{Id: task.Id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.Task.Status != "done" || !got.Task.Done {
+			t.Errorf("ожидался статус done, получено %q", got.Task.Status)
+		}
+
+		reopened, err := move(alice, task.Id, "todo")
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if reopened.Status != "todo" || reopened.Done {
+			t.Errorf("ожидался статус todo, получено %q", reopened.Status)
+		}
+	})
+
+	t.Run("недопустимый переход и неизвестный статус", func(t *testing.T) {
+		if _, err := move(alice, task.Id, "cancelled"); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := move(alice, task.Id, "done"); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("cancelled → done: ожидался код FailedPrecondition, получено: %v", err)
+		}
+		if _, err := move(alice, task.Id, "archived"); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ожидался код InvalidArgument, получено: %v", err)
+		}
+	})
+
+	t.Run("зрителю переход запрещён", func(t *testing.T) {
+		if _, err := server.Share(alice, &pb.ShareTaskRequest{Id: task.Id, UserId: "bob", Role: "viewer"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := move(bob, task.Id, "todo"); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("ожидался код PermissionDenied, получено: %v", err)
+		}
+	})
+
+	t.Run("процесс меняет только администратор", func(t *testing.T) {
+		req := &pb.SetWorkflowRequest{Transitions: []*pb.WorkflowTransition{
+			{From: "todo", To: "in_progress"},
+			{From: "in_progress", To: "done"},
+			{From: "todo", To: "in_progress"},
+		}}
+		if _, err := server.SetWorkflow(alice, req); status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("ожидался код PermissionDenied, получено: %v", err)
+		}
+		wf, err := server.SetWorkflow(admin, req)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(wf.Transitions) != 2 || wf.WorkspaceId != "ws-1" {
+			t.Errorf("ожидалось два различных перехода, получено %+v", wf.Transitions)
+		}
+		if _, err := server.SetWorkflow(admin, &pb.SetWorkflowRequest{Transitions: []*pb.WorkflowTransition{{From: "todo", To: "todo"}}}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("переход в себя: ожидался код InvalidArgument, получено: %v", err)
+		}
+	})
+
+	t.Run("свой процесс действует на переходы и автозавершение", func(t *testing.T) {
+		other := create(t, &pb.CreateTaskRequest{Title: "Строгая", AutoComplete: true})
+		if _, err := server.MarkDone(alice, &pb.TaskIDRequest{Id: other.Id}); status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("todo → done: ожидался код FailedPrecondition, получено: %v", err)
+		}
+
+		added, err := server.AddItem(alice, &pb.AddItemRequest{TaskId: other.Id, Text: "Шаг"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		updated, err := server.UpdateItem(alice, &pb.UpdateItemRequest{
+			TaskId:     other.Id,
+			ItemId:     added.Task.Items[0].Id,
+			Item:       &pb.TaskItem{Done: true},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"done"}},
+		})
+		if err != nil {
+			t.Fatalf("автозавершение не должно мешать отметке пункта: %v", err)
+		}
+		if updated.Task.Status != "todo" {
+			t.Errorf("процесс не разрешает todo → done, получено %q", updated.Task.Status)
+		}
+	})
+
+	t.Run("пустой список возвращает процесс по умолчанию", func(t *testing.T) {
+		wf, err := server.SetWorkflow(admin, &pb.SetWorkflowRequest{})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(wf.Transitions) != len(models.DefaultTransitions) {
+			t.Errorf("ожидался процесс по умолчанию, получено %d переходов", len(wf.Transitions))
+		}
+		got, err := server.GetWorkflow(bob, &emptypb.Empty{})
+		if err != nil || len(got.Transitions) != len(models.DefaultTransitions) {
+			t.Errorf("процесс должны видеть все участники: %v", err)
+		}
+	})
+}
+
+func TestTaskServer_Trash(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+	server := &TaskServer{
+		service: service,
+	}
+
+	alice := userContext("alice", "ws-1")
+	bob := userContext("bob", "ws-1")
+
+	resp, err := server.Create(alice, &pb.CreateTaskRequest{Title: "Черновик"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	task := resp.Task
+	if _, err := server.Share(alice, &pb.ShareTaskRequest{Id: task.Id, UserId: "bob", Role: "editor"}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	t.Run("удалённая задача попадает в корзину владельца", func(t *testing.T) {
+		if _, err := server.Delete(alice, &pb.TaskIDRequest{Id: task.Id}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := server.Get(alice, &pb.TaskIDRequest{Id: task.Id}); status.Code(err) != codes.NotFound {
+			t.Errorf("ожидался код NotFound, получено: %v", err)
+		}
+		list, err := server.List(bob, &pb.ListTasksRequest{})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(list.Tasks) != 0 {
+			t.Errorf("удалённая задача не должна быть в списке, получено %d задач", len(list.Tasks))
+		}
+
+		trash, err := server.ListTrash(alice, &pb.ListTrashRequest{})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(trash.Tasks) != 1 || trash.Tasks[0].Id != task.Id || trash.Tasks[0].DeletedAt == nil {
+			t.Fatalf("ожидалась одна задача в корзине, получено %+v", trash.Tasks)
+		}
+		trash, err = server.ListTrash(bob, &pb.ListTrashRequest{})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(trash.Tasks) != 0 {
+			t.Errorf("корзина соавтора должна быть пуста, получено %d задач", len(trash.Tasks))
+		}
+	})
+
+	t.Run("восстановить может только владелец", func(t *testing.T) {
+		if _, err := server.Restore(bob, &pb.TaskIDRequest{Id: task.Id}); status.Code(err) != codes.NotFound {
+			t.Errorf("ожидался код NotFound, получено: %v", err)
+		}
+		restored, err := server.Restore(alice, &pb.TaskIDRequest{Id: task.Id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if restored.Task.DeletedAt != nil || restored.Task.Title != "Черновик" {
+			t.Errorf("неожиданная задача после восстановления: %+v", restored.Task)
+		}
+		if _, err := server.Get(bob, &pb.TaskIDRequest{Id: task.Id}); err != nil {
+			t.Errorf("соавтор должен снова видеть задачу: %v", err)
+		}
+		if _, err := server.Restore(alice, &pb.TaskIDRequest{Id: task.Id}); status.Code(err) != codes.NotFound {
+			t.Errorf("повторное восстановление: ожидался код NotFound, получено: %v", err)
+		}
+	})
+
+	t.Run("переоткрытие закрытой задачи", func(t *testing.T) {
+		if _, err := server.MarkDone(bob, &pb.TaskIDRequest{Id: task.Id}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		reopened, err := server.Reopen(bob, &pb.TaskIDRequest{Id: task.Id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if reopened.Task.Status != "todo" || reopened.Task.Done {
+			t.Errorf("ожидался статус todo, получено %q", reopened.Task.Status)
+		}
+
+		if _, err := server.Transition(alice, &pb.TransitionTaskRequest{Id: task.Id, Status: "in_progress"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		open, err := server.Reopen(alice, &pb.TaskIDRequest{Id: task.Id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if open.Task.Status != "in_progress" {
+			t.Errorf("открытая задача не должна меняться, получено %q", open.Task.Status)
+		}
+	})
+
+	t.Run("переоткрытие запрещено зрителю", func(t *testing.T) {
+		if _, err := server.Share(alice, &pb.ShareTaskRequest{Id: task.Id, UserId: "carol", Role: "viewer"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := server.Reopen(userContext("carol", "ws-1"), &pb.TaskIDRequest{Id: task.Id}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("ожидался код PermissionDenied, получено: %v", err)
+		}
+	})
+}
+
+func TestTaskServer_History(t *testing.T) {
+	repo := memory.NewTaskRepo()
+	service := services.NewTaskService(repo, memory.NewPermissionRepo(repo), memory.NewWorkflowRepo(), memory.NewHistoryRepo(repo), &mockTaskCache{})
+	server := &TaskServer{
+		service: service,
+	}
+
+	alice := userContext("alice", "ws-1")
+	bob := userContext("bob", "ws-1")
+	admin := auth.NewContext(context.Background(), &auth.Principal{Subject: "carol", WorkspaceID: "ws-1", Admin: true})
+
+	resp, err := server.Create(alice, &pb.CreateTaskRequest{Title: "Отчёт"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	id := resp.Task.Id
+	steps := []func() error{
+		func() error {
+			_, err := server.Update(alice, &pb.UpdateTaskRequest{
+				Id:         id,
+				Task:       &pb.Task{Title: "Годовой отчёт"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+			})
+			return err
+		},
+		func() error {
+			_, err := server.MarkDone(alice, &pb.TaskIDRequest{Id: id})
+			return err
+		},
+		func() error {
+			_, err := server.Delete(alice, &pb.TaskIDRequest{Id: id})
+			return err
+		},
+		func() error {
+			_, err := server.Restore(alice, &pb.TaskIDRequest{Id: id})
+			return err
+		},
+		func() error {
+			_, err := server.Share(alice, &pb.ShareTaskRequest{Id: id, UserId: "bob", Role: "viewer"})
+			return err
+		},
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+
+	t.Run("история задачи видна зрителю", func(t *testing.T) {
+		history, err := server.GetHistory(bob, &pb.GetHistoryRequest{Id: id})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		var types []string
+		for _, e := range history.Events {
+			types = append(types, e.Type)
+			if e.Actor != "alice" || e.TaskId != id {
+				t.Errorf("неожиданная запись: %+v", e)
+			}
+		}
+		want := []string{"task.restored", "task.deleted", "task.done", "task.updated", "task.created"}
+		if !slices.Equal(types, want) {
+			t.Fatalf("ожидались записи %v, получено %v", want, types)
+		}
+
+		updated := history.Events[3].Changes
+		if len(updated) != 1 || updated[0].Field != "title" || updated[0].Before != `"Отчёт"` || updated[0].After != `"Годовой отчёт"` {
+			t.Errorf("неожиданные изменения при обновлении: %+v", updated)
+		}
+		deleted := history.Events[1].Changes
+		if len(deleted) != 1 || deleted[0].Field != "deleted_at" || deleted[0].Before != "" || deleted[0].After == "" {
+			t.Errorf("неожиданные изменения при удалении: %+v", deleted)
+		}
+	})
+
+	t.Run("постраничная история", func(t *testing.T) {
+		first, err := server.GetHistory(alice, &pb.GetHistoryRequest{Id: id, PageSize: 3})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(first.Events) != 3 || first.NextPageToken == "" {
+			t.Fatalf("ожидалась полная первая страница, получено %d записей", len(first.Events))
+		}
+		second, err := server.GetHistory(alice, &pb.GetHistoryRequest{Id: id, PageSize: 3, PageToken: first.NextPageToken})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(second.Events) != 2 || second.NextPageToken != "" || second.Events[1].Type != "task.created" {
+			t.Errorf("неожиданная вторая страница: %+v", second.Events)
+		}
+	})
+
+	t.Run("чужая история недоступна", func(t *testing.T) {
+		if _, err := server.GetHistory(userContext("dave", "ws-1"), &pb.GetHistoryRequest{Id: id}); status.Code(err) != codes.NotFound {
+			t.Errorf("ожидался код NotFound, получено: %v", err)
+		}
+	})
+
+	t.Run("журнал аудита только для администратора", func(t *testing.T) {
+		if _, err := server.ListAudit(alice, &pb.ListAuditRequest{}); status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("ожидался код PermissionDenied, получено: %v", err)
+		}
+
+		other, err := server.Create(bob, &pb.CreateTaskRequest{Title: "Задача Боба"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := server.Create(userContext("bob", "ws-2"), &pb.CreateTaskRequest{Title: "Другое пространство"}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		all, err := server.ListAudit(admin, &pb.ListAuditRequest{})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(all.Events) != 6 || all.Events[0].TaskId != other.Task.Id {
+			t.Errorf("ожидалось 6 записей пространства, новые первыми, получено %d", len(all.Events))
+		}
+
+		byBob, err := server.ListAudit(admin, &pb.ListAuditRequest{Actor: "bob", Types: []string{"task.created"}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(byBob.Events) != 1 || byBob.Events[0].TaskId != other.Task.Id {
+			t.Errorf("ожидалась одна запись Боба, получено %+v", byBob.Events)
+		}
+
+		byTask, err := server.ListAudit(admin, &pb.ListAuditRequest{TaskId: id, Types: []string{"task.deleted", "task.restored"}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(byTask.Events) != 2 {
+			t.Errorf("ожидались две записи, получено %d", len(byTask.Events))
+		}
+
+		future, err := server.ListAudit(admin, &pb.ListAuditRequest{Since: timestamppb.New(time.Now().Add(time.Hour))})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(future.Events) != 0 {
+			t.Errorf("ожидался пустой журнал, получено %d записей", len(future.Events))
+		}
+	})
+
+	t.Run("неверные параметры аудита", func(t *testing.T) {
+		now := time.Now()
+		for name, req := range map[string]*pb.ListAuditRequest{
+			"неверный ID задачи":    {TaskId: "не-uuid"},
+			"неверный токен":        {PageToken: "!"},
+			"since не раньше until": {Since: timestamppb.New(now), Until: timestamppb.New(now)},
+			"отрицательный размер":  {PageSize: -1},
+		} {
+			if _, err := server.ListAudit(admin, req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("%s: ожидался код InvalidArgument, получено: %v", name, err)
+			}
+		}
+	})
+}
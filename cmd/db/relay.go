@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	events "github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+// outboxStore is the part of repositories.PostgresOutbox used by the relay.
+type outboxStore interface {
+	Relay(ctx context.Context, limit int, publish func(ctx context.Context, msgs []repositories.OutboxMessage) error) (int, error)
+}
+
+type messagePublisher interface {
+	SendMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// outboxRelay publishes the events stored in the outbox to Kafka. A message
+// is marked sent only after Kafka has accepted it, so delivery is
+// at-least-once: consumers may see a message twice, but never miss one.
+type outboxRelay struct {
+	store     outboxStore
+	publisher messagePublisher
+	interval  time.Duration
+	batchSize int
+}
+
+// run relays pending messages until ctx is cancelled. After a full batch,
+// which may be larger than batchSize to keep the events of a transaction
+// together, it goes on with the next one at once, otherwise it waits for
+// interval.
+func (r *outboxRelay) run(ctx context.Context) {
+	for {
+		n, err := r.store.Relay(ctx, r.batchSize, r.publish)
+		if err != nil && ctx.Err() == nil {
+			log.Println("outbox relay:", err)
+		}
+		if err == nil && n >= r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+func (r *outboxRelay) publish(ctx context.Context, msgs []repositories.OutboxMessage) error {
+	batch := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		// The consumer continues the trace of the request that made the change.
+		batch[i] = kafka.Message{Key: m.Key, Value: m.Payload, Headers: events.TraceHeaders(m.TraceContext)}
+	}
+	return r.publisher.SendMessages(ctx, batch...)
+}
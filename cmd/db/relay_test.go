@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeOutbox hands out its pending messages like PostgresOutbox: a message
+// leaves the queue only when publish succeeds.
+type fakeOutbox struct {
+	mu      sync.Mutex
+	pending []repositories.OutboxMessage
+}
+
+func (o *fakeOutbox) Relay(ctx context.Context, limit int, publish func(ctx context.Context, msgs []repositories.OutboxMessage) error) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	batch := o.pending
+	if len(batch) > limit {
+		batch = batch[:limit]
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := publish(ctx, batch); err != nil {
+		return len(batch), err
+	}
+	o.pending = o.pending[len(batch):]
+	return len(batch), nil
+}
+
+func (o *fakeOutbox) left() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+type fakePublisher struct {
+	mu       sync.Mutex
+	failures int
+	sent     []kafka.Message
+}
+
+func (p *fakePublisher) SendMessages(ctx context.Context, msgs ...kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("kafka недоступна")
+	}
+	p.sent = append(p.sent, msgs...)
+	return nil
+}
+
+func TestOutboxRelay(t *testing.T) {
+	t.Run("сообщения публикуются после сбоев и помечаются отправленными", func(t *testing.T) {
+		store := &fakeOutbox{}
+		for i := 0; i < 5; i++ {
+			store.pending = append(store.pending, repositories.OutboxMessage{
+				ID:      int64(i + 1),
+				Key:     []byte("task"),
+				Payload: []byte{byte('a' + i)},
+			})
+		}
+		publisher := &fakePublisher{failures: 2}
+		relay := &outboxRelay{store: store, publisher: publisher, interval: time.Millisecond, batchSize: 2}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			relay.run(ctx)
+			close(done)
+		}()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for store.left() > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		<-done
+
+		if store.left() != 0 {
+			t.Fatalf("в outbox остались сообщения: %d", store.left())
+		}
+		if len(publisher.sent) != 5 {
+			t.Fatalf("ожидалось 5 отправленных сообщений, получено %d", len(publisher.sent))
+		}
+		for i, msg := range publisher.sent {
+			if string(msg.Key) != "task" || msg.Value[0] != byte('a'+i) {
+				t.Errorf("сообщения должны уходить по порядку: %d -> %q", i, msg.Value)
+			}
+		}
+	})
+
+	t.Run("отмена контекста останавливает relay", func(t *testing.T) {
+		relay := &outboxRelay{store: &fakeOutbox{}, publisher: &fakePublisher{}, interval: time.Hour, batchSize: 10}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			relay.run(ctx)
+			close(done)
+		}()
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("relay не остановился после отмены контекста")
+		}
+	})
+}
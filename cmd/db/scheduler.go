@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+)
+
+// schedulerActor is the actor of the events sent by the scheduler.
+const schedulerActor = "scheduler"
+
+// schedulerStore is the part of repositories.PostgresScheduler used by the
+// scheduler.
+type schedulerStore interface {
+	Remind(ctx context.Context, limit int) (int, error)
+	NotifyOverdue(ctx context.Context, limit int) (int, error)
+	PurgeTrash(ctx context.Context, retention time.Duration, limit int) (int, error)
+	PurgeOutbox(ctx context.Context, retention time.Duration, limit int) (int, error)
+}
+
+// taskScheduler sends task.reminder and task.overdue events when the remind
+// or due time of a task passes, purges tasks that have been in the trash for
+// longer than retention and outbox messages sent longer than outboxRetention
+// ago. The events go through the outbox, so they reach Kafka with the relay
+// like any other.
+type taskScheduler struct {
+	store     schedulerStore
+	interval  time.Duration
+	batchSize int
+	// retention of deleted tasks; the trash is never purged if it is zero.
+	retention time.Duration
+	// outboxRetention of sent messages; they are kept forever if it is zero.
+	outboxRetention time.Duration
+}
+
+// run notifies about due tasks and purges the trash and the outbox until ctx
+// is cancelled.
+// After a full batch of any kind it polls again at once, otherwise it waits
+// for interval.
+func (s *taskScheduler) run(ctx context.Context) {
+	ctx = reqmeta.NewContext(ctx, reqmeta.Meta{Actor: schedulerActor})
+	type step struct {
+		name   string
+		notify func(ctx context.Context, limit int) (int, error)
+	}
+	steps := []step{
+		{"reminders", s.store.Remind},
+		{"overdue tasks", s.store.NotifyOverdue},
+	}
+	if s.retention > 0 {
+		steps = append(steps, step{"trash", func(ctx context.Context, limit int) (int, error) {
+			return s.store.PurgeTrash(ctx, s.retention, limit)
+		}})
+	}
+	if s.outboxRetention > 0 {
+		steps = append(steps, step{"sent outbox messages", func(ctx context.Context, limit int) (int, error) {
+			return s.store.PurgeOutbox(ctx, s.outboxRetention, limit)
+		}})
+	}
+
+	for {
+		full := false
+		for _, step := range steps {
+			n, err := step.notify(ctx, s.batchSize)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("scheduler: %s: %v", step.name, err)
+			}
+			if err == nil && n == s.batchSize {
+				full = true
+			}
+		}
+		if full {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.interval):
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+)
+
+// fakeReminders counts the due tasks of each kind like PostgresScheduler:
+// a task or an outbox message is notified or purged once, in batches of at
+// most limit.
+type fakeReminders struct {
+	mu        sync.Mutex
+	reminders int
+	overdue   int
+	trashed   int
+	sent      int
+	failures  int
+	actors    map[string]bool
+	retention time.Duration
+
+	outboxRetention time.Duration
+}
+
+func (f *fakeReminders) take(ctx context.Context, pending *int, limit int) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.actors == nil {
+		f.actors = map[string]bool{}
+	}
+	f.actors[reqmeta.FromContext(ctx).Actor] = true
+	if f.failures > 0 {
+		f.failures--
+		return 0, errors.New("postgres недоступен")
+	}
+	n := min(*pending, limit)
+	*pending -= n
+	return n, nil
+}
+
+func (f *fakeReminders) Remind(ctx context.Context, limit int) (int, error) {
+	return f.take(ctx, &f.reminders, limit)
+}
+
+func (f *fakeReminders) NotifyOverdue(ctx context.Context, limit int) (int, error) {
+	return f.take(ctx, &f.overdue, limit)
+}
+
+func (f *fakeReminders) PurgeTrash(ctx context.Context, retention time.Duration, limit int) (int, error) {
+	f.mu.Lock()
+	f.retention = retention
+	f.mu.Unlock()
+	return f.take(ctx, &f.trashed, limit)
+}
+
+func (f *fakeReminders) PurgeOutbox(ctx context.Context, retention time.Duration, limit int) (int, error) {
+	f.mu.Lock()
+	f.outboxRetention = retention
+	f.mu.Unlock()
+	return f.take(ctx, &f.sent, limit)
+}
+
+func (f *fakeReminders) left() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reminders + f.overdue + f.trashed + f.sent
+}
+
+func TestTaskScheduler(t *testing.T) {
+	t.Run("все напоминания и просрочки отправляются пачками после сбоев", func(t *testing.T) {
+		store := &fakeReminders{reminders: 7, overdue: 3, trashed: 5, sent: 4, failures: 2}
+		scheduler := &taskScheduler{store: store, interval: time.Millisecond, batchSize: 2, retention: time.Hour, outboxRetention: time.Minute}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			scheduler.run(ctx)
+			close(done)
+		}()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for store.left() > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		<-done
+
+		if store.left() != 0 {
+			t.Fatalf("остались неотправленные задачи: %d", store.left())
+		}
+		if len(store.actors) != 1 || !store.actors[schedulerActor] {
+			t.Errorf("события должны идти от имени планировщика, получено %v", store.actors)
+		}
+		if store.retention != time.Hour {
+			t.Errorf("ожидался срок хранения 1h, получено %v", store.retention)
+		}
+		if store.outboxRetention != time.Minute {
+			t.Errorf("ожидался срок хранения outbox 1m, получено %v", store.outboxRetention)
+		}
+	})
+
+	t.Run("без срока хранения корзина и outbox не очищаются", func(t *testing.T) {
+		store := &fakeReminders{reminders: 3, trashed: 2, sent: 2}
+		scheduler := &taskScheduler{store: store, interval: time.Millisecond, batchSize: 2}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			scheduler.run(ctx)
+			close(done)
+		}()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for store.left() > 4 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		<-done
+
+		if store.left() != 4 {
+			t.Fatalf("ожидалось 4 задачи в корзине и сообщения в outbox, осталось %d", store.left())
+		}
+	})
+
+	t.Run("отмена контекста останавливает планировщик", func(t *testing.T) {
+		scheduler := &taskScheduler{store: &fakeReminders{}, interval: time.Hour, batchSize: 10}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			scheduler.run(ctx)
+			close(done)
+		}()
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("планировщик не остановился после отмены контекста")
+		}
+	})
+}
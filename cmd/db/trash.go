@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/services"
+)
+
+func (s *TaskServer) Restore(ctx context.Context, req *pb.TaskIDRequest) (*pb.TaskResponse, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	task, err := s.service.Restore(ctx, id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+func (s *TaskServer) ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.TaskListResponse, error) {
+	page, err := s.service.ListTrash(ctx, models.TaskListQuery{
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.TaskListResponse{NextPageToken: page.NextPageToken}
+	for _, t := range page.Tasks {
+		resp.Tasks = append(resp.Tasks, toPBTask(&t))
+	}
+	return resp, nil
+}
+
+func (s *TaskServer) Reopen(ctx context.Context, req *pb.TaskIDRequest) (*pb.TaskResponse, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	task, err := s.service.Reopen(ctx, id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
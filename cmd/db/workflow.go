@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/pb"
+	"github.com/kalpovskii/checklist/internal/app/services"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func toPBWorkflow(wf *models.Workflow) *pb.Workflow {
+	res := &pb.Workflow{WorkspaceId: wf.WorkspaceID}
+	for _, t := range wf.Transitions {
+		res.Transitions = append(res.Transitions, &pb.WorkflowTransition{From: string(t.From), To: string(t.To)})
+	}
+	return res
+}
+
+func (s *TaskServer) Transition(ctx context.Context, req *pb.TransitionTaskRequest) (*pb.TaskResponse, error) {
+	id, err := services.ParseID(req.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	task, err := s.service.Transition(ctx, id, models.Status(req.Status))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.TaskResponse{Task: toPBTask(task)}, nil
+}
+
+func (s *TaskServer) GetWorkflow(ctx context.Context, _ *emptypb.Empty) (*pb.Workflow, error) {
+	wf, err := s.service.Workflow(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPBWorkflow(wf), nil
+}
+
+func (s *TaskServer) SetWorkflow(ctx context.Context, req *pb.SetWorkflowRequest) (*pb.Workflow, error) {
+	transitions := make([]models.Transition, 0, len(req.Transitions))
+	for _, t := range req.Transitions {
+		transitions = append(transitions, models.Transition{From: models.Status(t.From), To: models.Status(t.To)})
+	}
+	wf, err := s.service.SetWorkflow(ctx, transitions)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPBWorkflow(wf), nil
+}
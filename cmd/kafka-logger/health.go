@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// consumerHealth tracks the progress of the logger for its health endpoint.
+// The logger counts as stalled when it is behind the topic and has not
+// logged anything for stallTimeout.
+type consumerHealth struct {
+	lag          func() int64
+	stallTimeout time.Duration
+	now          func() time.Time
+
+	mu            sync.Mutex
+	startedAt     time.Time
+	lastMessageAt time.Time
+}
+
+func newConsumerHealth(lag func() int64, stallTimeout time.Duration) *consumerHealth {
+	return &consumerHealth{lag: lag, stallTimeout: stallTimeout, now: time.Now, startedAt: time.Now()}
+}
+
+// observe records that a message has been logged.
+func (h *consumerHealth) observe() {
+	h.mu.Lock()
+	h.lastMessageAt = h.now()
+	h.mu.Unlock()
+}
+
+type healthReport struct {
+	Status        string     `json:"status"`
+	Lag           int64      `json:"lag"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+}
+
+func (h *consumerHealth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	last := h.lastMessageAt
+	h.mu.Unlock()
+
+	report := healthReport{Status: "ok", Lag: h.lag()}
+	since := h.startedAt
+	if !last.IsZero() {
+		report.LastMessageAt = &last
+		since = last
+	}
+
+	code := http.StatusOK
+	if report.Lag > 0 && h.now().Sub(since) > h.stallTimeout {
+		code, report.Status = http.StatusServiceUnavailable, "stalled"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
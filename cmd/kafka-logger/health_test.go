@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConsumerHealth(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	var lag int64
+
+	h := newConsumerHealth(func() int64 { return lag }, time.Minute)
+	h.now = func() time.Time { return now }
+	h.startedAt = start
+
+	check := func(wantCode int, wantStatus string) healthReport {
+		t.Helper()
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		var report healthReport
+		if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if resp.Code != wantCode || report.Status != wantStatus {
+			t.Fatalf("ожидалось %d %q, получено %d %q", wantCode, wantStatus, resp.Code, report.Status)
+		}
+		return report
+	}
+
+	t.Run("без сообщений и без отставания", func(t *testing.T) {
+		now = start.Add(time.Hour)
+		if report := check(http.StatusOK, "ok"); report.LastMessageAt != nil {
+			t.Errorf("время последнего сообщения должно отсутствовать: %v", report.LastMessageAt)
+		}
+	})
+
+	t.Run("отставание при недавнем сообщении", func(t *testing.T) {
+		h.observe()
+		lag = 10
+		now = now.Add(30 * time.Second)
+
+		report := check(http.StatusOK, "ok")
+		if report.Lag != 10 || report.LastMessageAt == nil || !report.LastMessageAt.Equal(start.Add(time.Hour)) {
+			t.Errorf("неожиданный отчёт: %+v", report)
+		}
+	})
+
+	t.Run("отставание без сообщений дольше таймаута", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		check(http.StatusServiceUnavailable, "stalled")
+	})
+}
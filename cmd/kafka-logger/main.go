@@ -1,18 +1,33 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	events "github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/kalpovskii/checklist/internal/metrics"
+	"github.com/kalpovskii/checklist/internal/tracing"
 	"github.com/segmentio/kafka-go"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func initConfig() {
 	viper.SetEnvPrefix("CHECKLIST")
 	viper.AutomaticEnv()
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 15*time.Second)
+	viper.SetDefault("KAFKA_LOGGER_HEALTH_PORT", "8081")
+	viper.SetDefault("KAFKA_LOGGER_STALL_TIMEOUT", time.Minute)
 }
 
 func main() {
@@ -26,11 +41,20 @@ func main() {
 		log.Fatal("KAFKA_BROKER, KAFKA_TOPIC or KAFKA_LOG_FILE is not configured")
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName:  "checklist-kafka-logger",
+		Exporter:     viper.GetString("OTEL_EXPORTER"),
+		OTLPEndpoint: viper.GetString("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		File:         viper.GetString("OTEL_EXPORTER_FILE"),
+	})
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+
 	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Fatalf("failed to open log file: %v", err)
 	}
-	defer file.Close()
 
 	logger := log.New(file, "", log.LstdFlags)
 	logger.Println("Kafka Logger started")
@@ -41,13 +65,115 @@ func main() {
 		GroupID: "kafka-logger-group",
 	})
 
-	for {
-		m, err := r.ReadMessage(context.Background())
+	health := newConsumerHealth(func() int64 { return r.Stats().Lag }, viper.GetDuration("KAFKA_LOGGER_STALL_TIMEOUT"))
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", health)
+	mux.Handle("/metrics", metrics.Handler())
+	healthSrv := &http.Server{Addr: ":" + viper.GetString("KAFKA_LOGGER_HEALTH_PORT"), Handler: mux}
+	go func() {
+		if err := healthSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("health server failed: %v", err)
+		}
+	}()
+
+	// ctx is cancelled on SIGINT or SIGTERM, which starts the shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// ReadMessage commits the offset of every message it returns, so the
+	// message being logged when the signal arrives is the last one read.
+	for ctx.Err() == nil {
+		m, err := r.ReadMessage(ctx)
 		if err != nil {
-			logger.Printf("error reading message: %v\n", err)
+			if ctx.Err() == nil {
+				logger.Printf("error reading message: %v\n", err)
+			}
 			continue
 		}
 
-		logger.Printf("[%s] %s\n", time.Now().Format(time.RFC3339), string(m.Value))
+		_, span := events.StartConsumerSpan(ctx, m)
+		logger.Print(formatMessage(m))
+		eventType := messageType(m)
+		span.SetAttributes(attribute.String("event.type", eventType))
+		span.End()
+
+		metrics.KafkaConsumed.WithLabelValues(eventType).Inc()
+		health.observe()
+	}
+
+	log.Print("shutting down")
+	healthSrv.Close()
+	closed := make(chan error, 1)
+	go func() {
+		closed <- r.Close()
+	}()
+	timeout := viper.GetDuration("SHUTDOWN_TIMEOUT")
+	select {
+	case err := <-closed:
+		if err != nil {
+			log.Printf("failed to close kafka reader: %v", err)
+		}
+	case <-time.After(timeout):
+		log.Printf("kafka reader did not close within %s", timeout)
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), timeout)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Printf("failed to flush traces: %v", err)
+	}
+
+	logger.Println("Kafka Logger stopped")
+	if err := file.Sync(); err != nil {
+		log.Printf("failed to flush log file: %v", err)
 	}
-}
\ No newline at end of file
+	if err := file.Close(); err != nil {
+		log.Printf("failed to close log file: %v", err)
+	}
+}
+
+// messageType returns the event type of m, or "unknown" if m is not an event
+// envelope.
+func messageType(m kafka.Message) string {
+	event, err := events.DecodeEvent(m.Value)
+	if err != nil || event.Type == "" {
+		return "unknown"
+	}
+	return event.Type
+}
+
+// formatMessage renders an event envelope as a header line followed by the
+// indented task snapshots. Messages that are not envelopes, such as the bare
+// action strings of older API versions, are logged as is.
+func formatMessage(m kafka.Message) string {
+	event, err := events.DecodeEvent(m.Value)
+	if err != nil || event.Type == "" {
+		return fmt.Sprintf("[%s] %s", time.Now().Format(time.RFC3339), string(m.Value))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s v%d", event.OccurredAt.Format(time.RFC3339Nano), event.Type, event.SchemaVersion)
+	if event.TaskID != "" {
+		fmt.Fprintf(&b, " task=%s", event.TaskID)
+	}
+	fmt.Fprintf(&b, " actor=%s event=%s", event.Actor, event.ID)
+	if event.CorrelationID != "" {
+		fmt.Fprintf(&b, " correlation=%s", event.CorrelationID)
+	}
+	fmt.Fprintf(&b, " partition=%d offset=%d", m.Partition, m.Offset)
+	for _, snap := range []struct {
+		name string
+		data json.RawMessage
+	}{{"before", event.Before}, {"after", event.After}} {
+		if len(snap.data) == 0 {
+			continue
+		}
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, snap.data, "    ", "  "); err != nil {
+			pretty.Reset()
+			pretty.Write(snap.data)
+		}
+		fmt.Fprintf(&b, "\n  %s: %s", snap.name, pretty.String())
+	}
+	return b.String()
+}
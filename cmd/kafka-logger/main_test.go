@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	events "github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestFormatMessage(t *testing.T) {
+	t.Run("конверт события", func(t *testing.T) {
+		event := events.NewEvent(events.EventTaskUpdated, "task-1")
+		event.Actor = "alice"
+		event.CorrelationID = "corr-1"
+		event.Before = json.RawMessage(`{"title":"old"}`)
+		event.After = json.RawMessage(`{"title":"new"}`)
+
+		msg, err := event.Message()
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		got := formatMessage(msg)
+
+		for _, want := range []string{"task.updated v1", "task=task-1", "actor=alice", "correlation=corr-1", "event=" + event.ID, "before: {", `"title": "old"`, "after: {", `"title": "new"`} {
+			if !strings.Contains(got, want) {
+				t.Errorf("в строке лога нет %q:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("старое сообщение без конверта", func(t *testing.T) {
+		got := formatMessage(kafka.Message{Value: []byte("create")})
+
+		if !strings.HasSuffix(got, "] create") {
+			t.Errorf("неожиданная строка лога: %s", got)
+		}
+	})
+}
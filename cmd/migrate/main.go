@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	_ "github.com/lib/pq"
+	"github.com/spf13/viper"
+)
+
+const usage = `usage: migrate <command> [args]
+
+commands:
+  up            apply all pending migrations
+  down [N]      roll back the last N applied migrations (default 1)
+  status        show every migration and when it was applied
+  create NAME   add an empty migration pair to CHECKLIST_MIGRATIONS_DIR
+`
+
+func initConfig() {
+	viper.SetEnvPrefix("CHECKLIST")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	viper.SetDefault("MIGRATIONS_DIR", "internal/app/repositories/migrations")
+}
+
+func main() {
+	initConfig()
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+
+	if cmd == "create" {
+		if len(args) != 1 {
+			log.Fatal("usage: migrate create NAME")
+		}
+		up, down, err := repositories.CreateMigration(viper.GetString("MIGRATIONS_DIR"), args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("created %s\ncreated %s", up, down)
+		return
+	}
+
+	dsn := viper.GetString("DB_POSTGRES_DSN")
+	if dsn == "" {
+		log.Fatal("DB_POSTGRES_DSN is not configured")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator, err := repositories.NewMigrator(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	switch cmd {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Printf("applied %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			log.Print("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			steps, err = strconv.Atoi(args[0])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps %q", args[0])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			log.Printf("reverted %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, st := range statuses {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = "applied " + st.AppliedAt.Format(time.RFC3339)
+			}
+			log.Printf("%04d_%-40s %s", st.Version, st.Name, applied)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// apiKeyPrefix marks checklist API keys, which helps secret scanners and
+// people tell them apart from other tokens.
+const apiKeyPrefix = "ck_"
+
+// GenerateAPIKey returns a new random API key. Only its hash is stored, so
+// the key itself is shown to the client once.
+func GenerateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the hash under which key is stored. Keys carry 256
+// random bits, so a plain SHA-256 is enough to make a leaked table useless.
+func HashAPIKey(key string) []byte {
+	h := sha256.Sum256([]byte(key))
+	return h[:]
+}
+
+// ValidAPIKeyFormat reports whether key looks like a key from GenerateAPIKey,
+// to reject garbage without a database lookup.
+func ValidAPIKeyFormat(key string) bool {
+	rest, ok := strings.CutPrefix(key, apiKeyPrefix)
+	if !ok {
+		return false
+	}
+	b, err := base64.RawURLEncoding.DecodeString(rest)
+	return err == nil && len(b) == 32
+}
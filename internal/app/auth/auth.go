@@ -0,0 +1,90 @@
+// Package auth authenticates the clients of the checklist services. The API
+// accepts JWT bearer tokens and API keys and forwards them to the DB
+// service, which verifies them again, so that the DB service never trusts
+// an identity it cannot check itself.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrUnauthenticated is returned for missing, malformed or invalid
+// credentials.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authentication schemes.
+const (
+	SchemeBearer = "Bearer"
+	SchemeAPIKey = "ApiKey"
+)
+
+// Credential is what a client presented to prove its identity.
+type Credential struct {
+	Scheme string
+	Value  string
+}
+
+// String renders the credential as an Authorization header value.
+func (c Credential) String() string {
+	return c.Scheme + " " + c.Value
+}
+
+// ParseAuthorization parses an Authorization header value of the form
+// "Bearer <jwt>" or "ApiKey <key>". The scheme is case-insensitive.
+func ParseAuthorization(header string) (Credential, error) {
+	scheme, value, ok := strings.Cut(strings.TrimSpace(header), " ")
+	value = strings.TrimSpace(value)
+	if !ok || value == "" {
+		return Credential{}, ErrUnauthenticated
+	}
+	switch {
+	case strings.EqualFold(scheme, SchemeBearer):
+		return Credential{Scheme: SchemeBearer, Value: value}, nil
+	case strings.EqualFold(scheme, SchemeAPIKey):
+		return Credential{Scheme: SchemeAPIKey, Value: value}, nil
+	}
+	return Credential{}, ErrUnauthenticated
+}
+
+// Principal is an authenticated client.
+type Principal struct {
+	// Subject identifies the client: the sub claim of a JWT or the owner of
+	// an API key.
+	Subject string
+	// WorkspaceID is the tenant the client acts in. Tasks of other
+	// workspaces are invisible to it.
+	WorkspaceID string
+	// Scheme is how the client authenticated, one of the Scheme constants.
+	Scheme string
+	// Admin is set for administrators of the workspace, who may configure
+	// it. Everyone administers their personal workspace.
+	Admin bool
+}
+
+type principalKey struct{}
+
+type credentialKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, or nil for an
+// unauthenticated context.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// WithCredential stores the credential the principal of ctx authenticated
+// with, to be forwarded to the DB service.
+func WithCredential(ctx context.Context, c Credential) context.Context {
+	return context.WithValue(ctx, credentialKey{}, c)
+}
+
+func CredentialFromContext(ctx context.Context) (Credential, bool) {
+	c, ok := ctx.Value(credentialKey{}).(Credential)
+	return c, ok
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func signHS256(t *testing.T, secret string, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func validClaims(sub string) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{Subject: sub, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+}
+
+func TestParseAuthorization(t *testing.T) {
+	for header, want := range map[string]Credential{
+		"Bearer abc":      {Scheme: SchemeBearer, Value: "abc"},
+		"bearer  abc ":    {Scheme: SchemeBearer, Value: "abc"},
+		"ApiKey ck_12345": {Scheme: SchemeAPIKey, Value: "ck_12345"},
+	} {
+		got, err := ParseAuthorization(header)
+		if err != nil || got != want {
+			t.Errorf("%q: ожидалось %+v, получено %+v (%v)", header, want, got, err)
+		}
+	}
+	for _, header := range []string{"", "Bearer", "Basic dXNlcjpwYXNz", "abc"} {
+		if _, err := ParseAuthorization(header); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("%q: ожидалась ErrUnauthenticated, получено %v", header, err)
+		}
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	t.Run("без алгоритмов проверка выключена", func(t *testing.T) {
+		if v := NewJWTVerifier(JWTConfig{}); v != nil {
+			t.Fatal("ожидался nil")
+		}
+	})
+
+	v := NewJWTVerifier(JWTConfig{HS256Secret: []byte("secret"), Issuer: "checklist"})
+
+	t.Run("HS256", func(t *testing.T) {
+		claims := validClaims("alice")
+		claims.Issuer = "checklist"
+
+		p, err := v.Verify(signHS256(t, "secret", claims))
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if p.Subject != "alice" || p.Scheme != SchemeBearer {
+			t.Errorf("неожиданный principal: %+v", p)
+		}
+	})
+
+	t.Run("администратор рабочего пространства", func(t *testing.T) {
+		for _, tc := range []struct {
+			workspace string
+			admin     bool
+			want      bool
+		}{
+			{workspace: "", want: true},
+			{workspace: "team", want: false},
+			{workspace: "team", admin: true, want: true},
+		} {
+			claims := Claims{RegisteredClaims: validClaims("alice"), WorkspaceID: tc.workspace, WorkspaceAdmin: tc.admin}
+			claims.Issuer = "checklist"
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := v.Verify(token)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if p.Admin != tc.want {
+				t.Errorf("%+v: ожидалось Admin=%t, получено %t", tc, tc.want, p.Admin)
+			}
+		}
+	})
+
+	expired := validClaims("alice")
+	expired.Issuer = "checklist"
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	noSubject := validClaims("")
+	noSubject.Issuer = "checklist"
+	wrongIssuer := validClaims("alice")
+	wrongIssuer.Issuer = "other"
+	noExpiry := jwt.RegisteredClaims{Subject: "alice", Issuer: "checklist"}
+
+	for name, token := range map[string]string{
+		"чужой секрет":       signHS256(t, "other", validClaims("alice")),
+		"истёкший токен":     signHS256(t, "secret", expired),
+		"без subject":        signHS256(t, "secret", noSubject),
+		"другой issuer":      signHS256(t, "secret", wrongIssuer),
+		"без срока действия": signHS256(t, "secret", noExpiry),
+		"не JWT":             "garbage",
+		"алгоритм none":      mustNone(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := v.Verify(token); !errors.Is(err, ErrUnauthenticated) {
+				t.Fatalf("ожидалась ErrUnauthenticated, получено %v", err)
+			}
+		})
+	}
+
+	t.Run("RS256 из PEM файла", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(t.TempDir(), "jwt.pub")
+		if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		rv, err := LoadJWTVerifier("", file, "", "")
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims("bob")).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, err := rv.Verify(token); err != nil || p.Subject != "bob" {
+			t.Fatalf("ожидался bob, получено %+v (%v)", p, err)
+		}
+
+		// Без HS256 секрета токен HS256 не принимается.
+		if _, err := rv.Verify(signHS256(t, "secret", validClaims("bob"))); !errors.Is(err, ErrUnauthenticated) {
+			t.Fatalf("ожидалась ErrUnauthenticated, получено %v", err)
+		}
+	})
+}
+
+func mustNone(t *testing.T) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims("alice")).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAPIKey(t *testing.T) {
+	key, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if !ValidAPIKeyFormat(key) {
+		t.Errorf("сгенерированный ключ должен проходить проверку формата: %s", key)
+	}
+	for _, bad := range []string{"", "ck_", "xx_" + key[3:], key + "x"} {
+		if ValidAPIKeyFormat(bad) {
+			t.Errorf("ключ %q не должен проходить проверку формата", bad)
+		}
+	}
+
+	other, _ := GenerateAPIKey()
+	if string(HashAPIKey(key)) == string(HashAPIKey(other)) {
+		t.Error("у разных ключей должны быть разные хэши")
+	}
+}
+
+type authenticatorFunc func(ctx context.Context, c Credential) (*Principal, error)
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, c Credential) (*Principal, error) {
+	return f(ctx, c)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(authenticatorFunc(func(ctx context.Context, c Credential) (*Principal, error) {
+		if c.Value != "good" {
+			return nil, ErrUnauthenticated
+		}
+		return &Principal{Subject: "alice", Scheme: c.Scheme}, nil
+	}), HealthCheckMethod)
+
+	call := func(method string, md metadata.MD) (*Principal, error) {
+		var got *Principal
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			got = FromContext(ctx)
+			return nil, nil
+		})
+		return got, err
+	}
+
+	t.Run("верные данные", func(t *testing.T) {
+		p, err := call("/checklist.TaskService/Get", metadata.Pairs(AuthorizationKey, "Bearer good", PrincipalKey, "alice"))
+		if err != nil || p == nil || p.Subject != "alice" {
+			t.Fatalf("ожидался alice, получено %+v (%v)", p, err)
+		}
+	})
+
+	for name, md := range map[string]metadata.MD{
+		"без данных":      nil,
+		"неверный формат": metadata.Pairs(AuthorizationKey, "Basic abc"),
+		"неверный токен":  metadata.Pairs(AuthorizationKey, "Bearer bad"),
+		"чужой principal": metadata.Pairs(AuthorizationKey, "Bearer good", PrincipalKey, "mallory"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := call("/checklist.TaskService/Get", md); status.Code(err) != codes.Unauthenticated {
+				t.Fatalf("ожидался Unauthenticated, получено %v", err)
+			}
+		})
+	}
+
+	t.Run("health check без данных", func(t *testing.T) {
+		if _, err := call("/grpc.health.v1.Health/Check", nil); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	})
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	ctx := WithCredential(NewContext(context.Background(), &Principal{Subject: "alice"}), Credential{Scheme: SchemeAPIKey, Value: "ck_x"})
+
+	var md metadata.MD
+	err := UnaryClientInterceptor()(ctx, "/checklist.TaskService/Get", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got := md.Get(AuthorizationKey); len(got) != 1 || got[0] != "ApiKey ck_x" {
+		t.Errorf("неожиданный authorization: %v", got)
+	}
+	if got := md.Get(PrincipalKey); len(got) != 1 || got[0] != "alice" {
+		t.Errorf("неожиданный principal: %v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC metadata keys.
+const (
+	AuthorizationKey = "authorization"
+	PrincipalKey     = "x-principal"
+)
+
+// Authenticator checks a credential and returns who it belongs to. Errors
+// for bad credentials wrap ErrUnauthenticated.
+type Authenticator interface {
+	Authenticate(ctx context.Context, c Credential) (*Principal, error)
+}
+
+// UnaryClientInterceptor forwards the credential and principal stored in
+// the context of each call as outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if c, ok := CredentialFromContext(ctx); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, AuthorizationKey, c.String())
+		}
+		if p := FromContext(ctx); p != nil {
+			ctx = metadata.AppendToOutgoingContext(ctx, PrincipalKey, p.Subject)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryServerInterceptor authenticates the credential of every call and
+// stores the principal in the call context. If the caller also names a
+// principal, it must be the one the credential belongs to. Calls of the
+// methods for which public returns true are let through as they are.
+func UnaryServerInterceptor(a Authenticator, public func(fullMethod string) bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if public != nil && public(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(AuthorizationKey)
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "missing credentials")
+		}
+		cred, err := ParseAuthorization(values[0])
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "malformed credentials")
+		}
+
+		p, err := a.Authenticate(ctx, cred)
+		if errors.Is(err, ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if err != nil {
+			return nil, err
+		}
+		if claimed := md.Get(PrincipalKey); len(claimed) > 0 && claimed[0] != p.Subject {
+			return nil, status.Error(codes.Unauthenticated, "principal does not match credentials")
+		}
+
+		ctx = WithCredential(NewContext(ctx, p), cred)
+		return handler(ctx, req)
+	}
+}
+
+// HealthCheckMethod reports whether fullMethod belongs to the standard gRPC
+// health service, which probes call without credentials.
+func HealthCheckMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/")
+}
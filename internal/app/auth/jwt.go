@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type JWTConfig struct {
+	// HS256Secret enables HS256 tokens signed with this shared secret.
+	HS256Secret []byte
+	// RS256PublicKey enables RS256 tokens signed with the matching private
+	// key.
+	RS256PublicKey *rsa.PublicKey
+	// Issuer and Audience, if set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+}
+
+// JWTVerifier checks bearer tokens. Tokens must be signed with one of the
+// configured algorithms, must not be expired and must have a subject.
+type JWTVerifier struct {
+	cfg    JWTConfig
+	parser *jwt.Parser
+}
+
+// NewJWTVerifier returns nil if cfg enables no algorithm, in which case
+// bearer tokens are not accepted at all.
+func NewJWTVerifier(cfg JWTConfig) *JWTVerifier {
+	var methods []string
+	if len(cfg.HS256Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.RS256PublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	return &JWTVerifier{cfg: cfg, parser: jwt.NewParser(opts...)}
+}
+
+// LoadJWTVerifier builds a verifier from the service configuration: an
+// HS256 secret and the path of a PEM encoded RS256 public key, either of
+// which may be empty.
+func LoadJWTVerifier(hs256Secret, rs256PublicKeyFile, issuer, audience string) (*JWTVerifier, error) {
+	cfg := JWTConfig{HS256Secret: []byte(hs256Secret), Issuer: issuer, Audience: audience}
+	if rs256PublicKeyFile != "" {
+		data, err := os.ReadFile(rs256PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.RS256PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(data); err != nil {
+			return nil, fmt.Errorf("parse RS256 public key: %w", err)
+		}
+	}
+	return NewJWTVerifier(cfg), nil
+}
+
+// Claims are the JWT claims the services read.
+type Claims struct {
+	jwt.RegisteredClaims
+	// WorkspaceID selects the workspace of the token. Tokens without it act
+	// in the personal workspace of their subject.
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	// WorkspaceAdmin makes the subject an administrator of the workspace.
+	WorkspaceAdmin bool `json:"workspace_admin,omitempty"`
+}
+
+// Verify returns the principal named by the sub claim of a valid token.
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	claims := &Claims{}
+	_, err := v.parser.ParseWithClaims(token, claims, v.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrUnauthenticated)
+	}
+	p := &Principal{Subject: claims.Subject, WorkspaceID: claims.WorkspaceID, Scheme: SchemeBearer}
+	if p.WorkspaceID == "" {
+		p.WorkspaceID = PersonalWorkspace(p.Subject)
+	}
+	p.Admin = claims.WorkspaceAdmin || p.WorkspaceID == PersonalWorkspace(p.Subject)
+	return p, nil
+}
+
+// PersonalWorkspace is the workspace of a subject that names none.
+func PersonalWorkspace(subject string) string {
+	return "user:" + subject
+}
+
+func (v *JWTVerifier) key(t *jwt.Token) (any, error) {
+	switch t.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.cfg.HS256Secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		return v.cfg.RS256PublicKey, nil
+	}
+	return nil, errors.New("unexpected signing method")
+}
@@ -0,0 +1,25 @@
+package models
+
+// BatchMode decides what a batch of changes does when one of its items
+// fails.
+type BatchMode string
+
+const (
+	// BatchAllOrNothing rolls back the whole batch on the first failure.
+	BatchAllOrNothing BatchMode = "all_or_nothing"
+	// BatchBestEffort rolls back only the items that failed.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// Valid reports whether m is a known mode.
+func (m BatchMode) Valid() bool {
+	return m == BatchAllOrNothing || m == BatchBestEffort
+}
+
+// BatchResult is the outcome of one item of a batch.
+type BatchResult struct {
+	// Task is the task after the change. It is nil if the item failed or
+	// deleted the task.
+	Task *Task
+	Err  error
+}
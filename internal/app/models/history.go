@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskEvent is an entry of the history of a task: a change of the task, who
+// made it and when. Entries are never changed once written.
+type TaskEvent struct {
+	ID          int64     `json:"id"`
+	TaskID      uuid.UUID `json:"task_id"`
+	WorkspaceID string    `json:"workspace_id"`
+	// Type is the type of the Kafka event sent about the change, such as
+	// task.created or task.updated.
+	Type       string        `json:"type"`
+	Actor      string        `json:"actor"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Changes    []FieldChange `json:"changes"`
+}
+
+// FieldChange is the change of one field of a task, named like in its JSON
+// form. Before and After hold the JSON values, and are empty for a field
+// that was or became unset.
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// HistoryQuery selects entries of the task history of the workspace of the
+// principal, newest first. Every set field narrows the selection.
+type HistoryQuery struct {
+	PageSize  int
+	PageToken string
+	TaskID    *uuid.UUID
+	// Visible, with TaskID, reads the history of the task only while it is
+	// visible to the principal, and fails with not found otherwise.
+	Visible bool
+	Actor   string
+	// Types selects the entries of any of these event types.
+	Types []string
+	// Inclusive lower and exclusive upper bounds on OccurredAt.
+	Since *time.Time
+	Until *time.Time
+}
+
+type TaskEventPage struct {
+	Events        []TaskEvent `json:"events"`
+	NextPageToken string      `json:"next_page_token,omitempty"`
+}
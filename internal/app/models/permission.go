@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is what a user may do with a task. Each role includes the ones
+// below it: owners may do everything editors may, editors everything
+// viewers may.
+type Role string
+
+const (
+	// RoleViewer may read the task.
+	RoleViewer Role = "viewer"
+	// RoleEditor may also update it and mark it done.
+	RoleEditor Role = "editor"
+	// RoleOwner may also delete and share it. Only the creator of a task
+	// is its owner.
+	RoleOwner Role = "owner"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+// Includes reports whether r grants everything other does.
+func (r Role) Includes(other Role) bool {
+	return roleRank[r] > 0 && roleRank[r] >= roleRank[other]
+}
+
+// Grantable reports whether a task can be shared with this role.
+func (r Role) Grantable() bool {
+	return r == RoleViewer || r == RoleEditor
+}
+
+// Collaborator is a user a task is shared with.
+type Collaborator struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	UserID    string    `json:"user_id"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,84 @@
+package models
+
+// Status is where a task is in its workflow. Which statuses a task may move
+// between is decided by the Workflow of its workspace.
+type Status string
+
+const (
+	StatusTodo       Status = "todo"
+	StatusInProgress Status = "in_progress"
+	StatusBlocked    Status = "blocked"
+	StatusDone       Status = "done"
+	StatusCancelled  Status = "cancelled"
+)
+
+// Statuses lists every status, in workflow order.
+var Statuses = []Status{StatusTodo, StatusInProgress, StatusBlocked, StatusDone, StatusCancelled}
+
+// Valid reports whether s is one of the Statuses.
+func (s Status) Valid() bool {
+	for _, v := range Statuses {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Closed reports whether a task in status s needs no more work. Closed
+// tasks are never due.
+func (s Status) Closed() bool {
+	return s == StatusDone || s == StatusCancelled
+}
+
+// Transition is a move of a task from one status to another.
+type Transition struct {
+	From Status `json:"from"`
+	To   Status `json:"to"`
+}
+
+// Workflow is the transition table of a workspace: a task may only move
+// along its Transitions. Staying in the same status is always allowed.
+type Workflow struct {
+	WorkspaceID string       `json:"workspace_id"`
+	Transitions []Transition `json:"transitions"`
+}
+
+// DefaultTransitions is the workflow of workspaces that configured none.
+// Open tasks may move anywhere, done tasks may be reopened and cancelled
+// ones restarted.
+var DefaultTransitions = []Transition{
+	{StatusTodo, StatusInProgress},
+	{StatusTodo, StatusBlocked},
+	{StatusTodo, StatusDone},
+	{StatusTodo, StatusCancelled},
+	{StatusInProgress, StatusTodo},
+	{StatusInProgress, StatusBlocked},
+	{StatusInProgress, StatusDone},
+	{StatusInProgress, StatusCancelled},
+	{StatusBlocked, StatusTodo},
+	{StatusBlocked, StatusInProgress},
+	{StatusBlocked, StatusDone},
+	{StatusBlocked, StatusCancelled},
+	{StatusDone, StatusTodo},
+	{StatusDone, StatusInProgress},
+	{StatusCancelled, StatusTodo},
+}
+
+// DefaultWorkflow returns the workflow of a workspace without one of its own.
+func DefaultWorkflow(workspaceID string) *Workflow {
+	return &Workflow{WorkspaceID: workspaceID, Transitions: append([]Transition{}, DefaultTransitions...)}
+}
+
+// Allows reports whether a task may move from one status to another.
+func (w *Workflow) Allows(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+	return false
+}
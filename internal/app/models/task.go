@@ -6,10 +6,125 @@ import (
 	"github.com/google/uuid"
 )
 
+// Task belongs to the user who created it, in the workspace they acted in.
 type Task struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	OwnerID     string    `json:"owner_id"`
+	WorkspaceID string    `json:"workspace_id"`
+	// Status is where the task is in the workflow of its workspace. Done is
+	// whether it is StatusDone, kept for clients that predate statuses.
+	Status Status `json:"status"`
+	Done   bool   `json:"done"`
+	// StatusChangedAt and StatusChangedBy record the last transition, and
+	// are unset for tasks that never moved.
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	StatusChangedBy string     `json:"status_changed_by,omitempty"`
+	// AutoComplete marks the task done once all its items are done.
+	AutoComplete bool `json:"auto_complete"`
+	// DueAt is when the task should be done, RemindAt when its owner wants
+	// to be reminded of it. Both are optional.
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	RemindAt *time.Time `json:"remind_at,omitempty"`
+	// Recurrence is an RRULE, see package rrule. Marking a recurring task
+	// with a due time done creates its next occurrence. TimeZone is the
+	// IANA time zone the rule is expanded in, UTC if empty.
+	Recurrence string `json:"recurrence,omitempty"`
+	TimeZone   string `json:"time_zone,omitempty"`
+	// DeletedAt is set while the task is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Version starts at 1 and is incremented by every change of the task,
+	// its items included.
+	Version int64 `json:"version"`
+	// Items are ordered by position.
+	Items []TaskItem `json:"items"`
+}
+
+// TaskItem is a checklist item of a task. Positions of the items of a task
+// run from 0 without gaps.
+type TaskItem struct {
 	ID        uuid.UUID `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
+	TaskID    uuid.UUID `json:"task_id"`
+	Position  int       `json:"position"`
+	Text      string    `json:"text"`
 	Done      bool      `json:"done"`
 	CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+}
+
+// TaskPatch describes a partial update of a task. Nil fields are left
+// unchanged, a zero DueAt or RemindAt clears the time and an empty
+// Recurrence stops the task from recurring.
+type TaskPatch struct {
+	Title        *string
+	Content      *string
+	AutoComplete *bool
+	DueAt        *time.Time
+	RemindAt     *time.Time
+	Recurrence   *string
+	TimeZone     *string
+}
+
+// TaskItemPatch describes a partial update of an item. Nil fields are left
+// unchanged.
+type TaskItemPatch struct {
+	Text *string
+	Done *bool
+}
+
+// AllItemsDone reports whether the task has items and all of them are done.
+func (t *Task) AllItemsDone() bool {
+	for _, it := range t.Items {
+		if !it.Done {
+			return false
+		}
+	}
+	return len(t.Items) > 0
+}
+
+// TaskListQuery selects one page of tasks. PageToken is the NextPageToken of
+// the previous page, or empty for the first one.
+type TaskListQuery struct {
+	PageSize      int
+	PageToken     string
+	Done          *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Descending    bool
+	// Overdue selects open tasks past their due time, DueWithin open tasks
+	// due within that long from now. Only one of them may be set.
+	Overdue   bool
+	DueWithin time.Duration
+	// Statuses selects the tasks in any of them, all if empty. Open tasks
+	// are the ones whose status is not Closed.
+	Statuses []Status
+	// Trashed selects the deleted tasks the principal owns instead.
+	Trashed bool
+}
+
+type TaskPage struct {
+	Tasks         []Task `json:"tasks"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// SearchResult is a task matched by a full-text query. Snippets contain the
+// matched words wrapped in <b></b>.
+type SearchResult struct {
+	Task           Task    `json:"task"`
+	Rank           float32 `json:"rank"`
+	TitleSnippet   string  `json:"title_snippet"`
+	ContentSnippet string  `json:"content_snippet"`
+}
+
+// APIKey describes an API key. The key itself is never stored, only its
+// hash. Requests made with it act as Owner in WorkspaceID.
+type APIKey struct {
+	ID          uuid.UUID  `json:"id"`
+	Owner       string     `json:"owner"`
+	WorkspaceID string     `json:"workspace_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
@@ -9,7 +9,9 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -23,32 +25,1806 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type SortOrder int32
+
+const (
+	// Same as SORT_ORDER_CREATED_ASC.
+	SortOrder_SORT_ORDER_UNSPECIFIED  SortOrder = 0
+	SortOrder_SORT_ORDER_CREATED_ASC  SortOrder = 1
+	SortOrder_SORT_ORDER_CREATED_DESC SortOrder = 2
+)
+
+// Enum value maps for SortOrder.
+var (
+	SortOrder_name = map[int32]string{
+		0: "SORT_ORDER_UNSPECIFIED",
+		1: "SORT_ORDER_CREATED_ASC",
+		2: "SORT_ORDER_CREATED_DESC",
+	}
+	SortOrder_value = map[string]int32{
+		"SORT_ORDER_UNSPECIFIED":  0,
+		"SORT_ORDER_CREATED_ASC":  1,
+		"SORT_ORDER_CREATED_DESC": 2,
+	}
+)
+
+func (x SortOrder) Enum() *SortOrder {
+	p := new(SortOrder)
+	*p = x
+	return p
+}
+
+func (x SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_app_pb_task_proto_enumTypes[0].Descriptor()
+}
+
+func (SortOrder) Type() protoreflect.EnumType {
+	return &file_internal_app_pb_task_proto_enumTypes[0]
+}
+
+func (x SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortOrder.Descriptor instead.
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{0}
+}
+
+// What a batch does when one of its items fails.
+type BatchMode int32
+
+const (
+	// Same as BATCH_MODE_ALL_OR_NOTHING.
+	BatchMode_BATCH_MODE_UNSPECIFIED BatchMode = 0
+	// The first failed item rolls back the whole batch.
+	BatchMode_BATCH_MODE_ALL_OR_NOTHING BatchMode = 1
+	// Only the failed items are rolled back.
+	BatchMode_BATCH_MODE_BEST_EFFORT BatchMode = 2
+)
+
+// Enum value maps for BatchMode.
+var (
+	BatchMode_name = map[int32]string{
+		0: "BATCH_MODE_UNSPECIFIED",
+		1: "BATCH_MODE_ALL_OR_NOTHING",
+		2: "BATCH_MODE_BEST_EFFORT",
+	}
+	BatchMode_value = map[string]int32{
+		"BATCH_MODE_UNSPECIFIED":    0,
+		"BATCH_MODE_ALL_OR_NOTHING": 1,
+		"BATCH_MODE_BEST_EFFORT":    2,
+	}
+)
+
+func (x BatchMode) Enum() *BatchMode {
+	p := new(BatchMode)
+	*p = x
+	return p
+}
+
+func (x BatchMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BatchMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_app_pb_task_proto_enumTypes[1].Descriptor()
+}
+
+func (BatchMode) Type() protoreflect.EnumType {
+	return &file_internal_app_pb_task_proto_enumTypes[1]
+}
+
+func (x BatchMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BatchMode.Descriptor instead.
+func (BatchMode) EnumDescriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{1}
+}
+
 type Task struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title   string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Content string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	// Whether status is "done".
+	Done        bool                   `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	OwnerId     string                 `protobuf:"bytes,6,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	WorkspaceId string                 `protobuf:"bytes,7,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	// Whether the task is marked done once all its items are done.
+	AutoComplete bool `protobuf:"varint,8,opt,name=auto_complete,json=autoComplete,proto3" json:"auto_complete,omitempty"`
+	// Checklist items, ordered by position.
+	Items []*TaskItem `protobuf:"bytes,9,rep,name=items,proto3" json:"items,omitempty"`
+	// When the task should be done and when to remind of it, both optional.
+	DueAt    *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	RemindAt *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=remind_at,json=remindAt,proto3" json:"remind_at,omitempty"`
+	// RRULE (RFC 5545 subset) and the IANA time zone it is expanded in, UTC
+	// if empty. Marking a recurring task done creates its next occurrence.
+	Recurrence string `protobuf:"bytes,12,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	TimeZone   string `protobuf:"bytes,13,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	// One of "todo", "in_progress", "blocked", "done" and "cancelled".
+	Status string `protobuf:"bytes,14,opt,name=status,proto3" json:"status,omitempty"`
+	// When and by whom the status was last changed, unset if never.
+	StatusChangedAt *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=status_changed_at,json=statusChangedAt,proto3" json:"status_changed_at,omitempty"`
+	StatusChangedBy string                 `protobuf:"bytes,16,opt,name=status_changed_by,json=statusChangedBy,proto3" json:"status_changed_by,omitempty"`
+	// When the task was moved to the trash, unset if it was not.
+	DeletedAt *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// Starts at 1 and is incremented by every change of the task. Clients send
+	// it back in the x-expected-version metadata to change the task only if it
+	// is still at that version.
+	Version       int64 `protobuf:"varint,18,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Task) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Task) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Task) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Task) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *Task) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *Task) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *Task) GetAutoComplete() bool {
+	if x != nil {
+		return x.AutoComplete
+	}
+	return false
+}
+
+func (x *Task) GetItems() []*TaskItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *Task) GetDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *Task) GetRemindAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RemindAt
+	}
+	return nil
+}
+
+func (x *Task) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *Task) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *Task) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Task) GetStatusChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StatusChangedAt
+	}
+	return nil
+}
+
+func (x *Task) GetStatusChangedBy() string {
+	if x != nil {
+		return x.StatusChangedBy
+	}
+	return ""
+}
+
+func (x *Task) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *Task) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type TaskItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Position      int32                  `protobuf:"varint,2,opt,name=position,proto3" json:"position,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Done          bool                   `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskItem) Reset() {
+	*x = TaskItem{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskItem) ProtoMessage() {}
+
+func (x *TaskItem) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskItem.ProtoReflect.Descriptor instead.
+func (*TaskItem) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TaskItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TaskItem) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *TaskItem) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TaskItem) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *TaskItem) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CreateTaskRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Title        string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Content      string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	AutoComplete bool                   `protobuf:"varint,3,opt,name=auto_complete,json=autoComplete,proto3" json:"auto_complete,omitempty"`
+	DueAt        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	RemindAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=remind_at,json=remindAt,proto3" json:"remind_at,omitempty"`
+	// A recurring task needs due_at.
+	Recurrence    string `protobuf:"bytes,6,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	TimeZone      string `protobuf:"bytes,7,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaskRequest) Reset() {
+	*x = CreateTaskRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaskRequest) ProtoMessage() {}
+
+func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
+func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateTaskRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetAutoComplete() bool {
+	if x != nil {
+		return x.AutoComplete
+	}
+	return false
+}
+
+func (x *CreateTaskRequest) GetDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *CreateTaskRequest) GetRemindAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RemindAt
+	}
+	return nil
+}
+
+func (x *CreateTaskRequest) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+type UpdateTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Task  *Task                  `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	// Only the listed fields of task are applied. Supported paths: "title",
+	// "content", "auto_complete", "due_at", "remind_at", "recurrence",
+	// "time_zone". A listed time that is not set clears it.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTaskRequest) Reset() {
+	*x = UpdateTaskRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskRequest) ProtoMessage() {}
+
+func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdateTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateTaskRequest) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *UpdateTaskRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type TaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskResponse) Reset() {
+	*x = TaskResponse{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskResponse) ProtoMessage() {}
+
+func (x *TaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskResponse.ProtoReflect.Descriptor instead.
+func (*TaskResponse) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TaskResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+type TaskIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskIDRequest) Reset() {
+	*x = TaskIDRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskIDRequest) ProtoMessage() {}
+
+func (x *TaskIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskIDRequest.ProtoReflect.Descriptor instead.
+func (*TaskIDRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TaskIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type StatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of tasks to return. Zero means the server default.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Opaque token from a previous TaskListResponse.next_page_token. The other
+	// fields must be the same as in the request that produced the token.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	Done      *bool  `protobuf:"varint,3,opt,name=done,proto3,oneof" json:"done,omitempty"`
+	// Inclusive lower and exclusive upper bounds on created_at.
+	CreatedAfter  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	Sort          SortOrder              `protobuf:"varint,6,opt,name=sort,proto3,enum=checklist.SortOrder" json:"sort,omitempty"`
+	// Only open tasks past their due time.
+	Overdue bool `protobuf:"varint,7,opt,name=overdue,proto3" json:"overdue,omitempty"`
+	// Only open tasks due within this long from now. Cannot be combined with
+	// overdue.
+	DueWithin *durationpb.Duration `protobuf:"bytes,8,opt,name=due_within,json=dueWithin,proto3" json:"due_within,omitempty"`
+	// Only tasks in one of these statuses.
+	Statuses      []string `protobuf:"bytes,9,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTasksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetDone() bool {
+	if x != nil && x.Done != nil {
+		return *x.Done
+	}
+	return false
+}
+
+func (x *ListTasksRequest) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetSort() SortOrder {
+	if x != nil {
+		return x.Sort
+	}
+	return SortOrder_SORT_ORDER_UNSPECIFIED
+}
+
+func (x *ListTasksRequest) GetOverdue() bool {
+	if x != nil {
+		return x.Overdue
+	}
+	return false
+}
+
+func (x *ListTasksRequest) GetDueWithin() *durationpb.Duration {
+	if x != nil {
+		return x.DueWithin
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+type ListTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashRequest) Reset() {
+	*x = ListTrashRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashRequest) ProtoMessage() {}
+
+func (x *ListTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashRequest.ProtoReflect.Descriptor instead.
+func (*ListTrashRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListTrashRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTrashRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type TransitionTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The status to move the task to.
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransitionTaskRequest) Reset() {
+	*x = TransitionTaskRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransitionTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransitionTaskRequest) ProtoMessage() {}
+
+func (x *TransitionTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransitionTaskRequest.ProtoReflect.Descriptor instead.
+func (*TransitionTaskRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TransitionTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransitionTaskRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type WorkflowTransition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkflowTransition) Reset() {
+	*x = WorkflowTransition{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkflowTransition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkflowTransition) ProtoMessage() {}
+
+func (x *WorkflowTransition) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkflowTransition.ProtoReflect.Descriptor instead.
+func (*WorkflowTransition) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WorkflowTransition) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *WorkflowTransition) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type Workflow struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	WorkspaceId string                 `protobuf:"bytes,1,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	// The moves allowed between statuses. A task may always stay where it is.
+	Transitions   []*WorkflowTransition `protobuf:"bytes,2,rep,name=transitions,proto3" json:"transitions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Workflow) Reset() {
+	*x = Workflow{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Workflow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Workflow) ProtoMessage() {}
+
+func (x *Workflow) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Workflow.ProtoReflect.Descriptor instead.
+func (*Workflow) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Workflow) GetWorkspaceId() string {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return ""
+}
+
+func (x *Workflow) GetTransitions() []*WorkflowTransition {
+	if x != nil {
+		return x.Transitions
+	}
+	return nil
+}
+
+type SetWorkflowRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// No transitions restore the default workflow.
+	Transitions   []*WorkflowTransition `protobuf:"bytes,1,rep,name=transitions,proto3" json:"transitions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetWorkflowRequest) Reset() {
+	*x = SetWorkflowRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetWorkflowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetWorkflowRequest) ProtoMessage() {}
+
+func (x *SetWorkflowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetWorkflowRequest.ProtoReflect.Descriptor instead.
+func (*SetWorkflowRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SetWorkflowRequest) GetTransitions() []*WorkflowTransition {
+	if x != nil {
+		return x.Transitions
+	}
+	return nil
+}
+
+type FieldChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the field in the JSON form of a task.
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// JSON values of the field, empty if it was or became unset.
+	Before        string `protobuf:"bytes,2,opt,name=before,proto3" json:"before,omitempty"`
+	After         string `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldChange) ProtoMessage() {}
+
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FieldChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldChange) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *FieldChange) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+type TaskEvent struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TaskId string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// The type of the Kafka event sent about the change, like "task.updated".
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Actor         string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Changes       []*FieldChange         `protobuf:"bytes,6,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskEvent) Reset() {
+	*x = TaskEvent{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskEvent) ProtoMessage() {}
+
+func (x *TaskEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskEvent.ProtoReflect.Descriptor instead.
+func (*TaskEvent) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TaskEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TaskEvent) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *TaskEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *TaskEvent) GetChanges() []*FieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type GetHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetHistoryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetHistoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListAuditRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PageSize  int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Optional filters, each narrowing the selection.
+	TaskId string   `protobuf:"bytes,3,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Actor  string   `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	Types  []string `protobuf:"bytes,5,rep,name=types,proto3" json:"types,omitempty"`
+	// Inclusive lower and exclusive upper bounds on occurred_at.
+	Since         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=since,proto3" json:"since,omitempty"`
+	Until         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditRequest) Reset() {
+	*x = ListAuditRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditRequest) ProtoMessage() {}
+
+func (x *ListAuditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListAuditRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAuditRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListAuditRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ListAuditRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ListAuditRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *ListAuditRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListAuditRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+type TaskEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first.
+	Events []*TaskEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	// Empty when there are no more pages.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskEventsResponse) Reset() {
+	*x = TaskEventsResponse{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskEventsResponse) ProtoMessage() {}
+
+func (x *TaskEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskEventsResponse.ProtoReflect.Descriptor instead.
+func (*TaskEventsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *TaskEventsResponse) GetEvents() []*TaskEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *TaskEventsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type TaskListResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tasks []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	// Empty when there are no more pages.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskListResponse) Reset() {
+	*x = TaskListResponse{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskListResponse) ProtoMessage() {}
+
+func (x *TaskListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskListResponse.ProtoReflect.Descriptor instead.
+func (*TaskListResponse) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TaskListResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *TaskListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type SearchTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Search terms in web search syntax: words, "quoted phrases", -excluded.
+	Query         string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	PageSize      int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchTasksRequest) Reset() {
+	*x = SearchTasksRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTasksRequest) ProtoMessage() {}
+
+func (x *SearchTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTasksRequest.ProtoReflect.Descriptor instead.
+func (*SearchTasksRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SearchTasksRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchTasksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type SearchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Task  *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Rank  float32                `protobuf:"fixed32,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	// Title and content fragments with matches wrapped in <b></b>.
+	TitleSnippet   string `protobuf:"bytes,3,opt,name=title_snippet,json=titleSnippet,proto3" json:"title_snippet,omitempty"`
+	ContentSnippet string `protobuf:"bytes,4,opt,name=content_snippet,json=contentSnippet,proto3" json:"content_snippet,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SearchResult) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *SearchResult) GetRank() float32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchResult) GetTitleSnippet() string {
+	if x != nil {
+		return x.TitleSnippet
+	}
+	return ""
+}
+
+func (x *SearchResult) GetContentSnippet() string {
+	if x != nil {
+		return x.ContentSnippet
+	}
+	return ""
+}
+
+type SearchTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchTasksResponse) Reset() {
+	*x = SearchTasksResponse{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTasksResponse) ProtoMessage() {}
+
+func (x *SearchTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTasksResponse.ProtoReflect.Descriptor instead.
+func (*SearchTasksResponse) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SearchTasksResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ShareTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The user to share the task with, in the workspace of the task.
+	UserId string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// "viewer" or "editor".
+	Role          string `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareTaskRequest) Reset() {
+	*x = ShareTaskRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareTaskRequest) ProtoMessage() {}
+
+func (x *ShareTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareTaskRequest.ProtoReflect.Descriptor instead.
+func (*ShareTaskRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ShareTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ShareTaskRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ShareTaskRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type UnshareTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnshareTaskRequest) Reset() {
+	*x = UnshareTaskRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnshareTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnshareTaskRequest) ProtoMessage() {}
+
+func (x *UnshareTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnshareTaskRequest.ProtoReflect.Descriptor instead.
+func (*UnshareTaskRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UnshareTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UnshareTaskRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type Collaborator struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Collaborator) Reset() {
+	*x = Collaborator{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Collaborator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Collaborator) ProtoMessage() {}
+
+func (x *Collaborator) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Collaborator.ProtoReflect.Descriptor instead.
+func (*Collaborator) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Collaborator) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Collaborator) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Collaborator) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type CollaboratorsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
-	Done          bool                   `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Collaborators []*Collaborator        `protobuf:"bytes,1,rep,name=collaborators,proto3" json:"collaborators,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Task) Reset() {
-	*x = Task{}
-	mi := &file_internal_app_pb_task_proto_msgTypes[0]
+func (x *CollaboratorsResponse) Reset() {
+	*x = CollaboratorsResponse{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Task) String() string {
+func (x *CollaboratorsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Task) ProtoMessage() {}
+func (*CollaboratorsResponse) ProtoMessage() {}
 
-func (x *Task) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_app_pb_task_proto_msgTypes[0]
+func (x *CollaboratorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -59,69 +1835,215 @@ func (x *Task) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Task.ProtoReflect.Descriptor instead.
-func (*Task) Descriptor() ([]byte, []int) {
-	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use CollaboratorsResponse.ProtoReflect.Descriptor instead.
+func (*CollaboratorsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *Task) GetId() string {
+func (x *CollaboratorsResponse) GetCollaborators() []*Collaborator {
 	if x != nil {
-		return x.Id
+		return x.Collaborators
+	}
+	return nil
+}
+
+type AddItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddItemRequest) Reset() {
+	*x = AddItemRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddItemRequest) ProtoMessage() {}
+
+func (x *AddItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddItemRequest.ProtoReflect.Descriptor instead.
+func (*AddItemRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *AddItemRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
 	}
 	return ""
 }
 
-func (x *Task) GetTitle() string {
+func (x *AddItemRequest) GetText() string {
 	if x != nil {
-		return x.Title
+		return x.Text
 	}
 	return ""
 }
 
-func (x *Task) GetContent() string {
+type UpdateItemRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	TaskId string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	ItemId string                 `protobuf:"bytes,2,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Item   *TaskItem              `protobuf:"bytes,3,opt,name=item,proto3" json:"item,omitempty"`
+	// Only the listed fields of item are applied. Supported paths: "text", "done".
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,4,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateItemRequest) Reset() {
+	*x = UpdateItemRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateItemRequest) ProtoMessage() {}
+
+func (x *UpdateItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[27]
 	if x != nil {
-		return x.Content
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateItemRequest.ProtoReflect.Descriptor instead.
+func (*UpdateItemRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UpdateItemRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
 	}
 	return ""
 }
 
-func (x *Task) GetDone() bool {
+func (x *UpdateItemRequest) GetItemId() string {
 	if x != nil {
-		return x.Done
+		return x.ItemId
 	}
-	return false
+	return ""
 }
 
-func (x *Task) GetCreatedAt() *timestamppb.Timestamp {
+func (x *UpdateItemRequest) GetItem() *TaskItem {
 	if x != nil {
-		return x.CreatedAt
+		return x.Item
 	}
 	return nil
 }
 
-type CreateTaskRequest struct {
+func (x *UpdateItemRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type ReorderItemsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	TaskId string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// Every item of the task, in the new order.
+	ItemIds       []string `protobuf:"bytes,2,rep,name=item_ids,json=itemIds,proto3" json:"item_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReorderItemsRequest) Reset() {
+	*x = ReorderItemsRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReorderItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReorderItemsRequest) ProtoMessage() {}
+
+func (x *ReorderItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReorderItemsRequest.ProtoReflect.Descriptor instead.
+func (*ReorderItemsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReorderItemsRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ReorderItemsRequest) GetItemIds() []string {
+	if x != nil {
+		return x.ItemIds
+	}
+	return nil
+}
+
+type ItemIDRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
-	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	ItemId        string                 `protobuf:"bytes,2,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateTaskRequest) Reset() {
-	*x = CreateTaskRequest{}
-	mi := &file_internal_app_pb_task_proto_msgTypes[1]
+func (x *ItemIDRequest) Reset() {
+	*x = ItemIDRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateTaskRequest) String() string {
+func (x *ItemIDRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateTaskRequest) ProtoMessage() {}
+func (*ItemIDRequest) ProtoMessage() {}
 
-func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_app_pb_task_proto_msgTypes[1]
+func (x *ItemIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -132,47 +2054,48 @@ func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
-func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
-	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ItemIDRequest.ProtoReflect.Descriptor instead.
+func (*ItemIDRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *CreateTaskRequest) GetTitle() string {
+func (x *ItemIDRequest) GetTaskId() string {
 	if x != nil {
-		return x.Title
+		return x.TaskId
 	}
 	return ""
 }
 
-func (x *CreateTaskRequest) GetContent() string {
+func (x *ItemIDRequest) GetItemId() string {
 	if x != nil {
-		return x.Content
+		return x.ItemId
 	}
 	return ""
 }
 
-type TaskResponse struct {
+type BatchCreateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Tasks         []*CreateTaskRequest   `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	Mode          BatchMode              `protobuf:"varint,2,opt,name=mode,proto3,enum=checklist.BatchMode" json:"mode,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskResponse) Reset() {
-	*x = TaskResponse{}
-	mi := &file_internal_app_pb_task_proto_msgTypes[2]
+func (x *BatchCreateRequest) Reset() {
+	*x = BatchCreateRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskResponse) String() string {
+func (x *BatchCreateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskResponse) ProtoMessage() {}
+func (*BatchCreateRequest) ProtoMessage() {}
 
-func (x *TaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_app_pb_task_proto_msgTypes[2]
+func (x *BatchCreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -183,40 +2106,48 @@ func (x *TaskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskResponse.ProtoReflect.Descriptor instead.
-func (*TaskResponse) Descriptor() ([]byte, []int) {
-	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use BatchCreateRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *TaskResponse) GetTask() *Task {
+func (x *BatchCreateRequest) GetTasks() []*CreateTaskRequest {
 	if x != nil {
-		return x.Task
+		return x.Tasks
 	}
 	return nil
 }
 
-type TaskIDRequest struct {
+func (x *BatchCreateRequest) GetMode() BatchMode {
+	if x != nil {
+		return x.Mode
+	}
+	return BatchMode_BATCH_MODE_UNSPECIFIED
+}
+
+type BatchTaskIDsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	Mode          BatchMode              `protobuf:"varint,2,opt,name=mode,proto3,enum=checklist.BatchMode" json:"mode,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskIDRequest) Reset() {
-	*x = TaskIDRequest{}
-	mi := &file_internal_app_pb_task_proto_msgTypes[3]
+func (x *BatchTaskIDsRequest) Reset() {
+	*x = BatchTaskIDsRequest{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskIDRequest) String() string {
+func (x *BatchTaskIDsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskIDRequest) ProtoMessage() {}
+func (*BatchTaskIDsRequest) ProtoMessage() {}
 
-func (x *TaskIDRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_app_pb_task_proto_msgTypes[3]
+func (x *BatchTaskIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -227,40 +2158,55 @@ func (x *TaskIDRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskIDRequest.ProtoReflect.Descriptor instead.
-func (*TaskIDRequest) Descriptor() ([]byte, []int) {
-	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use BatchTaskIDsRequest.ProtoReflect.Descriptor instead.
+func (*BatchTaskIDsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *TaskIDRequest) GetId() string {
+func (x *BatchTaskIDsRequest) GetIds() []string {
 	if x != nil {
-		return x.Id
+		return x.Ids
 	}
-	return ""
+	return nil
 }
 
-type StatusResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+func (x *BatchTaskIDsRequest) GetMode() BatchMode {
+	if x != nil {
+		return x.Mode
+	}
+	return BatchMode_BATCH_MODE_UNSPECIFIED
+}
+
+// The outcome of one item of a batch.
+type BatchItemResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The task the item is about. Empty for a task that failed to be created.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The task after the change, unless the item failed or deleted it.
+	Task *Task `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	// The google.rpc.Code of the failure of the item, OK if it succeeded.
+	// Items rolled back because another one failed are ABORTED.
+	Code          int32  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StatusResponse) Reset() {
-	*x = StatusResponse{}
-	mi := &file_internal_app_pb_task_proto_msgTypes[4]
+func (x *BatchItemResult) Reset() {
+	*x = BatchItemResult{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StatusResponse) String() string {
+func (x *BatchItemResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StatusResponse) ProtoMessage() {}
+func (*BatchItemResult) ProtoMessage() {}
 
-func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_app_pb_task_proto_msgTypes[4]
+func (x *BatchItemResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -271,40 +2217,62 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
-func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use BatchItemResult.ProtoReflect.Descriptor instead.
+func (*BatchItemResult) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *StatusResponse) GetStatus() string {
+func (x *BatchItemResult) GetId() string {
 	if x != nil {
-		return x.Status
+		return x.Id
 	}
 	return ""
 }
 
-type TaskListResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+func (x *BatchItemResult) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *BatchItemResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchItemResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per item, in the order of the request.
+	Results       []*BatchItemResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskListResponse) Reset() {
-	*x = TaskListResponse{}
-	mi := &file_internal_app_pb_task_proto_msgTypes[5]
+func (x *BatchResponse) Reset() {
+	*x = BatchResponse{}
+	mi := &file_internal_app_pb_task_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskListResponse) String() string {
+func (x *BatchResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskListResponse) ProtoMessage() {}
+func (*BatchResponse) ProtoMessage() {}
 
-func (x *TaskListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_app_pb_task_proto_msgTypes[5]
+func (x *BatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_app_pb_task_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -315,14 +2283,14 @@ func (x *TaskListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskListResponse.ProtoReflect.Descriptor instead.
-func (*TaskListResponse) Descriptor() ([]byte, []int) {
-	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use BatchResponse.ProtoReflect.Descriptor instead.
+func (*BatchResponse) Descriptor() ([]byte, []int) {
+	return file_internal_app_pb_task_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *TaskListResponse) GetTasks() []*Task {
+func (x *BatchResponse) GetResults() []*BatchItemResult {
 	if x != nil {
-		return x.Tasks
+		return x.Results
 	}
 	return nil
 }
@@ -331,30 +2299,209 @@ var File_internal_app_pb_task_proto protoreflect.FileDescriptor
 
 const file_internal_app_pb_task_proto_rawDesc = "" +
 	"\n" +
-	"\x1ainternal/app/pb/task.proto\x12\tchecklist\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1bgoogle/protobuf/empty.proto\"\x95\x01\n" +
+	"\x1ainternal/app/pb/task.proto\x12\tchecklist\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a google/protobuf/field_mask.proto\x1a\x1egoogle/protobuf/duration.proto\"\xad\x05\n" +
 	"\x04Task\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12\x12\n" +
 	"\x04done\x18\x04 \x01(\bR\x04done\x129\n" +
 	"\n" +
-	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"C\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x19\n" +
+	"\bowner_id\x18\x06 \x01(\tR\aownerId\x12!\n" +
+	"\fworkspace_id\x18\a \x01(\tR\vworkspaceId\x12#\n" +
+	"\rauto_complete\x18\b \x01(\bR\fautoComplete\x12)\n" +
+	"\x05items\x18\t \x03(\v2\x13.checklist.TaskItemR\x05items\x121\n" +
+	"\x06due_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x127\n" +
+	"\tremind_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\bremindAt\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\f \x01(\tR\n" +
+	"recurrence\x12\x1b\n" +
+	"\ttime_zone\x18\r \x01(\tR\btimeZone\x12\x16\n" +
+	"\x06status\x18\x0e \x01(\tR\x06status\x12F\n" +
+	"\x11status_changed_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\x0fstatusChangedAt\x12*\n" +
+	"\x11status_changed_by\x18\x10 \x01(\tR\x0fstatusChangedBy\x129\n" +
+	"\n" +
+	"deleted_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\x18\n" +
+	"\aversion\x18\x12 \x01(\x03R\aversion\"\x99\x01\n" +
+	"\bTaskItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bposition\x18\x02 \x01(\x05R\bposition\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\x12\x12\n" +
+	"\x04done\x18\x04 \x01(\bR\x04done\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x91\x02\n" +
 	"\x11CreateTaskRequest\x12\x14\n" +
 	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\tR\acontent\"3\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12#\n" +
+	"\rauto_complete\x18\x03 \x01(\bR\fautoComplete\x121\n" +
+	"\x06due_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x127\n" +
+	"\tremind_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bremindAt\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\x06 \x01(\tR\n" +
+	"recurrence\x12\x1b\n" +
+	"\ttime_zone\x18\a \x01(\tR\btimeZone\"\x85\x01\n" +
+	"\x11UpdateTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\x04task\x18\x02 \x01(\v2\x0f.checklist.TaskR\x04task\x12;\n" +
+	"\vupdate_mask\x18\x03 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"3\n" +
 	"\fTaskResponse\x12#\n" +
 	"\x04task\x18\x01 \x01(\v2\x0f.checklist.TaskR\x04task\"\x1f\n" +
 	"\rTaskIDRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"(\n" +
 	"\x0eStatusResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\"9\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\x8e\x03\n" +
+	"\x10ListTasksRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x17\n" +
+	"\x04done\x18\x03 \x01(\bH\x00R\x04done\x88\x01\x01\x12?\n" +
+	"\rcreated_after\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12(\n" +
+	"\x04sort\x18\x06 \x01(\x0e2\x14.checklist.SortOrderR\x04sort\x12\x18\n" +
+	"\aoverdue\x18\a \x01(\bR\aoverdue\x128\n" +
+	"\n" +
+	"due_within\x18\b \x01(\v2\x19.google.protobuf.DurationR\tdueWithin\x12\x1a\n" +
+	"\bstatuses\x18\t \x03(\tR\bstatusesB\a\n" +
+	"\x05_done\"N\n" +
+	"\x10ListTrashRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"?\n" +
+	"\x15TransitionTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"8\n" +
+	"\x12WorkflowTransition\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\"n\n" +
+	"\bWorkflow\x12!\n" +
+	"\fworkspace_id\x18\x01 \x01(\tR\vworkspaceId\x12?\n" +
+	"\vtransitions\x18\x02 \x03(\v2\x1d.checklist.WorkflowTransitionR\vtransitions\"U\n" +
+	"\x12SetWorkflowRequest\x12?\n" +
+	"\vtransitions\x18\x01 \x03(\v2\x1d.checklist.WorkflowTransitionR\vtransitions\"Q\n" +
+	"\vFieldChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x16\n" +
+	"\x06before\x18\x02 \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\x03 \x01(\tR\x05after\"\xcd\x01\n" +
+	"\tTaskEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12;\n" +
+	"\voccurred_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x120\n" +
+	"\achanges\x18\x06 \x03(\v2\x16.checklist.FieldChangeR\achanges\"_\n" +
+	"\x11GetHistoryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\xf7\x01\n" +
+	"\x10ListAuditRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x17\n" +
+	"\atask_id\x18\x03 \x01(\tR\x06taskId\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x14\n" +
+	"\x05types\x18\x05 \x03(\tR\x05types\x120\n" +
+	"\x05since\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x05until\"j\n" +
+	"\x12TaskEventsResponse\x12,\n" +
+	"\x06events\x18\x01 \x03(\v2\x14.checklist.TaskEventR\x06events\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"a\n" +
 	"\x10TaskListResponse\x12%\n" +
-	"\x05tasks\x18\x01 \x03(\v2\x0f.checklist.TaskR\x05tasks2\x8b\x02\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x0f.checklist.TaskR\x05tasks\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"G\n" +
+	"\x12SearchTasksRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"\x95\x01\n" +
+	"\fSearchResult\x12#\n" +
+	"\x04task\x18\x01 \x01(\v2\x0f.checklist.TaskR\x04task\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x02R\x04rank\x12#\n" +
+	"\rtitle_snippet\x18\x03 \x01(\tR\ftitleSnippet\x12'\n" +
+	"\x0fcontent_snippet\x18\x04 \x01(\tR\x0econtentSnippet\"H\n" +
+	"\x13SearchTasksResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.checklist.SearchResultR\aresults\"O\n" +
+	"\x10ShareTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\"=\n" +
+	"\x12UnshareTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"v\n" +
+	"\fCollaborator\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"V\n" +
+	"\x15CollaboratorsResponse\x12=\n" +
+	"\rcollaborators\x18\x01 \x03(\v2\x17.checklist.CollaboratorR\rcollaborators\"=\n" +
+	"\x0eAddItemRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"\xab\x01\n" +
+	"\x11UpdateItemRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x17\n" +
+	"\aitem_id\x18\x02 \x01(\tR\x06itemId\x12'\n" +
+	"\x04item\x18\x03 \x01(\v2\x13.checklist.TaskItemR\x04item\x12;\n" +
+	"\vupdate_mask\x18\x04 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"I\n" +
+	"\x13ReorderItemsRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x19\n" +
+	"\bitem_ids\x18\x02 \x03(\tR\aitemIds\"A\n" +
+	"\rItemIDRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x17\n" +
+	"\aitem_id\x18\x02 \x01(\tR\x06itemId\"r\n" +
+	"\x12BatchCreateRequest\x122\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x1c.checklist.CreateTaskRequestR\x05tasks\x12(\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x14.checklist.BatchModeR\x04mode\"Q\n" +
+	"\x13BatchTaskIDsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\x12(\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x14.checklist.BatchModeR\x04mode\"p\n" +
+	"\x0fBatchItemResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\x04task\x18\x02 \x01(\v2\x0f.checklist.TaskR\x04task\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"E\n" +
+	"\rBatchResponse\x124\n" +
+	"\aresults\x18\x01 \x03(\v2\x1a.checklist.BatchItemResultR\aresults*`\n" +
+	"\tSortOrder\x12\x1a\n" +
+	"\x16SORT_ORDER_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16SORT_ORDER_CREATED_ASC\x10\x01\x12\x1b\n" +
+	"\x17SORT_ORDER_CREATED_DESC\x10\x02*b\n" +
+	"\tBatchMode\x12\x1a\n" +
+	"\x16BATCH_MODE_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19BATCH_MODE_ALL_OR_NOTHING\x10\x01\x12\x1a\n" +
+	"\x16BATCH_MODE_BEST_EFFORT\x10\x022\xb1\r\n" +
 	"\vTaskService\x12?\n" +
-	"\x06Create\x12\x1c.checklist.CreateTaskRequest\x1a\x17.checklist.TaskResponse\x12;\n" +
-	"\x04List\x12\x16.google.protobuf.Empty\x1a\x1b.checklist.TaskListResponse\x12=\n" +
-	"\x06Delete\x12\x18.checklist.TaskIDRequest\x1a\x19.checklist.StatusResponse\x12?\n" +
-	"\bMarkDone\x12\x18.checklist.TaskIDRequest\x1a\x19.checklist.StatusResponseB\aZ\x05./;pbb\x06proto3"
+	"\x06Create\x12\x1c.checklist.CreateTaskRequest\x1a\x17.checklist.TaskResponse\x12@\n" +
+	"\x04List\x12\x1b.checklist.ListTasksRequest\x1a\x1b.checklist.TaskListResponse\x12=\n" +
+	"\x06Delete\x12\x18.checklist.TaskIDRequest\x1a\x19.checklist.StatusResponse\x12<\n" +
+	"\aRestore\x12\x18.checklist.TaskIDRequest\x1a\x17.checklist.TaskResponse\x12E\n" +
+	"\tListTrash\x12\x1b.checklist.ListTrashRequest\x1a\x1b.checklist.TaskListResponse\x12?\n" +
+	"\bMarkDone\x12\x18.checklist.TaskIDRequest\x1a\x19.checklist.StatusResponse\x12G\n" +
+	"\n" +
+	"Transition\x12 .checklist.TransitionTaskRequest\x1a\x17.checklist.TaskResponse\x12;\n" +
+	"\x06Reopen\x12\x18.checklist.TaskIDRequest\x1a\x17.checklist.TaskResponse\x12?\n" +
+	"\x06Update\x12\x1c.checklist.UpdateTaskRequest\x1a\x17.checklist.TaskResponse\x128\n" +
+	"\x03Get\x12\x18.checklist.TaskIDRequest\x1a\x17.checklist.TaskResponse\x12G\n" +
+	"\x06Search\x12\x1d.checklist.SearchTasksRequest\x1a\x1e.checklist.SearchTasksResponse\x12=\n" +
+	"\x05Share\x12\x1b.checklist.ShareTaskRequest\x1a\x17.checklist.Collaborator\x12C\n" +
+	"\aUnshare\x12\x1d.checklist.UnshareTaskRequest\x1a\x19.checklist.StatusResponse\x12O\n" +
+	"\x11ListCollaborators\x12\x18.checklist.TaskIDRequest\x1a .checklist.CollaboratorsResponse\x12=\n" +
+	"\aAddItem\x12\x19.checklist.AddItemRequest\x1a\x17.checklist.TaskResponse\x12C\n" +
+	"\n" +
+	"UpdateItem\x12\x1c.checklist.UpdateItemRequest\x1a\x17.checklist.TaskResponse\x12G\n" +
+	"\fReorderItems\x12\x1e.checklist.ReorderItemsRequest\x1a\x17.checklist.TaskResponse\x12?\n" +
+	"\n" +
+	"RemoveItem\x12\x18.checklist.ItemIDRequest\x1a\x17.checklist.TaskResponse\x12:\n" +
+	"\vGetWorkflow\x12\x16.google.protobuf.Empty\x1a\x13.checklist.Workflow\x12A\n" +
+	"\vSetWorkflow\x12\x1d.checklist.SetWorkflowRequest\x1a\x13.checklist.Workflow\x12I\n" +
+	"\n" +
+	"GetHistory\x12\x1c.checklist.GetHistoryRequest\x1a\x1d.checklist.TaskEventsResponse\x12G\n" +
+	"\tListAudit\x12\x1b.checklist.ListAuditRequest\x1a\x1d.checklist.TaskEventsResponse\x12F\n" +
+	"\vBatchCreate\x12\x1d.checklist.BatchCreateRequest\x1a\x18.checklist.BatchResponse\x12I\n" +
+	"\rBatchMarkDone\x12\x1e.checklist.BatchTaskIDsRequest\x1a\x18.checklist.BatchResponse\x12G\n" +
+	"\vBatchDelete\x12\x1e.checklist.BatchTaskIDsRequest\x1a\x18.checklist.BatchResponseB\aZ\x05./;pbb\x06proto3"
 
 var (
 	file_internal_app_pb_task_proto_rawDescOnce sync.Once
@@ -368,34 +2515,141 @@ func file_internal_app_pb_task_proto_rawDescGZIP() []byte {
 	return file_internal_app_pb_task_proto_rawDescData
 }
 
-var file_internal_app_pb_task_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_internal_app_pb_task_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_internal_app_pb_task_proto_msgTypes = make([]protoimpl.MessageInfo, 34)
 var file_internal_app_pb_task_proto_goTypes = []any{
-	(*Task)(nil),                  // 0: checklist.Task
-	(*CreateTaskRequest)(nil),     // 1: checklist.CreateTaskRequest
-	(*TaskResponse)(nil),          // 2: checklist.TaskResponse
-	(*TaskIDRequest)(nil),         // 3: checklist.TaskIDRequest
-	(*StatusResponse)(nil),        // 4: checklist.StatusResponse
-	(*TaskListResponse)(nil),      // 5: checklist.TaskListResponse
-	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
-	(*emptypb.Empty)(nil),         // 7: google.protobuf.Empty
+	(SortOrder)(0),                // 0: checklist.SortOrder
+	(BatchMode)(0),                // 1: checklist.BatchMode
+	(*Task)(nil),                  // 2: checklist.Task
+	(*TaskItem)(nil),              // 3: checklist.TaskItem
+	(*CreateTaskRequest)(nil),     // 4: checklist.CreateTaskRequest
+	(*UpdateTaskRequest)(nil),     // 5: checklist.UpdateTaskRequest
+	(*TaskResponse)(nil),          // 6: checklist.TaskResponse
+	(*TaskIDRequest)(nil),         // 7: checklist.TaskIDRequest
+	(*StatusResponse)(nil),        // 8: checklist.StatusResponse
+	(*ListTasksRequest)(nil),      // 9: checklist.ListTasksRequest
+	(*ListTrashRequest)(nil),      // 10: checklist.ListTrashRequest
+	(*TransitionTaskRequest)(nil), // 11: checklist.TransitionTaskRequest
+	(*WorkflowTransition)(nil),    // 12: checklist.WorkflowTransition
+	(*Workflow)(nil),              // 13: checklist.Workflow
+	(*SetWorkflowRequest)(nil),    // 14: checklist.SetWorkflowRequest
+	(*FieldChange)(nil),           // 15: checklist.FieldChange
+	(*TaskEvent)(nil),             // 16: checklist.TaskEvent
+	(*GetHistoryRequest)(nil),     // 17: checklist.GetHistoryRequest
+	(*ListAuditRequest)(nil),      // 18: checklist.ListAuditRequest
+	(*TaskEventsResponse)(nil),    // 19: checklist.TaskEventsResponse
+	(*TaskListResponse)(nil),      // 20: checklist.TaskListResponse
+	(*SearchTasksRequest)(nil),    // 21: checklist.SearchTasksRequest
+	(*SearchResult)(nil),          // 22: checklist.SearchResult
+	(*SearchTasksResponse)(nil),   // 23: checklist.SearchTasksResponse
+	(*ShareTaskRequest)(nil),      // 24: checklist.ShareTaskRequest
+	(*UnshareTaskRequest)(nil),    // 25: checklist.UnshareTaskRequest
+	(*Collaborator)(nil),          // 26: checklist.Collaborator
+	(*CollaboratorsResponse)(nil), // 27: checklist.CollaboratorsResponse
+	(*AddItemRequest)(nil),        // 28: checklist.AddItemRequest
+	(*UpdateItemRequest)(nil),     // 29: checklist.UpdateItemRequest
+	(*ReorderItemsRequest)(nil),   // 30: checklist.ReorderItemsRequest
+	(*ItemIDRequest)(nil),         // 31: checklist.ItemIDRequest
+	(*BatchCreateRequest)(nil),    // 32: checklist.BatchCreateRequest
+	(*BatchTaskIDsRequest)(nil),   // 33: checklist.BatchTaskIDsRequest
+	(*BatchItemResult)(nil),       // 34: checklist.BatchItemResult
+	(*BatchResponse)(nil),         // 35: checklist.BatchResponse
+	(*timestamppb.Timestamp)(nil), // 36: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 37: google.protobuf.FieldMask
+	(*durationpb.Duration)(nil),   // 38: google.protobuf.Duration
+	(*emptypb.Empty)(nil),         // 39: google.protobuf.Empty
 }
 var file_internal_app_pb_task_proto_depIdxs = []int32{
-	6, // 0: checklist.Task.created_at:type_name -> google.protobuf.Timestamp
-	0, // 1: checklist.TaskResponse.task:type_name -> checklist.Task
-	0, // 2: checklist.TaskListResponse.tasks:type_name -> checklist.Task
-	1, // 3: checklist.TaskService.Create:input_type -> checklist.CreateTaskRequest
-	7, // 4: checklist.TaskService.List:input_type -> google.protobuf.Empty
-	3, // 5: checklist.TaskService.Delete:input_type -> checklist.TaskIDRequest
-	3, // 6: checklist.TaskService.MarkDone:input_type -> checklist.TaskIDRequest
-	2, // 7: checklist.TaskService.Create:output_type -> checklist.TaskResponse
-	5, // 8: checklist.TaskService.List:output_type -> checklist.TaskListResponse
-	4, // 9: checklist.TaskService.Delete:output_type -> checklist.StatusResponse
-	4, // 10: checklist.TaskService.MarkDone:output_type -> checklist.StatusResponse
-	7, // [7:11] is the sub-list for method output_type
-	3, // [3:7] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	36, // 0: checklist.Task.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 1: checklist.Task.items:type_name -> checklist.TaskItem
+	36, // 2: checklist.Task.due_at:type_name -> google.protobuf.Timestamp
+	36, // 3: checklist.Task.remind_at:type_name -> google.protobuf.Timestamp
+	36, // 4: checklist.Task.status_changed_at:type_name -> google.protobuf.Timestamp
+	36, // 5: checklist.Task.deleted_at:type_name -> google.protobuf.Timestamp
+	36, // 6: checklist.TaskItem.created_at:type_name -> google.protobuf.Timestamp
+	36, // 7: checklist.CreateTaskRequest.due_at:type_name -> google.protobuf.Timestamp
+	36, // 8: checklist.CreateTaskRequest.remind_at:type_name -> google.protobuf.Timestamp
+	2,  // 9: checklist.UpdateTaskRequest.task:type_name -> checklist.Task
+	37, // 10: checklist.UpdateTaskRequest.update_mask:type_name -> google.protobuf.FieldMask
+	2,  // 11: checklist.TaskResponse.task:type_name -> checklist.Task
+	36, // 12: checklist.ListTasksRequest.created_after:type_name -> google.protobuf.Timestamp
+	36, // 13: checklist.ListTasksRequest.created_before:type_name -> google.protobuf.Timestamp
+	0,  // 14: checklist.ListTasksRequest.sort:type_name -> checklist.SortOrder
+	38, // 15: checklist.ListTasksRequest.due_within:type_name -> google.protobuf.Duration
+	12, // 16: checklist.Workflow.transitions:type_name -> checklist.WorkflowTransition
+	12, // 17: checklist.SetWorkflowRequest.transitions:type_name -> checklist.WorkflowTransition
+	36, // 18: checklist.TaskEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	15, // 19: checklist.TaskEvent.changes:type_name -> checklist.FieldChange
+	36, // 20: checklist.ListAuditRequest.since:type_name -> google.protobuf.Timestamp
+	36, // 21: checklist.ListAuditRequest.until:type_name -> google.protobuf.Timestamp
+	16, // 22: checklist.TaskEventsResponse.events:type_name -> checklist.TaskEvent
+	2,  // 23: checklist.TaskListResponse.tasks:type_name -> checklist.Task
+	2,  // 24: checklist.SearchResult.task:type_name -> checklist.Task
+	22, // 25: checklist.SearchTasksResponse.results:type_name -> checklist.SearchResult
+	36, // 26: checklist.Collaborator.created_at:type_name -> google.protobuf.Timestamp
+	26, // 27: checklist.CollaboratorsResponse.collaborators:type_name -> checklist.Collaborator
+	3,  // 28: checklist.UpdateItemRequest.item:type_name -> checklist.TaskItem
+	37, // 29: checklist.UpdateItemRequest.update_mask:type_name -> google.protobuf.FieldMask
+	4,  // 30: checklist.BatchCreateRequest.tasks:type_name -> checklist.CreateTaskRequest
+	1,  // 31: checklist.BatchCreateRequest.mode:type_name -> checklist.BatchMode
+	1,  // 32: checklist.BatchTaskIDsRequest.mode:type_name -> checklist.BatchMode
+	2,  // 33: checklist.BatchItemResult.task:type_name -> checklist.Task
+	34, // 34: checklist.BatchResponse.results:type_name -> checklist.BatchItemResult
+	4,  // 35: checklist.TaskService.Create:input_type -> checklist.CreateTaskRequest
+	9,  // 36: checklist.TaskService.List:input_type -> checklist.ListTasksRequest
+	7,  // 37: checklist.TaskService.Delete:input_type -> checklist.TaskIDRequest
+	7,  // 38: checklist.TaskService.Restore:input_type -> checklist.TaskIDRequest
+	10, // 39: checklist.TaskService.ListTrash:input_type -> checklist.ListTrashRequest
+	7,  // 40: checklist.TaskService.MarkDone:input_type -> checklist.TaskIDRequest
+	11, // 41: checklist.TaskService.Transition:input_type -> checklist.TransitionTaskRequest
+	7,  // 42: checklist.TaskService.Reopen:input_type -> checklist.TaskIDRequest
+	5,  // 43: checklist.TaskService.Update:input_type -> checklist.UpdateTaskRequest
+	7,  // 44: checklist.TaskService.Get:input_type -> checklist.TaskIDRequest
+	21, // 45: checklist.TaskService.Search:input_type -> checklist.SearchTasksRequest
+	24, // 46: checklist.TaskService.Share:input_type -> checklist.ShareTaskRequest
+	25, // 47: checklist.TaskService.Unshare:input_type -> checklist.UnshareTaskRequest
+	7,  // 48: checklist.TaskService.ListCollaborators:input_type -> checklist.TaskIDRequest
+	28, // 49: checklist.TaskService.AddItem:input_type -> checklist.AddItemRequest
+	29, // 50: checklist.TaskService.UpdateItem:input_type -> checklist.UpdateItemRequest
+	30, // 51: checklist.TaskService.ReorderItems:input_type -> checklist.ReorderItemsRequest
+	31, // 52: checklist.TaskService.RemoveItem:input_type -> checklist.ItemIDRequest
+	39, // 53: checklist.TaskService.GetWorkflow:input_type -> google.protobuf.Empty
+	14, // 54: checklist.TaskService.SetWorkflow:input_type -> checklist.SetWorkflowRequest
+	17, // 55: checklist.TaskService.GetHistory:input_type -> checklist.GetHistoryRequest
+	18, // 56: checklist.TaskService.ListAudit:input_type -> checklist.ListAuditRequest
+	32, // 57: checklist.TaskService.BatchCreate:input_type -> checklist.BatchCreateRequest
+	33, // 58: checklist.TaskService.BatchMarkDone:input_type -> checklist.BatchTaskIDsRequest
+	33, // 59: checklist.TaskService.BatchDelete:input_type -> checklist.BatchTaskIDsRequest
+	6,  // 60: checklist.TaskService.Create:output_type -> checklist.TaskResponse
+	20, // 61: checklist.TaskService.List:output_type -> checklist.TaskListResponse
+	8,  // 62: checklist.TaskService.Delete:output_type -> checklist.StatusResponse
+	6,  // 63: checklist.TaskService.Restore:output_type -> checklist.TaskResponse
+	20, // 64: checklist.TaskService.ListTrash:output_type -> checklist.TaskListResponse
+	8,  // 65: checklist.TaskService.MarkDone:output_type -> checklist.StatusResponse
+	6,  // 66: checklist.TaskService.Transition:output_type -> checklist.TaskResponse
+	6,  // 67: checklist.TaskService.Reopen:output_type -> checklist.TaskResponse
+	6,  // 68: checklist.TaskService.Update:output_type -> checklist.TaskResponse
+	6,  // 69: checklist.TaskService.Get:output_type -> checklist.TaskResponse
+	23, // 70: checklist.TaskService.Search:output_type -> checklist.SearchTasksResponse
+	26, // 71: checklist.TaskService.Share:output_type -> checklist.Collaborator
+	8,  // 72: checklist.TaskService.Unshare:output_type -> checklist.StatusResponse
+	27, // 73: checklist.TaskService.ListCollaborators:output_type -> checklist.CollaboratorsResponse
+	6,  // 74: checklist.TaskService.AddItem:output_type -> checklist.TaskResponse
+	6,  // 75: checklist.TaskService.UpdateItem:output_type -> checklist.TaskResponse
+	6,  // 76: checklist.TaskService.ReorderItems:output_type -> checklist.TaskResponse
+	6,  // 77: checklist.TaskService.RemoveItem:output_type -> checklist.TaskResponse
+	13, // 78: checklist.TaskService.GetWorkflow:output_type -> checklist.Workflow
+	13, // 79: checklist.TaskService.SetWorkflow:output_type -> checklist.Workflow
+	19, // 80: checklist.TaskService.GetHistory:output_type -> checklist.TaskEventsResponse
+	19, // 81: checklist.TaskService.ListAudit:output_type -> checklist.TaskEventsResponse
+	35, // 82: checklist.TaskService.BatchCreate:output_type -> checklist.BatchResponse
+	35, // 83: checklist.TaskService.BatchMarkDone:output_type -> checklist.BatchResponse
+	35, // 84: checklist.TaskService.BatchDelete:output_type -> checklist.BatchResponse
+	60, // [60:85] is the sub-list for method output_type
+	35, // [35:60] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
 }
 
 func init() { file_internal_app_pb_task_proto_init() }
@@ -403,18 +2657,20 @@ func file_internal_app_pb_task_proto_init() {
 	if File_internal_app_pb_task_proto != nil {
 		return
 	}
+	file_internal_app_pb_task_proto_msgTypes[7].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_app_pb_task_proto_rawDesc), len(file_internal_app_pb_task_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      2,
+			NumMessages:   34,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_internal_app_pb_task_proto_goTypes,
 		DependencyIndexes: file_internal_app_pb_task_proto_depIdxs,
+		EnumInfos:         file_internal_app_pb_task_proto_enumTypes,
 		MessageInfos:      file_internal_app_pb_task_proto_msgTypes,
 	}.Build()
 	File_internal_app_pb_task_proto = out.File
@@ -20,10 +20,31 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TaskService_Create_FullMethodName   = "/checklist.TaskService/Create"
-	TaskService_List_FullMethodName     = "/checklist.TaskService/List"
-	TaskService_Delete_FullMethodName   = "/checklist.TaskService/Delete"
-	TaskService_MarkDone_FullMethodName = "/checklist.TaskService/MarkDone"
+	TaskService_Create_FullMethodName            = "/checklist.TaskService/Create"
+	TaskService_List_FullMethodName              = "/checklist.TaskService/List"
+	TaskService_Delete_FullMethodName            = "/checklist.TaskService/Delete"
+	TaskService_Restore_FullMethodName           = "/checklist.TaskService/Restore"
+	TaskService_ListTrash_FullMethodName         = "/checklist.TaskService/ListTrash"
+	TaskService_MarkDone_FullMethodName          = "/checklist.TaskService/MarkDone"
+	TaskService_Transition_FullMethodName        = "/checklist.TaskService/Transition"
+	TaskService_Reopen_FullMethodName            = "/checklist.TaskService/Reopen"
+	TaskService_Update_FullMethodName            = "/checklist.TaskService/Update"
+	TaskService_Get_FullMethodName               = "/checklist.TaskService/Get"
+	TaskService_Search_FullMethodName            = "/checklist.TaskService/Search"
+	TaskService_Share_FullMethodName             = "/checklist.TaskService/Share"
+	TaskService_Unshare_FullMethodName           = "/checklist.TaskService/Unshare"
+	TaskService_ListCollaborators_FullMethodName = "/checklist.TaskService/ListCollaborators"
+	TaskService_AddItem_FullMethodName           = "/checklist.TaskService/AddItem"
+	TaskService_UpdateItem_FullMethodName        = "/checklist.TaskService/UpdateItem"
+	TaskService_ReorderItems_FullMethodName      = "/checklist.TaskService/ReorderItems"
+	TaskService_RemoveItem_FullMethodName        = "/checklist.TaskService/RemoveItem"
+	TaskService_GetWorkflow_FullMethodName       = "/checklist.TaskService/GetWorkflow"
+	TaskService_SetWorkflow_FullMethodName       = "/checklist.TaskService/SetWorkflow"
+	TaskService_GetHistory_FullMethodName        = "/checklist.TaskService/GetHistory"
+	TaskService_ListAudit_FullMethodName         = "/checklist.TaskService/ListAudit"
+	TaskService_BatchCreate_FullMethodName       = "/checklist.TaskService/BatchCreate"
+	TaskService_BatchMarkDone_FullMethodName     = "/checklist.TaskService/BatchMarkDone"
+	TaskService_BatchDelete_FullMethodName       = "/checklist.TaskService/BatchDelete"
 )
 
 // TaskServiceClient is the client API for TaskService service.
@@ -31,9 +52,49 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TaskServiceClient interface {
 	Create(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error)
-	List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TaskListResponse, error)
+	List(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*TaskListResponse, error)
+	// Delete moves a task to the trash, from which its owner may restore it
+	// until it is purged.
 	Delete(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	Restore(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	// ListTrash lists the deleted tasks of the caller, the most recently
+	// created first.
+	ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*TaskListResponse, error)
+	// MarkDone is Transition to "done".
 	MarkDone(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Transition moves a task to another status if the workflow of its
+	// workspace allows it, and fails with FAILED_PRECONDITION otherwise.
+	Transition(ctx context.Context, in *TransitionTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	// Reopen moves a done or cancelled task back to "todo".
+	Reopen(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	Update(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	Get(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	Search(ctx context.Context, in *SearchTasksRequest, opts ...grpc.CallOption) (*SearchTasksResponse, error)
+	// Only the owner of a task may share it. Sharing with a user again
+	// changes their role.
+	Share(ctx context.Context, in *ShareTaskRequest, opts ...grpc.CallOption) (*Collaborator, error)
+	Unshare(ctx context.Context, in *UnshareTaskRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	ListCollaborators(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*CollaboratorsResponse, error)
+	// The item methods return the task with its items after the change.
+	AddItem(ctx context.Context, in *AddItemRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	ReorderItems(ctx context.Context, in *ReorderItemsRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	RemoveItem(ctx context.Context, in *ItemIDRequest, opts ...grpc.CallOption) (*TaskResponse, error)
+	// The workflow of the workspace of the caller. Only administrators of the
+	// workspace may set it.
+	GetWorkflow(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Workflow, error)
+	SetWorkflow(ctx context.Context, in *SetWorkflowRequest, opts ...grpc.CallOption) (*Workflow, error)
+	// GetHistory returns the recorded changes of a task, to anyone who may
+	// view it. ListAudit returns the changes of every task of the workspace,
+	// deleted ones included, to its administrators.
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*TaskEventsResponse, error)
+	ListAudit(ctx context.Context, in *ListAuditRequest, opts ...grpc.CallOption) (*TaskEventsResponse, error)
+	// The batch methods are Create, MarkDone and Delete of up to 100 tasks
+	// in one transaction. Failed items are reported in the results, the call
+	// itself fails only if the batch is malformed or could not run.
+	BatchCreate(ctx context.Context, in *BatchCreateRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchMarkDone(ctx context.Context, in *BatchTaskIDsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchDelete(ctx context.Context, in *BatchTaskIDsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
 }
 
 type taskServiceClient struct {
@@ -54,7 +115,7 @@ func (c *taskServiceClient) Create(ctx context.Context, in *CreateTaskRequest, o
 	return out, nil
 }
 
-func (c *taskServiceClient) List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TaskListResponse, error) {
+func (c *taskServiceClient) List(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*TaskListResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TaskListResponse)
 	err := c.cc.Invoke(ctx, TaskService_List_FullMethodName, in, out, cOpts...)
@@ -74,6 +135,26 @@ func (c *taskServiceClient) Delete(ctx context.Context, in *TaskIDRequest, opts
 	return out, nil
 }
 
+func (c *taskServiceClient) Restore(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_Restore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*TaskListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskListResponse)
+	err := c.cc.Invoke(ctx, TaskService_ListTrash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *taskServiceClient) MarkDone(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatusResponse)
@@ -84,14 +165,244 @@ func (c *taskServiceClient) MarkDone(ctx context.Context, in *TaskIDRequest, opt
 	return out, nil
 }
 
+func (c *taskServiceClient) Transition(ctx context.Context, in *TransitionTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_Transition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) Reopen(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_Reopen_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) Update(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) Get(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) Search(ctx context.Context, in *SearchTasksRequest, opts ...grpc.CallOption) (*SearchTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchTasksResponse)
+	err := c.cc.Invoke(ctx, TaskService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) Share(ctx context.Context, in *ShareTaskRequest, opts ...grpc.CallOption) (*Collaborator, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Collaborator)
+	err := c.cc.Invoke(ctx, TaskService_Share_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) Unshare(ctx context.Context, in *UnshareTaskRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, TaskService_Unshare_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ListCollaborators(ctx context.Context, in *TaskIDRequest, opts ...grpc.CallOption) (*CollaboratorsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollaboratorsResponse)
+	err := c.cc.Invoke(ctx, TaskService_ListCollaborators_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) AddItem(ctx context.Context, in *AddItemRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_AddItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_UpdateItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ReorderItems(ctx context.Context, in *ReorderItemsRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_ReorderItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) RemoveItem(ctx context.Context, in *ItemIDRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskResponse)
+	err := c.cc.Invoke(ctx, TaskService_RemoveItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) GetWorkflow(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*Workflow, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Workflow)
+	err := c.cc.Invoke(ctx, TaskService_GetWorkflow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) SetWorkflow(ctx context.Context, in *SetWorkflowRequest, opts ...grpc.CallOption) (*Workflow, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Workflow)
+	err := c.cc.Invoke(ctx, TaskService_SetWorkflow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*TaskEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskEventsResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ListAudit(ctx context.Context, in *ListAuditRequest, opts ...grpc.CallOption) (*TaskEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskEventsResponse)
+	err := c.cc.Invoke(ctx, TaskService_ListAudit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) BatchCreate(ctx context.Context, in *BatchCreateRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, TaskService_BatchCreate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) BatchMarkDone(ctx context.Context, in *BatchTaskIDsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, TaskService_BatchMarkDone_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) BatchDelete(ctx context.Context, in *BatchTaskIDsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, TaskService_BatchDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TaskServiceServer is the server API for TaskService service.
 // All implementations must embed UnimplementedTaskServiceServer
 // for forward compatibility.
 type TaskServiceServer interface {
 	Create(context.Context, *CreateTaskRequest) (*TaskResponse, error)
-	List(context.Context, *emptypb.Empty) (*TaskListResponse, error)
+	List(context.Context, *ListTasksRequest) (*TaskListResponse, error)
+	// Delete moves a task to the trash, from which its owner may restore it
+	// until it is purged.
 	Delete(context.Context, *TaskIDRequest) (*StatusResponse, error)
+	Restore(context.Context, *TaskIDRequest) (*TaskResponse, error)
+	// ListTrash lists the deleted tasks of the caller, the most recently
+	// created first.
+	ListTrash(context.Context, *ListTrashRequest) (*TaskListResponse, error)
+	// MarkDone is Transition to "done".
 	MarkDone(context.Context, *TaskIDRequest) (*StatusResponse, error)
+	// Transition moves a task to another status if the workflow of its
+	// workspace allows it, and fails with FAILED_PRECONDITION otherwise.
+	Transition(context.Context, *TransitionTaskRequest) (*TaskResponse, error)
+	// Reopen moves a done or cancelled task back to "todo".
+	Reopen(context.Context, *TaskIDRequest) (*TaskResponse, error)
+	Update(context.Context, *UpdateTaskRequest) (*TaskResponse, error)
+	Get(context.Context, *TaskIDRequest) (*TaskResponse, error)
+	Search(context.Context, *SearchTasksRequest) (*SearchTasksResponse, error)
+	// Only the owner of a task may share it. Sharing with a user again
+	// changes their role.
+	Share(context.Context, *ShareTaskRequest) (*Collaborator, error)
+	Unshare(context.Context, *UnshareTaskRequest) (*StatusResponse, error)
+	ListCollaborators(context.Context, *TaskIDRequest) (*CollaboratorsResponse, error)
+	// The item methods return the task with its items after the change.
+	AddItem(context.Context, *AddItemRequest) (*TaskResponse, error)
+	UpdateItem(context.Context, *UpdateItemRequest) (*TaskResponse, error)
+	ReorderItems(context.Context, *ReorderItemsRequest) (*TaskResponse, error)
+	RemoveItem(context.Context, *ItemIDRequest) (*TaskResponse, error)
+	// The workflow of the workspace of the caller. Only administrators of the
+	// workspace may set it.
+	GetWorkflow(context.Context, *emptypb.Empty) (*Workflow, error)
+	SetWorkflow(context.Context, *SetWorkflowRequest) (*Workflow, error)
+	// GetHistory returns the recorded changes of a task, to anyone who may
+	// view it. ListAudit returns the changes of every task of the workspace,
+	// deleted ones included, to its administrators.
+	GetHistory(context.Context, *GetHistoryRequest) (*TaskEventsResponse, error)
+	ListAudit(context.Context, *ListAuditRequest) (*TaskEventsResponse, error)
+	// The batch methods are Create, MarkDone and Delete of up to 100 tasks
+	// in one transaction. Failed items are reported in the results, the call
+	// itself fails only if the batch is malformed or could not run.
+	BatchCreate(context.Context, *BatchCreateRequest) (*BatchResponse, error)
+	BatchMarkDone(context.Context, *BatchTaskIDsRequest) (*BatchResponse, error)
+	BatchDelete(context.Context, *BatchTaskIDsRequest) (*BatchResponse, error)
 	mustEmbedUnimplementedTaskServiceServer()
 }
 
@@ -105,15 +416,78 @@ type UnimplementedTaskServiceServer struct{}
 func (UnimplementedTaskServiceServer) Create(context.Context, *CreateTaskRequest) (*TaskResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Create not implemented")
 }
-func (UnimplementedTaskServiceServer) List(context.Context, *emptypb.Empty) (*TaskListResponse, error) {
+func (UnimplementedTaskServiceServer) List(context.Context, *ListTasksRequest) (*TaskListResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method List not implemented")
 }
 func (UnimplementedTaskServiceServer) Delete(context.Context, *TaskIDRequest) (*StatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedTaskServiceServer) Restore(context.Context, *TaskIDRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedTaskServiceServer) ListTrash(context.Context, *ListTrashRequest) (*TaskListResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTrash not implemented")
+}
 func (UnimplementedTaskServiceServer) MarkDone(context.Context, *TaskIDRequest) (*StatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method MarkDone not implemented")
 }
+func (UnimplementedTaskServiceServer) Transition(context.Context, *TransitionTaskRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Transition not implemented")
+}
+func (UnimplementedTaskServiceServer) Reopen(context.Context, *TaskIDRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Reopen not implemented")
+}
+func (UnimplementedTaskServiceServer) Update(context.Context, *UpdateTaskRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedTaskServiceServer) Get(context.Context, *TaskIDRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedTaskServiceServer) Search(context.Context, *SearchTasksRequest) (*SearchTasksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedTaskServiceServer) Share(context.Context, *ShareTaskRequest) (*Collaborator, error) {
+	return nil, status.Error(codes.Unimplemented, "method Share not implemented")
+}
+func (UnimplementedTaskServiceServer) Unshare(context.Context, *UnshareTaskRequest) (*StatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Unshare not implemented")
+}
+func (UnimplementedTaskServiceServer) ListCollaborators(context.Context, *TaskIDRequest) (*CollaboratorsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListCollaborators not implemented")
+}
+func (UnimplementedTaskServiceServer) AddItem(context.Context, *AddItemRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddItem not implemented")
+}
+func (UnimplementedTaskServiceServer) UpdateItem(context.Context, *UpdateItemRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateItem not implemented")
+}
+func (UnimplementedTaskServiceServer) ReorderItems(context.Context, *ReorderItemsRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReorderItems not implemented")
+}
+func (UnimplementedTaskServiceServer) RemoveItem(context.Context, *ItemIDRequest) (*TaskResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveItem not implemented")
+}
+func (UnimplementedTaskServiceServer) GetWorkflow(context.Context, *emptypb.Empty) (*Workflow, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetWorkflow not implemented")
+}
+func (UnimplementedTaskServiceServer) SetWorkflow(context.Context, *SetWorkflowRequest) (*Workflow, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetWorkflow not implemented")
+}
+func (UnimplementedTaskServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*TaskEventsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedTaskServiceServer) ListAudit(context.Context, *ListAuditRequest) (*TaskEventsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAudit not implemented")
+}
+func (UnimplementedTaskServiceServer) BatchCreate(context.Context, *BatchCreateRequest) (*BatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchCreate not implemented")
+}
+func (UnimplementedTaskServiceServer) BatchMarkDone(context.Context, *BatchTaskIDsRequest) (*BatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchMarkDone not implemented")
+}
+func (UnimplementedTaskServiceServer) BatchDelete(context.Context, *BatchTaskIDsRequest) (*BatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchDelete not implemented")
+}
 func (UnimplementedTaskServiceServer) mustEmbedUnimplementedTaskServiceServer() {}
 func (UnimplementedTaskServiceServer) testEmbeddedByValue()                     {}
 
@@ -154,7 +528,7 @@ func _TaskService_Create_Handler(srv interface{}, ctx context.Context, dec func(
 }
 
 func _TaskService_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(ListTasksRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -166,7 +540,7 @@ func _TaskService_List_Handler(srv interface{}, ctx context.Context, dec func(in
 		FullMethod: TaskService_List_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TaskServiceServer).List(ctx, req.(*emptypb.Empty))
+		return srv.(TaskServiceServer).List(ctx, req.(*ListTasksRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -189,6 +563,42 @@ func _TaskService_Delete_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).Restore(ctx, req.(*TaskIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ListTrash(ctx, req.(*ListTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TaskService_MarkDone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TaskIDRequest)
 	if err := dec(in); err != nil {
@@ -207,6 +617,348 @@ func _TaskService_MarkDone_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskService_Transition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransitionTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).Transition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_Transition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).Transition(ctx, req.(*TransitionTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_Reopen_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).Reopen(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_Reopen_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).Reopen(ctx, req.(*TaskIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).Update(ctx, req.(*UpdateTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).Get(ctx, req.(*TaskIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).Search(ctx, req.(*SearchTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_Share_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).Share(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_Share_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).Share(ctx, req.(*ShareTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_Unshare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnshareTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).Unshare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_Unshare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).Unshare(ctx, req.(*UnshareTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ListCollaborators_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ListCollaborators(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ListCollaborators_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ListCollaborators(ctx, req.(*TaskIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_AddItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).AddItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_AddItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).AddItem(ctx, req.(*AddItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_UpdateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).UpdateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_UpdateItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).UpdateItem(ctx, req.(*UpdateItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ReorderItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ReorderItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ReorderItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ReorderItems(ctx, req.(*ReorderItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_RemoveItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ItemIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).RemoveItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_RemoveItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).RemoveItem(ctx, req.(*ItemIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_GetWorkflow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetWorkflow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetWorkflow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetWorkflow(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_SetWorkflow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetWorkflowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).SetWorkflow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_SetWorkflow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).SetWorkflow(ctx, req.(*SetWorkflowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ListAudit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ListAudit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ListAudit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ListAudit(ctx, req.(*ListAuditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_BatchCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).BatchCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_BatchCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).BatchCreate(ctx, req.(*BatchCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_BatchMarkDone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchTaskIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).BatchMarkDone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_BatchMarkDone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).BatchMarkDone(ctx, req.(*BatchTaskIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_BatchDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchTaskIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).BatchDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_BatchDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).BatchDelete(ctx, req.(*BatchTaskIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TaskService_ServiceDesc is the grpc.ServiceDesc for TaskService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -226,10 +978,94 @@ var TaskService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _TaskService_Delete_Handler,
 		},
+		{
+			MethodName: "Restore",
+			Handler:    _TaskService_Restore_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _TaskService_ListTrash_Handler,
+		},
 		{
 			MethodName: "MarkDone",
 			Handler:    _TaskService_MarkDone_Handler,
 		},
+		{
+			MethodName: "Transition",
+			Handler:    _TaskService_Transition_Handler,
+		},
+		{
+			MethodName: "Reopen",
+			Handler:    _TaskService_Reopen_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _TaskService_Update_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _TaskService_Get_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _TaskService_Search_Handler,
+		},
+		{
+			MethodName: "Share",
+			Handler:    _TaskService_Share_Handler,
+		},
+		{
+			MethodName: "Unshare",
+			Handler:    _TaskService_Unshare_Handler,
+		},
+		{
+			MethodName: "ListCollaborators",
+			Handler:    _TaskService_ListCollaborators_Handler,
+		},
+		{
+			MethodName: "AddItem",
+			Handler:    _TaskService_AddItem_Handler,
+		},
+		{
+			MethodName: "UpdateItem",
+			Handler:    _TaskService_UpdateItem_Handler,
+		},
+		{
+			MethodName: "ReorderItems",
+			Handler:    _TaskService_ReorderItems_Handler,
+		},
+		{
+			MethodName: "RemoveItem",
+			Handler:    _TaskService_RemoveItem_Handler,
+		},
+		{
+			MethodName: "GetWorkflow",
+			Handler:    _TaskService_GetWorkflow_Handler,
+		},
+		{
+			MethodName: "SetWorkflow",
+			Handler:    _TaskService_SetWorkflow_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _TaskService_GetHistory_Handler,
+		},
+		{
+			MethodName: "ListAudit",
+			Handler:    _TaskService_ListAudit_Handler,
+		},
+		{
+			MethodName: "BatchCreate",
+			Handler:    _TaskService_BatchCreate_Handler,
+		},
+		{
+			MethodName: "BatchMarkDone",
+			Handler:    _TaskService_BatchMarkDone_Handler,
+		},
+		{
+			MethodName: "BatchDelete",
+			Handler:    _TaskService_BatchDelete_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/app/pb/task.proto",
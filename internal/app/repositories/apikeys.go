@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey, hash []byte) error
+	// GetActiveByHash returns the key with the given hash unless it has
+	// been revoked.
+	GetActiveByHash(ctx context.Context, hash []byte) (*models.APIKey, error)
+	// Revoke revokes an active key of owner.
+	Revoke(ctx context.Context, id uuid.UUID, owner string) error
+}
+
+type PostgresAPIKeyRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresAPIKeyRepo(db *sql.DB) *PostgresAPIKeyRepo {
+	return &PostgresAPIKeyRepo{db: db}
+}
+
+func (r *PostgresAPIKeyRepo) Create(ctx context.Context, key *models.APIKey, hash []byte) error {
+	key.ID = uuid.New()
+	key.CreatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO api_keys (id, owner, workspace_id, name, prefix, key_hash, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		key.ID, key.Owner, key.WorkspaceID, key.Name, key.Prefix, hash, key.CreatedAt)
+	return err
+}
+
+func (r *PostgresAPIKeyRepo) GetActiveByHash(ctx context.Context, hash []byte) (*models.APIKey, error) {
+	var k models.APIKey
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, owner, workspace_id, name, prefix, created_at FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL", hash).
+		Scan(&k.ID, &k.Owner, &k.WorkspaceID, &k.Name, &k.Prefix, &k.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *PostgresAPIKeyRepo) Revoke(ctx context.Context, id uuid.UUID, owner string) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND owner = $2 AND revoked_at IS NULL", id, owner)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
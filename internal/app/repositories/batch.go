@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+// ErrBatchAborted is the result of the items of an all-or-nothing batch
+// that was rolled back because another item failed.
+var ErrBatchAborted = errors.New("batch aborted")
+
+// errBatchFailed rolls back the transaction of an all-or-nothing batch.
+var errBatchFailed = errors.New("batch item failed")
+
+// AbortBatch marks the items of a rolled back batch that did not fail
+// themselves with ErrBatchAborted.
+func AbortBatch(results []models.BatchResult) []models.BatchResult {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = models.BatchResult{Err: ErrBatchAborted}
+		}
+	}
+	return results
+}
+
+func (r *PostgresTaskRepo) BatchCreate(ctx context.Context, tasks []*models.Task, mode models.BatchMode) ([]models.BatchResult, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.runBatch(ctx, len(tasks), mode, func(tx *sql.Tx, i int) (*models.Task, error) {
+		if err := createTask(ctx, tx, tn, tasks[i]); err != nil {
+			return nil, err
+		}
+		return tasks[i], nil
+	})
+}
+
+func (r *PostgresTaskRepo) BatchTransition(ctx context.Context, ids []uuid.UUID, to models.Status, wf *models.Workflow, next NextOccurrence, mode models.BatchMode) ([]models.BatchResult, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.runBatch(ctx, len(ids), mode, func(tx *sql.Tx, i int) (*models.Task, error) {
+		return transitionTask(ctx, tx, tn, ids[i], to, wf, next)
+	})
+}
+
+func (r *PostgresTaskRepo) BatchDelete(ctx context.Context, ids []uuid.UUID, mode models.BatchMode) ([]models.BatchResult, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.runBatch(ctx, len(ids), mode, func(tx *sql.Tx, i int) (*models.Task, error) {
+		return nil, deleteTask(ctx, tx, tn, ids[i])
+	})
+}
+
+// runBatch runs item for each of n items in one transaction. In best-effort
+// mode every item runs in a savepoint, so that a failed one can be rolled
+// back alone.
+func (r *PostgresTaskRepo) runBatch(ctx context.Context, n int, mode models.BatchMode, item func(tx *sql.Tx, i int) (*models.Task, error)) ([]models.BatchResult, error) {
+	results := make([]models.BatchResult, n)
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		for i := range results {
+			if mode == models.BatchAllOrNothing {
+				task, err := item(tx, i)
+				results[i] = models.BatchResult{Task: task, Err: err}
+				if err != nil {
+					return errBatchFailed
+				}
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+				return err
+			}
+			task, err := item(tx, i)
+			if err != nil {
+				if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); err != nil {
+					return err
+				}
+				results[i].Err = err
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item"); err != nil {
+				return err
+			}
+			results[i].Task = task
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchFailed) {
+		return AbortBatch(results), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+var ErrInvalidCursor = errors.New("invalid page token")
+
+// Cursor is the keyset position after which the next page starts. Tasks are
+// ordered by (created_at, id), so the pair is unique even for equal timestamps.
+type Cursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uuid.UUID `json:"i"`
+}
+
+// EncodeCursor returns the page token of the tasks after t.
+func EncodeCursor(t models.Task) string {
+	data, _ := json.Marshal(Cursor{CreatedAt: t.CreatedAt, ID: t.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a page token of EncodeCursor.
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
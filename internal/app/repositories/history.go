@@ -0,0 +1,266 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/lib/pq"
+)
+
+// HistoryRepository reads the task history of the workspace of the principal
+// of ctx. It is written by the TaskRepository, see recordHistory.
+type HistoryRepository interface {
+	// Events returns one page of the entries selected by q, newest first.
+	// With q.Visible and a task that is not visible to the principal it
+	// fails with ErrNotFound.
+	Events(ctx context.Context, q models.HistoryQuery) (*models.TaskEventPage, error)
+}
+
+// historyEvents are the event types recorded in the history of a task. The
+// others are not changes of the task.
+var historyEvents = map[string]bool{
+	kafka.EventTaskCreated:       true,
+	kafka.EventTaskUpdated:       true,
+	kafka.EventTaskDone:          true,
+	kafka.EventTaskStatusChanged: true,
+	kafka.EventTaskDeleted:       true,
+	kafka.EventTaskRestored:      true,
+}
+
+// NewTaskEvent returns the history entry of event, a change of a task from
+// before to after.
+func NewTaskEvent(event kafka.Event, before, after *models.Task) (*models.TaskEvent, error) {
+	changes, err := diffTasks(before, after)
+	if err != nil {
+		return nil, err
+	}
+	task := after
+	if task == nil {
+		task = before
+	}
+	return &models.TaskEvent{
+		TaskID:      task.ID,
+		WorkspaceID: task.WorkspaceID,
+		Type:        event.Type,
+		Actor:       event.Actor,
+		OccurredAt:  event.OccurredAt,
+		Changes:     changes,
+	}, nil
+}
+
+// recordHistory stores the history entry of event in tx, the transaction of
+// the change.
+func recordHistory(ctx context.Context, tx *sql.Tx, event kafka.Event, before, after *models.Task) error {
+	e, err := NewTaskEvent(event, before, after)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO task_events (task_id, workspace_id, type, actor, occurred_at, changes)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		e.TaskID, e.WorkspaceID, e.Type, e.Actor, e.OccurredAt, changes)
+	return err
+}
+
+// diffTasks lists the fields that differ between two snapshots of a task, in
+// the order of their names. A missing snapshot has no fields set, so that a
+// created task lists the fields it was created with.
+func diffTasks(before, after *models.Task) ([]models.FieldChange, error) {
+	old, err := taskFields(before)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := taskFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range cur {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	changes := []models.FieldChange{}
+	for _, name := range names {
+		if !bytes.Equal(old[name], cur[name]) {
+			changes = append(changes, models.FieldChange{Field: name, Before: old[name], After: cur[name]})
+		}
+	}
+	return changes, nil
+}
+
+// taskFields returns the JSON fields of t, leaving out the unset ones: the
+// ones that are empty or have the value of the zero task. The version is left
+// out too, since every change increments it.
+func taskFields(t *models.Task) (map[string]json.RawMessage, error) {
+	fields, err := jsonFields(t)
+	if err != nil || t == nil {
+		return map[string]json.RawMessage{}, err
+	}
+	delete(fields, "version")
+	zero, err := jsonFields(&models.Task{})
+	if err != nil {
+		return nil, err
+	}
+	for name, v := range fields {
+		if s := string(v); s == "null" || s == "[]" || bytes.Equal(v, zero[name]) {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+func jsonFields(t *models.Task) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// EncodeEventCursor returns the page token of the entries older than id.
+func EncodeEventCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func DecodeEventCursor(token string) (int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+type PostgresHistoryRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresHistoryRepo(db *sql.DB) *PostgresHistoryRepo {
+	return &PostgresHistoryRepo{db: db}
+}
+
+func (r *PostgresHistoryRepo) Events(ctx context.Context, q models.HistoryQuery) (*models.TaskEventPage, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conds = append(conds, "workspace_id = "+arg(tn.WorkspaceID))
+	if q.TaskID != nil {
+		conds = append(conds, "task_id = "+arg(*q.TaskID))
+	}
+	if q.Actor != "" {
+		conds = append(conds, "actor = "+arg(q.Actor))
+	}
+	if len(q.Types) > 0 {
+		conds = append(conds, "type = ANY("+arg(pq.Array(q.Types))+"::text[])")
+	}
+	if q.Since != nil {
+		conds = append(conds, "occurred_at >= "+arg(*q.Since))
+	}
+	if q.Until != nil {
+		conds = append(conds, "occurred_at < "+arg(*q.Until))
+	}
+	if q.PageToken != "" {
+		id, err := DecodeEventCursor(q.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, "id < "+arg(id))
+	}
+
+	var db queryer = r.db
+	if q.TaskID != nil && q.Visible {
+		// The check sees the same snapshot as the read, so a user who lost
+		// access to the task before it cannot read its history.
+		tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+		var visible bool
+		err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND "+visibleTo("$2", "$3")+")",
+			*q.TaskID, tn.OwnerID, tn.WorkspaceID).Scan(&visible)
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			return nil, ErrNotFound
+		}
+		db = tx
+	}
+
+	// One extra row tells whether there is a next page.
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, task_id, workspace_id, type, actor, occurred_at, changes
+		FROM task_events
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY id DESC
+		LIMIT `+arg(q.PageSize+1), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := []models.TaskEvent{}
+	for rows.Next() {
+		var (
+			e       models.TaskEvent
+			changes []byte
+		)
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.WorkspaceID, &e.Type, &e.Actor, &e.OccurredAt, &changes); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return EventPage(events, q.PageSize), nil
+}
+
+// EventPage cuts events, read with one extra entry, to a page of size.
+func EventPage(events []models.TaskEvent, size int) *models.TaskEventPage {
+	page := &models.TaskEventPage{Events: events}
+	if len(events) > size {
+		page.Events = events[:size]
+		page.NextPageToken = EncodeEventCursor(page.Events[size-1].ID)
+	}
+	return page
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+func TestDiffTasks(t *testing.T) {
+	due := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	before := &models.Task{ID: uuid.New(), Title: "Отчёт", Status: models.StatusTodo, DueAt: &due}
+
+	t.Run("изменённые и снятые поля по имени", func(t *testing.T) {
+		after := *before
+		after.Title, after.DueAt = "Годовой отчёт", nil
+		after.Items = []models.TaskItem{{Text: "Собрать цифры"}}
+
+		changes, err := diffTasks(before, &after)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(changes) != 3 {
+			t.Fatalf("ожидалось три изменения, получено %+v", changes)
+		}
+		if c := changes[0]; c.Field != "due_at" || string(c.Before) != `"2024-03-01T09:00:00Z"` || c.After != nil {
+			t.Errorf("неожиданное изменение срока: %s %s → %s", c.Field, c.Before, c.After)
+		}
+		if c := changes[1]; c.Field != "items" || c.Before != nil || c.After == nil {
+			t.Errorf("неожиданное изменение пунктов: %s %s → %s", c.Field, c.Before, c.After)
+		}
+		if c := changes[2]; c.Field != "title" || string(c.Before) != `"Отчёт"` || string(c.After) != `"Годовой отчёт"` {
+			t.Errorf("неожиданное изменение заголовка: %s %s → %s", c.Field, c.Before, c.After)
+		}
+	})
+
+	t.Run("созданная задача сравнивается с пустой", func(t *testing.T) {
+		changes, err := diffTasks(nil, before)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		fields := map[string]bool{}
+		for _, c := range changes {
+			fields[c.Field] = true
+		}
+		if len(fields) != 4 || !fields["id"] || !fields["title"] || !fields["status"] || !fields["due_at"] {
+			t.Errorf("ожидались только заданные поля, получено %v", fields)
+		}
+	})
+
+	t.Run("без изменений", func(t *testing.T) {
+		changes, err := diffTasks(before, before)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("ожидался пустой список, получено %+v", changes)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrIdempotencyKeyLost means the reservation of a key is gone, because it
+// expired and the key was released or reserved again.
+var ErrIdempotencyKeyLost = errors.New("idempotency key is no longer reserved by the request")
+
+// IdempotencyStore remembers the responses to the requests made with an
+// idempotency key, so that a retry gets the original response instead of
+// repeating the change. Keys belong to the principal of ctx: the same key
+// of another user is another key.
+type IdempotencyStore interface {
+	// Reserve claims key for a request with fingerprint until ttl passes or
+	// the request completes. The token tells the reservation from later
+	// ones of the same key. If the key is taken, the record of the request
+	// that took it is returned and nothing changes.
+	Reserve(ctx context.Context, key, fingerprint, token string, ttl time.Duration) (*IdempotencyRecord, error)
+	// Complete stores the response to the request that reserved key with
+	// fingerprint and token, and keeps it for ttl. If the key is not
+	// reserved by that request anymore, it fails with ErrIdempotencyKeyLost
+	// and changes nothing.
+	Complete(ctx context.Context, key, fingerprint, token string, response []byte, ttl time.Duration) error
+	// Release frees a key reserved with fingerprint and token, so that the
+	// request may be retried. A key reserved by another request is left as
+	// it is.
+	Release(ctx context.Context, key, fingerprint, token string) error
+}
+
+// IdempotencyRecord is a request made with an idempotency key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request, so that a key reused for another
+	// request can be told from a retry.
+	Fingerprint string `json:"fingerprint"`
+	// Token is the one of the reservation, see IdempotencyStore.Reserve.
+	Token string `json:"token"`
+	// Response is nil while the request is running.
+	Response []byte `json:"response,omitempty"`
+}
+
+// IdempotencyKey namespaces key by the principal of ctx.
+func IdempotencyKey(ctx context.Context, key string) (string, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "ws:" + tn.WorkspaceID + ":idempotency:" + tn.OwnerID + ":" + key, nil
+}
+
+// The scripts of RedisIdempotencyStore change the record at KEYS[1] only if
+// it is still ARGV[1], the pending record of the reservation.
+var (
+	completeIdempotencyScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+	releaseIdempotencyScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+end
+return 1
+`)
+)
+
+// RedisIdempotencyStore keeps the records in Redis, expiring with their ttl.
+type RedisIdempotencyStore struct {
+	rdb *redis.Client
+}
+
+func NewRedisIdempotencyStore(rdb *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{rdb: rdb}
+}
+
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key, fingerprint, token string, ttl time.Duration) (*IdempotencyRecord, error) {
+	k, err := IdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(IdempotencyRecord{Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return nil, err
+	}
+
+	// SET NX GET returns the record that was there, if any.
+	prev, err := s.rdb.SetArgs(ctx, k, data, redis.SetArgs{Mode: "NX", Get: true, TTL: ttl}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec IdempotencyRecord
+	if err := json.Unmarshal([]byte(prev), &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key, fingerprint, token string, response []byte, ttl time.Duration) error {
+	k, err := IdempotencyKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	pending, err := json.Marshal(IdempotencyRecord{Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(IdempotencyRecord{Fingerprint: fingerprint, Token: token, Response: response})
+	if err != nil {
+		return err
+	}
+	stored, err := completeIdempotencyScript.Run(ctx, s.rdb, []string{k}, pending, data, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if stored == 0 {
+		return ErrIdempotencyKeyLost
+	}
+	return nil
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key, fingerprint, token string) error {
+	k, err := IdempotencyKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	pending, err := json.Marshal(IdempotencyRecord{Fingerprint: fingerprint, Token: token})
+	if err != nil {
+		return err
+	}
+	return releaseIdempotencyScript.Run(ctx, s.rdb, []string{k}, pending).Err()
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrItemNotFound = errors.New("item not found")
+	// ErrInvalidItemOrder is returned by ReorderItems for a list of IDs that
+	// is not a permutation of the items of the task.
+	ErrInvalidItemOrder = errors.New("item ids must list every item of the task once")
+)
+
+// itemColumns lists the columns read by loadItems, in order.
+const itemColumns = "id, task_id, position, text, done, created_at"
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// loadItems sets the items of each of tasks, ordered by position.
+func loadItems(ctx context.Context, q queryer, tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*models.Task, len(tasks))
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		t.Items = []models.TaskItem{}
+		byID[t.ID] = t
+		ids = append(ids, t.ID.String())
+	}
+
+	rows, err := q.QueryContext(ctx,
+		"SELECT "+itemColumns+" FROM task_items WHERE task_id = ANY($1::uuid[]) ORDER BY task_id, position", pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var it models.TaskItem
+		if err := rows.Scan(&it.ID, &it.TaskID, &it.Position, &it.Text, &it.Done, &it.CreatedAt); err != nil {
+			return err
+		}
+		t := byID[it.TaskID]
+		t.Items = append(t.Items, it)
+	}
+	return rows.Err()
+}
+
+// changeItems runs fn on a locked task visible to the principal of ctx,
+// increments its version and records the change of its items as a
+// task.updated event. Then it completes the task if AutoCompletes says so
+// for wf.
+func (r *PostgresTaskRepo) changeItems(ctx context.Context, taskID uuid.UUID, wf *models.Workflow, next NextOccurrence, fn func(tx *sql.Tx, task *models.Task) error) (*models.Task, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var after *models.Task
+	err = r.withTx(ctx, func(tx *sql.Tx) error {
+		before, err := lockTask(ctx, tx, tn, taskID)
+		if err != nil {
+			return err
+		}
+		if err := loadItems(ctx, tx, before); err != nil {
+			return err
+		}
+		if err := fn(tx, before); err != nil {
+			return err
+		}
+		after, err = scanTask(tx.QueryRowContext(ctx,
+			"UPDATE tasks SET version = version + 1 WHERE id = $1 RETURNING "+taskColumns, taskID))
+		if err != nil {
+			return err
+		}
+		if err := loadItems(ctx, tx, after); err != nil {
+			return err
+		}
+		if err := enqueueEvent(ctx, tx, kafka.EventTaskUpdated, taskID, before, after); err != nil {
+			return err
+		}
+		if !AutoCompletes(after, wf) {
+			return nil
+		}
+		after, err = moveTask(ctx, tx, tn, after, models.StatusDone, wf, next)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// AddItem appends item to the items of a task.
+func (r *PostgresTaskRepo) AddItem(ctx context.Context, taskID uuid.UUID, item *models.TaskItem) (*models.Task, error) {
+	return r.changeItems(ctx, taskID, nil, nil, func(tx *sql.Tx, task *models.Task) error {
+		item.ID = uuid.New()
+		item.TaskID = taskID
+		item.Position = len(task.Items)
+		item.CreatedAt = time.Now()
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO task_items ("+itemColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+			item.ID, item.TaskID, item.Position, item.Text, item.Done, item.CreatedAt)
+		return err
+	})
+}
+
+func (r *PostgresTaskRepo) UpdateItem(ctx context.Context, taskID, itemID uuid.UUID, patch models.TaskItemPatch, wf *models.Workflow, next NextOccurrence) (*models.Task, error) {
+	return r.changeItems(ctx, taskID, wf, next, func(tx *sql.Tx, task *models.Task) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE task_items SET text = COALESCE($3, text), done = COALESCE($4, done)
+			WHERE id = $1 AND task_id = $2`,
+			itemID, taskID, patch.Text, patch.Done)
+		if err != nil {
+			return err
+		}
+		return expectAffected(res, ErrItemNotFound)
+	})
+}
+
+func (r *PostgresTaskRepo) ReorderItems(ctx context.Context, taskID uuid.UUID, itemIDs []uuid.UUID) (*models.Task, error) {
+	return r.changeItems(ctx, taskID, nil, nil, func(tx *sql.Tx, task *models.Task) error {
+		if !IsPermutation(task.Items, itemIDs) {
+			return ErrInvalidItemOrder
+		}
+		ids := make([]string, len(itemIDs))
+		for i, id := range itemIDs {
+			ids[i] = id.String()
+		}
+		// The unique position constraint is deferred, so the intermediate
+		// duplicates are fine.
+		_, err := tx.ExecContext(ctx, `
+			UPDATE task_items SET position = v.ord - 1
+			FROM unnest($2::uuid[]) WITH ORDINALITY AS v(id, ord)
+			WHERE task_items.task_id = $1 AND task_items.id = v.id`,
+			taskID, pq.Array(ids))
+		return err
+	})
+}
+
+// RemoveItem deletes an item and moves the items after it up.
+func (r *PostgresTaskRepo) RemoveItem(ctx context.Context, taskID, itemID uuid.UUID, wf *models.Workflow, next NextOccurrence) (*models.Task, error) {
+	return r.changeItems(ctx, taskID, wf, next, func(tx *sql.Tx, task *models.Task) error {
+		var position int
+		err := tx.QueryRowContext(ctx,
+			"DELETE FROM task_items WHERE id = $1 AND task_id = $2 RETURNING position", itemID, taskID).Scan(&position)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE task_items SET position = position - 1 WHERE task_id = $1 AND position > $2", taskID, position)
+		return err
+	})
+}
+
+// AutoCompletes reports whether task, after a change of its items, is to be
+// moved to models.StatusDone: it asks for it, all its items are done and wf
+// allows it. Tasks the workflow does not let become done are left as they
+// are.
+func AutoCompletes(task *models.Task, wf *models.Workflow) bool {
+	return wf != nil && task.AutoComplete && !task.Done && task.AllItemsDone() && wf.Allows(task.Status, models.StatusDone)
+}
+
+// IsPermutation reports whether ids lists the ID of every one of items
+// exactly once.
+func IsPermutation(items []models.TaskItem, ids []uuid.UUID) bool {
+	if len(items) != len(ids) {
+		return false
+	}
+	pending := make(map[uuid.UUID]bool, len(items))
+	for _, it := range items {
+		pending[it.ID] = true
+	}
+	for _, id := range ids {
+		if !pending[id] {
+			return false
+		}
+		delete(pending, id)
+	}
+	return true
+}
+
+// expectAffected returns notFound if res affected no rows.
+func expectAffected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+)
+
+// HistoryRepo is an in-memory repositories.HistoryRepository for the
+// history kept by a TaskRepo.
+type HistoryRepo struct {
+	tasks *TaskRepo
+}
+
+func NewHistoryRepo(tasks *TaskRepo) *HistoryRepo {
+	return &HistoryRepo{tasks: tasks}
+}
+
+func (r *HistoryRepo) Events(ctx context.Context, q models.HistoryQuery) (*models.TaskEventPage, error) {
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var before int64
+	if q.PageToken != "" {
+		if before, err = repositories.DecodeEventCursor(q.PageToken); err != nil {
+			return nil, err
+		}
+	}
+
+	r.tasks.mu.RLock()
+	defer r.tasks.mu.RUnlock()
+
+	if q.TaskID != nil && q.Visible {
+		if _, err := r.tasks.lookup(ctx, *q.TaskID); err != nil {
+			return nil, err
+		}
+	}
+	events := []models.TaskEvent{}
+	for i := len(r.tasks.history) - 1; i >= 0 && len(events) <= q.PageSize; i-- {
+		e := r.tasks.history[i]
+		switch {
+		case e.WorkspaceID != tn.WorkspaceID,
+			q.TaskID != nil && e.TaskID != *q.TaskID,
+			q.Actor != "" && e.Actor != q.Actor,
+			len(q.Types) > 0 && !slices.Contains(q.Types, e.Type),
+			q.Since != nil && e.OccurredAt.Before(*q.Since),
+			q.Until != nil && !e.OccurredAt.Before(*q.Until),
+			before != 0 && e.ID >= before:
+			continue
+		}
+		events = append(events, e)
+	}
+	return repositories.EventPage(events, q.PageSize), nil
+}
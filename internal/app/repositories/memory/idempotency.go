@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+)
+
+// IdempotencyStore is an in-memory repositories.IdempotencyStore. Records
+// do not expire.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]repositories.IdempotencyRecord
+}
+
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{records: make(map[string]repositories.IdempotencyRecord)}
+}
+
+func (s *IdempotencyStore) Reserve(ctx context.Context, key, fingerprint, token string, ttl time.Duration) (*repositories.IdempotencyRecord, error) {
+	k, err := repositories.IdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[k]; ok {
+		return &rec, nil
+	}
+	s.records[k] = repositories.IdempotencyRecord{Fingerprint: fingerprint, Token: token}
+	return nil, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key, fingerprint, token string, response []byte, ttl time.Duration) error {
+	k, err := repositories.IdempotencyKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.reservedBy(k, fingerprint, token) {
+		return repositories.ErrIdempotencyKeyLost
+	}
+	s.records[k] = repositories.IdempotencyRecord{Fingerprint: fingerprint, Token: token, Response: response}
+	return nil
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key, fingerprint, token string) error {
+	k, err := repositories.IdempotencyKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reservedBy(k, fingerprint, token) {
+		delete(s.records, k)
+	}
+	return nil
+}
+
+// reservedBy reports whether k is pending for the request with fingerprint
+// and token. The caller must hold s.mu.
+func (s *IdempotencyStore) reservedBy(k, fingerprint, token string) bool {
+	rec, ok := s.records[k]
+	return ok && rec.Response == nil && rec.Fingerprint == fingerprint && rec.Token == token
+}
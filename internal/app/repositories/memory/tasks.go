@@ -0,0 +1,773 @@
+// Package memory implements the repositories in memory, for the tests of
+// the services and servers that use them. Nothing in it is meant for
+// production.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	"github.com/kalpovskii/checklist/internal/kafka"
+)
+
+// TaskRepo is an in-memory repositories.TaskRepository. Search does plain
+// token matching instead of Postgres full-text search, events are only
+// recorded in the history since there is no outbox, and the trash is never
+// purged.
+type TaskRepo struct {
+	mu    sync.RWMutex
+	tasks map[uuid.UUID]models.Task
+	// collaborators of each task by user ID, see PermissionRepo.
+	collaborators map[uuid.UUID]map[string]models.Collaborator
+	// items of each task by position. Tasks in the tasks map have no items.
+	items map[uuid.UUID][]models.TaskItem
+	// occurrences holds the next occurrence created for each recurring task.
+	occurrences map[uuid.UUID]uuid.UUID
+	// history of every task, oldest first, see HistoryRepo.
+	history []models.TaskEvent
+}
+
+func NewTaskRepo() *TaskRepo {
+	return &TaskRepo{
+		tasks:         make(map[uuid.UUID]models.Task),
+		collaborators: make(map[uuid.UUID]map[string]models.Collaborator),
+		items:         make(map[uuid.UUID][]models.TaskItem),
+		occurrences:   make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+// withItems returns t with a copy of its items. The caller must hold r.mu.
+func (r *TaskRepo) withItems(t models.Task) models.Task {
+	t.Items = append([]models.TaskItem{}, r.items[t.ID]...)
+	return t
+}
+
+// record adds the change of a task from before to after to the history.
+// The caller must hold r.mu.
+func (r *TaskRepo) record(ctx context.Context, eventType string, before, after *models.Task) error {
+	task := after
+	if task == nil {
+		task = before
+	}
+	e, err := repositories.NewTaskEvent(repositories.NewEvent(ctx, eventType, task.ID), before, after)
+	if err != nil {
+		return err
+	}
+	e.ID = int64(len(r.history) + 1)
+	r.history = append(r.history, *e)
+	return nil
+}
+
+// visible reports whether tn owns or collaborates on t and t is not in the
+// trash. The caller must hold r.mu.
+func (r *TaskRepo) visible(tn repositories.Tenant, t models.Task) bool {
+	if t.WorkspaceID != tn.WorkspaceID || t.DeletedAt != nil {
+		return false
+	}
+	_, shared := r.collaborators[t.ID][tn.OwnerID]
+	return t.OwnerID == tn.OwnerID || shared
+}
+
+func (r *TaskRepo) Create(ctx context.Context, task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.create(ctx, task)
+}
+
+// create is Create. The caller must hold r.mu for writing.
+func (r *TaskRepo) create(ctx context.Context, task *models.Task) error {
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	task.ID = uuid.New()
+	task.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	task.OwnerID, task.WorkspaceID = tn.OwnerID, tn.WorkspaceID
+	if task.Status == "" {
+		task.Status = models.StatusTodo
+	}
+	task.Done = task.Status == models.StatusDone
+	task.Version = 1
+	task.Items = []models.TaskItem{}
+	stored := *task
+	stored.Items = nil
+	r.tasks[task.ID] = stored
+	return r.record(ctx, kafka.EventTaskCreated, nil, task)
+}
+
+func (r *TaskRepo) List(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *repositories.Cursor
+	if q.PageToken != "" {
+		c, err := repositories.DecodeCursor(q.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	tasks := []models.Task{}
+	for _, t := range r.tasks {
+		if q.Trashed && (t.DeletedAt == nil || !tn.Owns(t)) {
+			continue
+		}
+		if !q.Trashed && !r.visible(tn, t) {
+			continue
+		}
+		if q.Done != nil && t.Done != *q.Done {
+			continue
+		}
+		if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, t.Status) {
+			continue
+		}
+		if q.CreatedAfter != nil && t.CreatedAt.Before(*q.CreatedAfter) {
+			continue
+		}
+		if q.CreatedBefore != nil && !t.CreatedAt.Before(*q.CreatedBefore) {
+			continue
+		}
+		if q.Overdue && (t.Status.Closed() || t.DueAt == nil || t.DueAt.After(now)) {
+			continue
+		}
+		if q.DueWithin > 0 && (t.Status.Closed() || t.DueAt == nil || !t.DueAt.After(now) || t.DueAt.After(now.Add(q.DueWithin))) {
+			continue
+		}
+		if after != nil && !keysetAfter(t, after, q.Descending) {
+			continue
+		}
+		tasks = append(tasks, r.withItems(t))
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return keysetLess(tasks[i], tasks[j]) != q.Descending
+	})
+
+	page := &models.TaskPage{Tasks: tasks}
+	if len(tasks) > q.PageSize {
+		page.Tasks = tasks[:q.PageSize]
+		page.NextPageToken = repositories.EncodeCursor(page.Tasks[q.PageSize-1])
+	}
+	return page, nil
+}
+
+func keysetLess(a, b models.Task) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+func keysetAfter(t models.Task, c *repositories.Cursor, desc bool) bool {
+	pos := models.Task{ID: c.ID, CreatedAt: c.CreatedAt}
+	if desc {
+		return keysetLess(t, pos)
+	}
+	return keysetLess(pos, t)
+}
+
+// lookup returns the task with the given id if it is visible to the
+// principal of ctx, with its items. The caller must hold r.mu.
+func (r *TaskRepo) lookup(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return models.Task{}, err
+	}
+	t, ok := r.tasks[id]
+	if !ok || !r.visible(tn, t) {
+		return models.Task{}, repositories.ErrNotFound
+	}
+	return r.withItems(t), nil
+}
+
+// lookupForUpdate is lookup for a change of the task, which the principal
+// must be an editor of and must be at the version expected by ctx. The
+// caller must hold r.mu for writing.
+func (r *TaskRepo) lookupForUpdate(ctx context.Context, id uuid.UUID) (models.Task, error) {
+	if err := r.checkRole(ctx, id, models.RoleEditor); err != nil {
+		return models.Task{}, err
+	}
+	t, err := r.lookup(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	return t, repositories.CheckVersion(ctx, &t)
+}
+
+// store saves t without its items. The caller must hold r.mu.
+func (r *TaskRepo) store(t models.Task) {
+	t.Items = nil
+	r.tasks[t.ID] = t
+}
+
+func (r *TaskRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, err := r.lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TaskRepo) Update(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.lookupForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := t
+	t.Version++
+	if patch.Title != nil {
+		t.Title = *patch.Title
+	}
+	if patch.Content != nil {
+		t.Content = *patch.Content
+	}
+	if patch.AutoComplete != nil {
+		t.AutoComplete = *patch.AutoComplete
+	}
+	if patch.DueAt != nil {
+		_, t.DueAt = repositories.TimePatch(patch.DueAt)
+	}
+	if patch.RemindAt != nil {
+		_, t.RemindAt = repositories.TimePatch(patch.RemindAt)
+	}
+	if patch.Recurrence != nil {
+		t.Recurrence = *patch.Recurrence
+	}
+	if patch.TimeZone != nil {
+		t.TimeZone = *patch.TimeZone
+	}
+	if t.Recurrence != "" && t.DueAt == nil {
+		return nil, repositories.ErrRecurrenceWithoutDue
+	}
+	r.store(t)
+	if err := r.record(ctx, kafka.EventTaskUpdated, &before, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TaskRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.trash(ctx, id)
+}
+
+// trash is Delete. The caller must hold r.mu for writing.
+func (r *TaskRepo) trash(ctx context.Context, id uuid.UUID) error {
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := r.checkRole(ctx, id, models.RoleOwner); err != nil {
+		return err
+	}
+	t, ok := r.tasks[id]
+	if !ok || !tn.Owns(t) || t.DeletedAt != nil {
+		return repositories.ErrNotFound
+	}
+	if err := repositories.CheckVersion(ctx, &t); err != nil {
+		return err
+	}
+	before := r.withItems(t)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	t.DeletedAt = &now
+	t.Version++
+	r.tasks[id] = t
+	t = r.withItems(t)
+	return r.record(ctx, kafka.EventTaskDeleted, &before, &t)
+}
+
+func (r *TaskRepo) Restore(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := r.tasks[id]
+	if !ok || !tn.Owns(t) || t.DeletedAt == nil {
+		return nil, repositories.ErrNotFound
+	}
+	if err := repositories.CheckVersion(ctx, &t); err != nil {
+		return nil, err
+	}
+	before := r.withItems(t)
+	t.DeletedAt = nil
+	t.Version++
+	r.tasks[id] = t
+	t = r.withItems(t)
+	if err := r.record(ctx, kafka.EventTaskRestored, &before, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TaskRepo) Transition(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.transition(ctx, id, to, wf, next)
+}
+
+// transition is Transition. The caller must hold r.mu for writing.
+func (r *TaskRepo) transition(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+	t, err := r.lookupForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.move(ctx, t, to, wf, next)
+}
+
+func (r *TaskRepo) Reopen(ctx context.Context, id uuid.UUID, wf *models.Workflow) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, err := r.lookupForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Status.Closed() {
+		return &t, nil
+	}
+	return r.move(ctx, t, models.StatusTodo, wf, nil)
+}
+
+// move is transition of t, looked up by lookupForUpdate. The caller must
+// hold r.mu for writing.
+func (r *TaskRepo) move(ctx context.Context, t models.Task, to models.Status, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+	id := t.ID
+	if !wf.Allows(t.Status, to) {
+		return nil, fmt.Errorf("%w: from %s to %s", repositories.ErrTransitionNotAllowed, t.Status, to)
+	}
+	if t.Status == to {
+		return &t, nil
+	}
+	if _, created := r.tasks[r.occurrences[id]]; next != nil && to == models.StatusDone && !created {
+		// Ask first, so that nothing changes if next fails.
+		task, err := repositories.Occurrence(&t, next)
+		if err != nil {
+			return nil, err
+		}
+		if task != nil {
+			if err := r.record(ctx, kafka.EventTaskCreated, nil, task); err != nil {
+				return nil, err
+			}
+			r.store(*task)
+			r.items[task.ID] = task.Items
+			r.occurrences[id] = task.ID
+			if shared := r.collaborators[id]; len(shared) > 0 {
+				copied := make(map[string]models.Collaborator, len(shared))
+				for user, c := range shared {
+					c.TaskID = task.ID
+					copied[user] = c
+				}
+				r.collaborators[task.ID] = copied
+			}
+		}
+	}
+	before := t
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	t.Status, t.Done = to, to == models.StatusDone
+	t.StatusChangedAt, t.StatusChangedBy = &now, auth.FromContext(ctx).Subject
+	t.Version++
+	r.store(t)
+	eventType := kafka.EventTaskStatusChanged
+	if to == models.StatusDone {
+		eventType = kafka.EventTaskDone
+	}
+	if err := r.record(ctx, eventType, &before, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TaskRepo) BatchCreate(ctx context.Context, tasks []*models.Task, mode models.BatchMode) ([]models.BatchResult, error) {
+	return r.runBatch(len(tasks), mode, func(i int) (*models.Task, error) {
+		if err := r.create(ctx, tasks[i]); err != nil {
+			return nil, err
+		}
+		return tasks[i], nil
+	})
+}
+
+func (r *TaskRepo) BatchTransition(ctx context.Context, ids []uuid.UUID, to models.Status, wf *models.Workflow, next repositories.NextOccurrence, mode models.BatchMode) ([]models.BatchResult, error) {
+	return r.runBatch(len(ids), mode, func(i int) (*models.Task, error) {
+		return r.transition(ctx, ids[i], to, wf, next)
+	})
+}
+
+func (r *TaskRepo) BatchDelete(ctx context.Context, ids []uuid.UUID, mode models.BatchMode) ([]models.BatchResult, error) {
+	return r.runBatch(len(ids), mode, func(i int) (*models.Task, error) {
+		return nil, r.trash(ctx, ids[i])
+	})
+}
+
+// checkRole checks the role of the principal of ctx on a task like
+// repositories.PostgresTaskRepo does. The caller must hold r.mu.
+func (r *TaskRepo) checkRole(ctx context.Context, id uuid.UUID, need models.Role) error {
+	t, err := r.lookup(ctx, id)
+	if err != nil {
+		return err
+	}
+	role := models.RoleOwner
+	if user := auth.FromContext(ctx).Subject; t.OwnerID != user {
+		role = r.collaborators[id][user].Role
+	}
+	if !role.Includes(need) {
+		return fmt.Errorf("%w: %s role required, have %s", repositories.ErrPermissionDenied, need, role)
+	}
+	return nil
+}
+
+// runBatch runs item for each of n items under r.mu, undoing the changes of
+// failed items like repositories.PostgresTaskRepo rolls them back.
+func (r *TaskRepo) runBatch(n int, mode models.BatchMode, item func(i int) (*models.Task, error)) ([]models.BatchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]models.BatchResult, n)
+	batch := r.snapshot()
+	for i := range results {
+		saved := r.snapshot()
+		task, err := item(i)
+		results[i] = models.BatchResult{Task: task, Err: err}
+		if err == nil {
+			continue
+		}
+		if mode == models.BatchAllOrNothing {
+			r.restore(batch)
+			return repositories.AbortBatch(results), nil
+		}
+		r.restore(saved)
+	}
+	return results, nil
+}
+
+// memorySnapshot is the state of a TaskRepo changed by the batch
+// methods.
+type memorySnapshot struct {
+	tasks         map[uuid.UUID]models.Task
+	collaborators map[uuid.UUID]map[string]models.Collaborator
+	items         map[uuid.UUID][]models.TaskItem
+	occurrences   map[uuid.UUID]uuid.UUID
+	history       int
+}
+
+// snapshot saves the state restored by restore. The maps are copied
+// shallowly, as the batch methods replace their values instead of changing
+// them. The caller must hold r.mu.
+func (r *TaskRepo) snapshot() memorySnapshot {
+	return memorySnapshot{
+		tasks:         maps.Clone(r.tasks),
+		collaborators: maps.Clone(r.collaborators),
+		items:         maps.Clone(r.items),
+		occurrences:   maps.Clone(r.occurrences),
+		history:       len(r.history),
+	}
+}
+
+// restore undoes the changes made since s was taken. The caller must hold
+// r.mu for writing.
+func (r *TaskRepo) restore(s memorySnapshot) {
+	r.tasks, r.collaborators, r.items, r.occurrences = s.tasks, s.collaborators, s.items, s.occurrences
+	r.history = r.history[:s.history]
+}
+
+// Search returns tasks containing every query token, ranked by the number of
+// matches with title matches counting double.
+func (r *TaskRepo) Search(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := map[string]bool{}
+	for _, tok := range tokenize(query) {
+		terms[tok] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := []models.SearchResult{}
+	if len(terms) == 0 {
+		return results, nil
+	}
+	for _, t := range r.tasks {
+		if !r.visible(tn, t) {
+			continue
+		}
+		title, content := tokenize(t.Title), tokenize(t.Content)
+		found := map[string]bool{}
+		var rank float32
+		for _, tok := range title {
+			if terms[tok] {
+				found[tok] = true
+				rank += 2
+			}
+		}
+		for _, tok := range content {
+			if terms[tok] {
+				found[tok] = true
+				rank++
+			}
+		}
+		if len(found) < len(terms) {
+			continue
+		}
+		results = append(results, models.SearchResult{
+			Task:           r.withItems(t),
+			Rank:           rank,
+			TitleSnippet:   highlight(t.Title, terms),
+			ContentSnippet: highlight(t.Content, terms),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Task.CreatedAt.After(results[j].Task.CreatedAt)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !isWordRune(r) })
+}
+
+// highlight wraps the words of s that are in terms with <b></b>.
+func highlight(s string, terms map[string]bool) string {
+	var (
+		b    strings.Builder
+		word []rune
+	)
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		if terms[strings.ToLower(string(word))] {
+			b.WriteString("<b>" + string(word) + "</b>")
+		} else {
+			b.WriteString(string(word))
+		}
+		word = word[:0]
+	}
+	for _, r := range s {
+		if isWordRune(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+	}
+	flush()
+	return b.String()
+}
+
+func (r *TaskRepo) AddItem(ctx context.Context, taskID uuid.UUID, item *models.TaskItem) (*models.Task, error) {
+	return r.changeItems(ctx, taskID, nil, nil, func(items []models.TaskItem) ([]models.TaskItem, error) {
+		item.ID = uuid.New()
+		item.TaskID = taskID
+		item.Position = len(items)
+		item.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		return append(items, *item), nil
+	})
+}
+
+func (r *TaskRepo) UpdateItem(ctx context.Context, taskID, itemID uuid.UUID, patch models.TaskItemPatch, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+	return r.changeItems(ctx, taskID, wf, next, func(items []models.TaskItem) ([]models.TaskItem, error) {
+		for i := range items {
+			if items[i].ID != itemID {
+				continue
+			}
+			if patch.Text != nil {
+				items[i].Text = *patch.Text
+			}
+			if patch.Done != nil {
+				items[i].Done = *patch.Done
+			}
+			return items, nil
+		}
+		return nil, repositories.ErrItemNotFound
+	})
+}
+
+func (r *TaskRepo) ReorderItems(ctx context.Context, taskID uuid.UUID, itemIDs []uuid.UUID) (*models.Task, error) {
+	return r.changeItems(ctx, taskID, nil, nil, func(items []models.TaskItem) ([]models.TaskItem, error) {
+		if !repositories.IsPermutation(items, itemIDs) {
+			return nil, repositories.ErrInvalidItemOrder
+		}
+		byID := make(map[uuid.UUID]models.TaskItem, len(items))
+		for _, it := range items {
+			byID[it.ID] = it
+		}
+		reordered := make([]models.TaskItem, len(itemIDs))
+		for i, id := range itemIDs {
+			reordered[i] = byID[id]
+		}
+		return reordered, nil
+	})
+}
+
+func (r *TaskRepo) RemoveItem(ctx context.Context, taskID, itemID uuid.UUID, wf *models.Workflow, next repositories.NextOccurrence) (*models.Task, error) {
+	return r.changeItems(ctx, taskID, wf, next, func(items []models.TaskItem) ([]models.TaskItem, error) {
+		for i := range items {
+			if items[i].ID == itemID {
+				return append(items[:i], items[i+1:]...), nil
+			}
+		}
+		return nil, repositories.ErrItemNotFound
+	})
+}
+
+// changeItems replaces the items of a task visible to the principal of ctx
+// with the ones fn returns, renumbering their positions, and increments its
+// version. Then it completes the task if repositories.AutoCompletes says so
+// for wf, undoing the change of the items if that fails.
+func (r *TaskRepo) changeItems(ctx context.Context, taskID uuid.UUID, wf *models.Workflow, next repositories.NextOccurrence, fn func(items []models.TaskItem) ([]models.TaskItem, error)) (*models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := r.snapshot()
+	t, err := r.lookupForUpdate(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	before := r.withItems(t)
+	items, err := fn(t.Items)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Position = i
+	}
+	r.items[taskID] = items
+	t.Version++
+	r.store(t)
+	t = r.withItems(t)
+	if err := r.record(ctx, kafka.EventTaskUpdated, &before, &t); err != nil {
+		return nil, err
+	}
+	if !repositories.AutoCompletes(&t, wf) {
+		return &t, nil
+	}
+	done, err := r.move(ctx, t, models.StatusDone, wf, next)
+	if err != nil {
+		r.restore(saved)
+		return nil, err
+	}
+	return done, nil
+}
+
+// PermissionRepo is an in-memory repositories.PermissionRepository for the
+// tasks of a TaskRepo.
+type PermissionRepo struct {
+	tasks *TaskRepo
+}
+
+func NewPermissionRepo(tasks *TaskRepo) *PermissionRepo {
+	return &PermissionRepo{tasks: tasks}
+}
+
+func (r *PermissionRepo) Role(ctx context.Context, taskID uuid.UUID) (models.Role, error) {
+	r.tasks.mu.RLock()
+	defer r.tasks.mu.RUnlock()
+
+	t, err := r.tasks.lookup(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	user := auth.FromContext(ctx).Subject
+	if t.OwnerID == user {
+		return models.RoleOwner, nil
+	}
+	return r.tasks.collaborators[taskID][user].Role, nil
+}
+
+func (r *PermissionRepo) Share(ctx context.Context, c *models.Collaborator) error {
+	r.tasks.mu.Lock()
+	defer r.tasks.mu.Unlock()
+
+	if _, err := r.tasks.lookup(ctx, c.TaskID); err != nil {
+		return err
+	}
+	c.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	byUser := r.tasks.collaborators[c.TaskID]
+	if byUser == nil {
+		byUser = make(map[string]models.Collaborator)
+		r.tasks.collaborators[c.TaskID] = byUser
+	}
+	if prev, ok := byUser[c.UserID]; ok {
+		c.CreatedAt = prev.CreatedAt
+	}
+	byUser[c.UserID] = *c
+	return nil
+}
+
+func (r *PermissionRepo) Unshare(ctx context.Context, taskID uuid.UUID, userID string) error {
+	r.tasks.mu.Lock()
+	defer r.tasks.mu.Unlock()
+
+	if _, err := r.tasks.lookup(ctx, taskID); err != nil {
+		return err
+	}
+	if _, ok := r.tasks.collaborators[taskID][userID]; !ok {
+		return repositories.ErrCollaboratorNotFound
+	}
+	delete(r.tasks.collaborators[taskID], userID)
+	return nil
+}
+
+func (r *PermissionRepo) ListCollaborators(ctx context.Context, taskID uuid.UUID) ([]models.Collaborator, error) {
+	r.tasks.mu.RLock()
+	defer r.tasks.mu.RUnlock()
+
+	if _, err := r.tasks.lookup(ctx, taskID); err != nil {
+		return nil, err
+	}
+	collaborators := []models.Collaborator{}
+	for _, c := range r.tasks.collaborators[taskID] {
+		collaborators = append(collaborators, c)
+	}
+	sort.Slice(collaborators, func(i, j int) bool {
+		a, b := collaborators[i], collaborators[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.UserID < b.UserID
+	})
+	return collaborators, nil
+}
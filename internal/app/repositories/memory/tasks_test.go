@@ -0,0 +1,670 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+)
+
+// tenantContext возвращает контекст запроса пользователя subject из
+// workspace.
+func tenantContext(subject, workspace string) context.Context {
+	return auth.NewContext(context.Background(), &auth.Principal{Subject: subject, WorkspaceID: workspace})
+}
+
+var aliceCtx = tenantContext("alice", "ws-1")
+
+func createTasks(t *testing.T, ctx context.Context, repo *TaskRepo, tasks ...models.Task) {
+	t.Helper()
+	for i := range tasks {
+		if err := repo.Create(ctx, &tasks[i]); err != nil {
+			t.Fatalf("не удалось создать задачу: %v", err)
+		}
+	}
+}
+
+func TestMemoryTaskRepo_Search(t *testing.T) {
+	repo := NewTaskRepo()
+	createTasks(t, aliceCtx, repo,
+		models.Task{Title: "Подготовить отчёт", Content: "Квартальный отчёт для команды"},
+		models.Task{Title: "Купить молоко", Content: "И хлеб"},
+		models.Task{Title: "Созвон", Content: "Обсудить отчёт"},
+	)
+
+	t.Run("совпадения в заголовке ранжируются выше", func(t *testing.T) {
+		results, err := repo.Search(aliceCtx, "отчёт", 10)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if len(results) != 2 {
+			t.Fatalf("ожидалось 2 результата, получено %d", len(results))
+		}
+
+		if results[0].Task.Title != "Подготовить отчёт" {
+			t.Errorf("первым ожидался 'Подготовить отчёт', получено '%s'", results[0].Task.Title)
+		}
+
+		if results[0].Rank <= results[1].Rank {
+			t.Errorf("ранги должны убывать: %v, %v", results[0].Rank, results[1].Rank)
+		}
+	})
+
+	t.Run("совпадения подсвечиваются", func(t *testing.T) {
+		results, err := repo.Search(aliceCtx, "ОТЧЁТ", 10)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if results[0].TitleSnippet != "Подготовить <b>отчёт</b>" {
+			t.Errorf("неожиданный сниппет заголовка: %s", results[0].TitleSnippet)
+		}
+
+		if results[0].ContentSnippet != "Квартальный <b>отчёт</b> для команды" {
+			t.Errorf("неожиданный сниппет содержимого: %s", results[0].ContentSnippet)
+		}
+	})
+
+	t.Run("все слова запроса должны совпасть", func(t *testing.T) {
+		results, err := repo.Search(aliceCtx, "отчёт команды", 10)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if len(results) != 1 || results[0].Task.Title != "Подготовить отчёт" {
+			t.Errorf("неожиданные результаты: %+v", results)
+		}
+	})
+
+	t.Run("ограничение количества результатов", func(t *testing.T) {
+		results, err := repo.Search(aliceCtx, "отчёт", 1)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if len(results) != 1 {
+			t.Errorf("ожидался 1 результат, получено %d", len(results))
+		}
+	})
+
+	t.Run("нет совпадений", func(t *testing.T) {
+		results, err := repo.Search(aliceCtx, "кофе", 10)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if len(results) != 0 {
+			t.Errorf("ожидался пустой результат, получено %d", len(results))
+		}
+	})
+}
+
+func TestMemoryTaskRepo_ListPagination(t *testing.T) {
+	repo := NewTaskRepo()
+	createTasks(t, aliceCtx, repo,
+		models.Task{Title: "1"},
+		models.Task{Title: "2", Status: models.StatusDone},
+		models.Task{Title: "3"},
+		models.Task{Title: "4", Status: models.StatusDone},
+		models.Task{Title: "5"},
+	)
+
+	t.Run("страницы не пересекаются и покрывают всё", func(t *testing.T) {
+		seen := map[string]bool{}
+		q := models.TaskListQuery{PageSize: 2}
+		pages := 0
+		for {
+			page, err := repo.List(aliceCtx, q)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			pages++
+			for _, task := range page.Tasks {
+				if seen[task.Title] {
+					t.Fatalf("задача '%s' встретилась дважды", task.Title)
+				}
+				seen[task.Title] = true
+			}
+			if page.NextPageToken == "" {
+				break
+			}
+			q.PageToken = page.NextPageToken
+		}
+
+		if pages != 3 || len(seen) != 5 {
+			t.Errorf("ожидалось 3 страницы и 5 задач, получено %d и %d", pages, len(seen))
+		}
+	})
+
+	t.Run("фильтр done", func(t *testing.T) {
+		done := true
+		page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10, Done: &done})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if len(page.Tasks) != 2 || page.NextPageToken != "" {
+			t.Errorf("ожидалось 2 выполненные задачи на одной странице, получено %+v", page)
+		}
+	})
+
+	t.Run("невалидный токен", func(t *testing.T) {
+		if _, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10, PageToken: "%%%"}); err != repositories.ErrInvalidCursor {
+			t.Errorf("ожидалась repositories.ErrInvalidCursor, получено %v", err)
+		}
+	})
+}
+
+func TestMemoryTaskRepo_ListDue(t *testing.T) {
+	repo := NewTaskRepo()
+	at := func(d time.Duration) *time.Time {
+		t := time.Now().Add(d)
+		return &t
+	}
+	createTasks(t, aliceCtx, repo,
+		models.Task{Title: "просрочена", DueAt: at(-time.Hour)},
+		models.Task{Title: "просрочена, но выполнена", DueAt: at(-time.Hour), Status: models.StatusDone},
+		models.Task{Title: "через час", DueAt: at(time.Hour)},
+		models.Task{Title: "через неделю", DueAt: at(7 * 24 * time.Hour)},
+		models.Task{Title: "без срока"},
+	)
+
+	titles := func(q models.TaskListQuery) []string {
+		t.Helper()
+		q.PageSize = 10
+		page, err := repo.List(aliceCtx, q)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		var titles []string
+		for _, task := range page.Tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	t.Run("просроченные", func(t *testing.T) {
+		if got := titles(models.TaskListQuery{Overdue: true}); len(got) != 1 || got[0] != "просрочена" {
+			t.Errorf("ожидалась только открытая просроченная задача, получено %v", got)
+		}
+	})
+
+	t.Run("скоро срок", func(t *testing.T) {
+		if got := titles(models.TaskListQuery{DueWithin: 24 * time.Hour}); len(got) != 1 || got[0] != "через час" {
+			t.Errorf("ожидалась только задача со сроком через час, получено %v", got)
+		}
+	})
+
+	t.Run("срок снимается нулевым временем", func(t *testing.T) {
+		page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10, Overdue: true})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		task, err := repo.Update(aliceCtx, page.Tasks[0].ID, models.TaskPatch{DueAt: &time.Time{}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if task.DueAt != nil {
+			t.Errorf("срок должен быть снят, получено %v", task.DueAt)
+		}
+		if got := titles(models.TaskListQuery{Overdue: true}); len(got) != 0 {
+			t.Errorf("просроченных задач не должно остаться, получено %v", got)
+		}
+	})
+}
+
+func TestMemoryTaskRepo_TenantIsolation(t *testing.T) {
+	repo := NewTaskRepo()
+	tasks := []models.Task{{Title: "Задача Алисы", Content: "отчёт"}}
+	createTasks(t, aliceCtx, repo, tasks...)
+	id := tasks[0].ID
+
+	t.Run("задача принадлежит создателю", func(t *testing.T) {
+		if tasks[0].OwnerID != "alice" || tasks[0].WorkspaceID != "ws-1" {
+			t.Errorf("неожиданный владелец: %s/%s", tasks[0].WorkspaceID, tasks[0].OwnerID)
+		}
+	})
+
+	for name, ctx := range map[string]context.Context{
+		"другой пользователь того же workspace":  tenantContext("bob", "ws-1"),
+		"тот же пользователь в другом workspace": tenantContext("alice", "ws-2"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := repo.GetByID(ctx, id); err != repositories.ErrNotFound {
+				t.Errorf("GetByID: ожидалась repositories.ErrNotFound, получено %v", err)
+			}
+
+			title := "Чужое"
+			if _, err := repo.Update(ctx, id, models.TaskPatch{Title: &title}); err != repositories.ErrNotFound {
+				t.Errorf("Update: ожидалась repositories.ErrNotFound, получено %v", err)
+			}
+
+			if _, err := repo.Transition(ctx, id, models.StatusDone, models.DefaultWorkflow("ws-1"), nil); err != repositories.ErrNotFound {
+				t.Errorf("Transition: ожидалась repositories.ErrNotFound, получено %v", err)
+			}
+
+			if err := repo.Delete(ctx, id); err != repositories.ErrNotFound {
+				t.Errorf("Delete: ожидалась repositories.ErrNotFound, получено %v", err)
+			}
+
+			page, err := repo.List(ctx, models.TaskListQuery{PageSize: 10})
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if len(page.Tasks) != 0 {
+				t.Errorf("список должен быть пуст, получено %d задач", len(page.Tasks))
+			}
+
+			results, err := repo.Search(ctx, "отчёт", 10)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if len(results) != 0 {
+				t.Errorf("поиск не должен находить чужие задачи, получено %d", len(results))
+			}
+		})
+	}
+
+	t.Run("задача не изменилась", func(t *testing.T) {
+		task, err := repo.GetByID(aliceCtx, id)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if task.Title != "Задача Алисы" || task.Done {
+			t.Errorf("задача изменена другим арендатором: %+v", task)
+		}
+	})
+
+	t.Run("без пользователя в контексте", func(t *testing.T) {
+		if _, err := repo.GetByID(context.Background(), id); err != repositories.ErrNoTenant {
+			t.Errorf("ожидалась repositories.ErrNoTenant, получено %v", err)
+		}
+		if err := repo.Create(context.Background(), &models.Task{Title: "Ничья"}); err != repositories.ErrNoTenant {
+			t.Errorf("ожидалась repositories.ErrNoTenant, получено %v", err)
+		}
+	})
+}
+
+func TestMemoryTaskRepo_Transition(t *testing.T) {
+	repo := NewTaskRepo()
+	wf := models.DefaultWorkflow("ws-1")
+	task := models.Task{Title: "Задача"}
+	createTasks(t, aliceCtx, repo, task)
+	page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10})
+	if err != nil || len(page.Tasks) != 1 {
+		t.Fatalf("не удалось прочитать задачу: %v", err)
+	}
+	id := page.Tasks[0].ID
+
+	t.Run("переход в тот же статус ничего не меняет", func(t *testing.T) {
+		got, err := repo.Transition(aliceCtx, id, models.StatusTodo, wf, nil)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.StatusChangedAt != nil || got.StatusChangedBy != "" {
+			t.Errorf("переход не должен записываться: %+v", got)
+		}
+	})
+
+	t.Run("done выставляет Done", func(t *testing.T) {
+		got, err := repo.Transition(aliceCtx, id, models.StatusDone, wf, nil)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !got.Done || got.StatusChangedBy != "alice" || got.StatusChangedAt == nil {
+			t.Errorf("неожиданная задача: %+v", got)
+		}
+	})
+
+	t.Run("переоткрытие", func(t *testing.T) {
+		got, err := repo.Reopen(aliceCtx, id, wf)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.Status != models.StatusTodo || got.Done {
+			t.Fatalf("ожидался статус todo, получено %q", got.Status)
+		}
+		if _, err := repo.Transition(aliceCtx, id, models.StatusInProgress, wf, nil); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		open, err := repo.Reopen(aliceCtx, id, wf)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if open.Status != models.StatusInProgress || open.Version != got.Version+1 {
+			t.Errorf("открытая задача не должна меняться: %+v", open)
+		}
+		if _, err := repo.Transition(aliceCtx, id, models.StatusDone, wf, nil); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	})
+
+	t.Run("переход вне процесса", func(t *testing.T) {
+		if _, err := repo.Transition(aliceCtx, id, models.StatusBlocked, wf, nil); !errors.Is(err, repositories.ErrTransitionNotAllowed) {
+			t.Errorf("done → blocked: ожидалась repositories.ErrTransitionNotAllowed, получено %v", err)
+		}
+	})
+
+	t.Run("отменённые задачи не считаются просроченными", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		overdue := models.Task{Title: "Отменена", DueAt: &past, Status: models.StatusCancelled}
+		createTasks(t, aliceCtx, repo, overdue)
+		page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10, Overdue: true})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(page.Tasks) != 0 {
+			t.Errorf("ожидался пустой список, получено %d задач", len(page.Tasks))
+		}
+		page, err = repo.List(aliceCtx, models.TaskListQuery{PageSize: 10, Statuses: []models.Status{models.StatusCancelled}})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(page.Tasks) != 1 || page.Tasks[0].Title != "Отменена" {
+			t.Errorf("ожидалась только отменённая задача, получено %+v", page.Tasks)
+		}
+	})
+}
+
+func TestMemoryTaskRepo_Trash(t *testing.T) {
+	repo := NewTaskRepo()
+	tasks := []models.Task{{Title: "Старый отчёт"}, {Title: "Новый отчёт"}}
+	createTasks(t, aliceCtx, repo, tasks...)
+	id := tasks[0].ID
+
+	if err := repo.Delete(aliceCtx, id); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	t.Run("удалённая задача скрыта", func(t *testing.T) {
+		if _, err := repo.GetByID(aliceCtx, id); err != repositories.ErrNotFound {
+			t.Errorf("GetByID: ожидалась repositories.ErrNotFound, получено %v", err)
+		}
+		if err := repo.Delete(aliceCtx, id); err != repositories.ErrNotFound {
+			t.Errorf("повторный Delete: ожидалась repositories.ErrNotFound, получено %v", err)
+		}
+		page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(page.Tasks) != 1 || page.Tasks[0].ID != tasks[1].ID {
+			t.Errorf("ожидалась только неудалённая задача, получено %+v", page.Tasks)
+		}
+		results, err := repo.Search(aliceCtx, "отчёт", 10)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(results) != 1 || results[0].Task.ID != tasks[1].ID {
+			t.Errorf("поиск не должен находить удалённые задачи, получено %d", len(results))
+		}
+	})
+
+	t.Run("корзина", func(t *testing.T) {
+		page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10, Trashed: true})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(page.Tasks) != 1 || page.Tasks[0].ID != id || page.Tasks[0].DeletedAt == nil {
+			t.Errorf("ожидалась одна задача в корзине, получено %+v", page.Tasks)
+		}
+		page, err = repo.List(tenantContext("bob", "ws-1"), models.TaskListQuery{PageSize: 10, Trashed: true})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(page.Tasks) != 0 {
+			t.Errorf("чужая корзина должна быть пуста, получено %d задач", len(page.Tasks))
+		}
+	})
+
+	t.Run("восстановление", func(t *testing.T) {
+		if _, err := repo.Restore(tenantContext("bob", "ws-1"), id); err != repositories.ErrNotFound {
+			t.Errorf("чужая задача: ожидалась repositories.ErrNotFound, получено %v", err)
+		}
+		if _, err := repo.Restore(aliceCtx, tasks[1].ID); err != repositories.ErrNotFound {
+			t.Errorf("задача не в корзине: ожидалась repositories.ErrNotFound, получено %v", err)
+		}
+		got, err := repo.Restore(aliceCtx, id)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.DeletedAt != nil || got.Title != "Старый отчёт" {
+			t.Errorf("неожиданная задача: %+v", got)
+		}
+		if _, err := repo.GetByID(aliceCtx, id); err != nil {
+			t.Errorf("восстановленная задача должна быть видна: %v", err)
+		}
+	})
+}
+
+func TestMemoryTaskRepo_Version(t *testing.T) {
+	repo := NewTaskRepo()
+	task := models.Task{Title: "Задача"}
+	createTasks(t, aliceCtx, repo, task)
+	page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10})
+	if err != nil || len(page.Tasks) != 1 {
+		t.Fatalf("не удалось прочитать задачу: %v", err)
+	}
+	id := page.Tasks[0].ID
+	if page.Tasks[0].Version != 1 {
+		t.Fatalf("новая задача должна быть в версии 1, получено %d", page.Tasks[0].Version)
+	}
+
+	expect := func(version int64) context.Context {
+		return reqmeta.NewContext(aliceCtx, reqmeta.Meta{ExpectedVersions: []int64{version}})
+	}
+	title := "Новый заголовок"
+
+	t.Run("изменение увеличивает версию", func(t *testing.T) {
+		got, err := repo.Update(expect(1), id, models.TaskPatch{Title: &title})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.Version != 2 {
+			t.Errorf("ожидалась версия 2, получено %d", got.Version)
+		}
+		got, err = repo.AddItem(aliceCtx, id, &models.TaskItem{Text: "Пункт"})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.Version != 3 {
+			t.Errorf("изменение пунктов: ожидалась версия 3, получено %d", got.Version)
+		}
+	})
+
+	t.Run("устаревшая версия", func(t *testing.T) {
+		if _, err := repo.Update(expect(1), id, models.TaskPatch{Title: &title}); !errors.Is(err, repositories.ErrVersionMismatch) {
+			t.Errorf("Update: ожидалась repositories.ErrVersionMismatch, получено %v", err)
+		}
+		if _, err := repo.Transition(expect(2), id, models.StatusDone, models.DefaultWorkflow("ws-1"), nil); !errors.Is(err, repositories.ErrVersionMismatch) {
+			t.Errorf("Transition: ожидалась repositories.ErrVersionMismatch, получено %v", err)
+		}
+		if err := repo.Delete(expect(2), id); !errors.Is(err, repositories.ErrVersionMismatch) {
+			t.Errorf("Delete: ожидалась repositories.ErrVersionMismatch, получено %v", err)
+		}
+		got, err := repo.GetByID(aliceCtx, id)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.Version != 3 || got.Status != models.StatusTodo {
+			t.Errorf("задача не должна меняться: %+v", got)
+		}
+	})
+}
+
+func TestMemoryTaskRepo_Roles(t *testing.T) {
+	repo := NewTaskRepo()
+	perms := NewPermissionRepo(repo)
+	createTasks(t, aliceCtx, repo, models.Task{Title: "Задача"})
+	page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 10})
+	if err != nil || len(page.Tasks) != 1 {
+		t.Fatalf("не удалось прочитать задачу: %v", err)
+	}
+	id := page.Tasks[0].ID
+	bobCtx := tenantContext("bob", "ws-1")
+	wf := models.DefaultWorkflow("ws-1")
+	title := "Новый заголовок"
+	share := func(role models.Role) {
+		t.Helper()
+		if err := perms.Share(aliceCtx, &models.Collaborator{TaskID: id, UserID: "bob", Role: role}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+
+	t.Run("зритель не меняет задачу", func(t *testing.T) {
+		share(models.RoleViewer)
+		if _, err := repo.Update(bobCtx, id, models.TaskPatch{Title: &title}); !errors.Is(err, repositories.ErrPermissionDenied) {
+			t.Errorf("Update: ожидалась repositories.ErrPermissionDenied, получено %v", err)
+		}
+		if _, err := repo.Transition(bobCtx, id, models.StatusDone, wf, nil); !errors.Is(err, repositories.ErrPermissionDenied) {
+			t.Errorf("Transition: ожидалась repositories.ErrPermissionDenied, получено %v", err)
+		}
+		if _, err := repo.AddItem(bobCtx, id, &models.TaskItem{Text: "Пункт"}); !errors.Is(err, repositories.ErrPermissionDenied) {
+			t.Errorf("AddItem: ожидалась repositories.ErrPermissionDenied, получено %v", err)
+		}
+	})
+
+	t.Run("редактор меняет, но не удаляет", func(t *testing.T) {
+		share(models.RoleEditor)
+		if _, err := repo.Update(bobCtx, id, models.TaskPatch{Title: &title}); err != nil {
+			t.Errorf("Update: неожиданная ошибка: %v", err)
+		}
+		if err := repo.Delete(bobCtx, id); !errors.Is(err, repositories.ErrPermissionDenied) {
+			t.Errorf("Delete: ожидалась repositories.ErrPermissionDenied, получено %v", err)
+		}
+	})
+
+	t.Run("без роли задача не найдена", func(t *testing.T) {
+		if _, err := repo.Update(tenantContext("carol", "ws-1"), id, models.TaskPatch{Title: &title}); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("ожидалась repositories.ErrNotFound, получено %v", err)
+		}
+	})
+}
+
+func TestMemoryTaskRepo_Batch(t *testing.T) {
+	repo := NewTaskRepo()
+	wf := models.DefaultWorkflow("ws-1")
+	count := func() int {
+		t.Helper()
+		page, err := repo.List(aliceCtx, models.TaskListQuery{PageSize: 100})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		return len(page.Tasks)
+	}
+
+	results, err := repo.BatchCreate(aliceCtx, []*models.Task{{Title: "Первая"}, {Title: "Вторая"}}, models.BatchAllOrNothing)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	var ids []uuid.UUID
+	for _, r := range results {
+		if r.Err != nil || r.Task == nil || r.Task.Version != 1 {
+			t.Fatalf("ожидалась созданная задача, получено %+v", r)
+		}
+		ids = append(ids, r.Task.ID)
+	}
+	missing := uuid.New()
+
+	t.Run("всё или ничего откатывает пакет", func(t *testing.T) {
+		results, err := repo.BatchTransition(aliceCtx, []uuid.UUID{ids[0], missing}, models.StatusDone, wf, nil, models.BatchAllOrNothing)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !errors.Is(results[0].Err, repositories.ErrBatchAborted) || results[0].Task != nil {
+			t.Errorf("ожидалась repositories.ErrBatchAborted, получено %+v", results[0])
+		}
+		if !errors.Is(results[1].Err, repositories.ErrNotFound) {
+			t.Errorf("ожидалась repositories.ErrNotFound, получено %v", results[1].Err)
+		}
+		got, err := repo.GetByID(aliceCtx, ids[0])
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.Status != models.StatusTodo || got.Version != 1 {
+			t.Errorf("задача не должна меняться: %+v", got)
+		}
+	})
+
+	t.Run("лучшее усилие применяет удачные элементы", func(t *testing.T) {
+		results, err := repo.BatchTransition(aliceCtx, []uuid.UUID{ids[0], missing}, models.StatusDone, wf, nil, models.BatchBestEffort)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if results[0].Err != nil || results[0].Task.Status != models.StatusDone {
+			t.Errorf("ожидалась выполненная задача, получено %+v", results[0])
+		}
+		if !errors.Is(results[1].Err, repositories.ErrNotFound) {
+			t.Errorf("ожидалась repositories.ErrNotFound, получено %v", results[1].Err)
+		}
+	})
+
+	t.Run("роль проверяется для каждой задачи", func(t *testing.T) {
+		bobCtx := tenantContext("bob", "ws-1")
+		share := &models.Collaborator{TaskID: ids[1], UserID: "bob", Role: models.RoleEditor}
+		if err := NewPermissionRepo(repo).Share(aliceCtx, share); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		results, err := repo.BatchDelete(bobCtx, []uuid.UUID{ids[1]}, models.BatchBestEffort)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !errors.Is(results[0].Err, repositories.ErrPermissionDenied) {
+			t.Errorf("ожидалась repositories.ErrPermissionDenied, получено %v", results[0].Err)
+		}
+		results, err = repo.BatchTransition(bobCtx, []uuid.UUID{ids[1], ids[0]}, models.StatusInProgress, wf, nil, models.BatchBestEffort)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if results[0].Err != nil || !errors.Is(results[1].Err, repositories.ErrNotFound) {
+			t.Errorf("ожидались успех и repositories.ErrNotFound, получено %+v", results)
+		}
+	})
+
+	t.Run("удаление", func(t *testing.T) {
+		results, err := repo.BatchDelete(aliceCtx, []uuid.UUID{ids[1], ids[1]}, models.BatchAllOrNothing)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !errors.Is(results[0].Err, repositories.ErrBatchAborted) || !errors.Is(results[1].Err, repositories.ErrNotFound) {
+			t.Errorf("повторное удаление должно откатить пакет, получено %+v", results)
+		}
+		if n := count(); n != 2 {
+			t.Fatalf("ожидалось 2 задачи, получено %d", n)
+		}
+
+		results, err = repo.BatchDelete(aliceCtx, ids, models.BatchAllOrNothing)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		for _, r := range results {
+			if r.Err != nil || r.Task != nil {
+				t.Errorf("ожидалось удаление, получено %+v", r)
+			}
+		}
+		if n := count(); n != 0 {
+			t.Errorf("ожидалось 0 задач, получено %d", n)
+		}
+	})
+
+	t.Run("откат не оставляет истории", func(t *testing.T) {
+		before := len(repo.history)
+		results, err := repo.BatchCreate(aliceCtx, []*models.Task{{Title: "Третья"}}, models.BatchAllOrNothing)
+		if err != nil || results[0].Err != nil {
+			t.Fatalf("неожиданная ошибка: %v %v", err, results)
+		}
+		results, err = repo.BatchTransition(aliceCtx, []uuid.UUID{results[0].Task.ID, missing}, models.StatusDone, wf, nil, models.BatchAllOrNothing)
+		if err != nil || !errors.Is(results[0].Err, repositories.ErrBatchAborted) {
+			t.Fatalf("ожидалась repositories.ErrBatchAborted, получено %v %+v", err, results)
+		}
+		if got := len(repo.history); got != before+1 {
+			t.Errorf("ожидалось одно новое событие, получено %d", got-before)
+		}
+	})
+}
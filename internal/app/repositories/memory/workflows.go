@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+)
+
+// WorkflowRepo is an in-memory repositories.WorkflowRepository.
+type WorkflowRepo struct {
+	mu          sync.RWMutex
+	transitions map[string][]models.Transition
+}
+
+func NewWorkflowRepo() *WorkflowRepo {
+	return &WorkflowRepo{transitions: make(map[string][]models.Transition)}
+}
+
+func (r *WorkflowRepo) Workflow(ctx context.Context) (*models.Workflow, error) {
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return repositories.NewWorkflow(tn.WorkspaceID, r.transitions[tn.WorkspaceID]), nil
+}
+
+func (r *WorkflowRepo) SetWorkflow(ctx context.Context, transitions []models.Transition) (*models.Workflow, error) {
+	tn, err := repositories.TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.transitions[tn.WorkspaceID] = slices.Clone(transitions)
+	return repositories.NewWorkflow(tn.WorkspaceID, transitions), nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("встроенные миграции загружаются по порядку", func(t *testing.T) {
+		m, err := NewMigrator(nil)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if len(m.migrations) == 0 {
+			t.Fatal("ожидалась хотя бы одна миграция")
+		}
+
+		for i, mig := range m.migrations {
+			if mig.Version != i+1 {
+				t.Errorf("версии должны идти подряд с 1: на позиции %d версия %d", i, mig.Version)
+			}
+			if mig.Up == "" || mig.Down == "" {
+				t.Errorf("у миграции %04d_%s должны быть up и down", mig.Version, mig.Name)
+			}
+		}
+	})
+
+	t.Run("пары up/down собираются по версии", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"0002_second.up.sql":   {Data: []byte("up 2")},
+			"0001_first.down.sql":  {Data: []byte("down 1")},
+			"0001_first.up.sql":    {Data: []byte("up 1")},
+			"0002_second.down.sql": {Data: []byte("down 2")},
+		}
+
+		migrations, err := loadMigrations(fsys)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if len(migrations) != 2 || migrations[0].Name != "first" || migrations[1].Name != "second" {
+			t.Fatalf("неожиданные миграции: %+v", migrations)
+		}
+
+		if migrations[0].Up != "up 1" || migrations[0].Down != "down 1" {
+			t.Errorf("неожиданное содержимое: %+v", migrations[0])
+		}
+	})
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"нет up файла":         {"0001_first.down.sql": {Data: []byte("down")}},
+		"два имени для версии": {"0001_a.up.sql": {Data: []byte("up")}, "0001_b.down.sql": {Data: []byte("down")}},
+		"лишний файл":          {"0001_first.up.sql": {Data: []byte("up")}, "README.md": {Data: []byte("")}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadMigrations(fsys); err == nil {
+				t.Fatal("ожидалась ошибка, но её не было")
+			}
+		})
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"0001_first.up.sql", "0001_first.down.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("select 1;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	up, down, err := CreateMigration(dir, "add_column")
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if filepath.Base(up) != "0002_add_column.up.sql" || filepath.Base(down) != "0002_add_column.down.sql" {
+		t.Errorf("неожиданные имена файлов: %s, %s", up, down)
+	}
+
+	if _, err := loadMigrations(os.DirFS(dir)); err != nil {
+		t.Errorf("созданные файлы должны загружаться: %v", err)
+	}
+
+	if _, _, err := CreateMigration(dir, "bad name"); err == nil {
+		t.Error("ожидалась ошибка для имени с пробелом")
+	}
+}
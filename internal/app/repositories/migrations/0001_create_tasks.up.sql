@@ -0,0 +1,11 @@
+-- IF NOT EXISTS keeps this safe for databases created before migrations,
+-- when the table was created by the DB service on startup.
+CREATE TABLE IF NOT EXISTS tasks (
+	id UUID PRIMARY KEY,
+	title TEXT NOT NULL,
+	content TEXT,
+	done BOOLEAN DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS tasks_created_at_id_idx ON tasks (created_at, id);
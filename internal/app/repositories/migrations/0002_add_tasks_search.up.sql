@@ -0,0 +1,6 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+	setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS tasks_search_vector_idx ON tasks USING GIN (search_vector);
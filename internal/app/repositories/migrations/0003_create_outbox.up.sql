@@ -0,0 +1,15 @@
+-- Events are written here in the same transaction as the task change and
+-- published to Kafka by the relay of the DB service.
+CREATE TABLE IF NOT EXISTS outbox (
+	id BIGSERIAL PRIMARY KEY,
+	event_id UUID NOT NULL UNIQUE,
+	message_key TEXT NOT NULL,
+	payload JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	attempts INT NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at, id) WHERE sent_at IS NULL;
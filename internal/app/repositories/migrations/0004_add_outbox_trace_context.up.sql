@@ -0,0 +1,3 @@
+-- W3C trace context of the request that produced the event, copied into the
+-- Kafka message headers by the relay.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS trace_context JSONB NOT NULL DEFAULT '{}'::jsonb;
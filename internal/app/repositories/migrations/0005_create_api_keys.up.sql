@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+	id UUID PRIMARY KEY,
+	owner TEXT NOT NULL,
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	-- SHA-256 of the key; the key itself is only shown once, when issued.
+	key_hash BYTEA NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_owner_idx ON api_keys (owner);
@@ -0,0 +1,6 @@
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS tasks_tenant_created_at_idx;
+ALTER TABLE tasks
+	DROP COLUMN IF EXISTS workspace_id,
+	DROP COLUMN IF EXISTS owner_id;
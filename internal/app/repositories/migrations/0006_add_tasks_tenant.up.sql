@@ -0,0 +1,10 @@
+-- Tasks created before tenancy have no owner and stay invisible until
+-- assigned to one.
+ALTER TABLE tasks
+	ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS workspace_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS tasks_tenant_created_at_idx ON tasks (workspace_id, owner_id, created_at, id);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS workspace_id TEXT NOT NULL DEFAULT '';
+UPDATE api_keys SET workspace_id = 'user:' || owner WHERE workspace_id = '';
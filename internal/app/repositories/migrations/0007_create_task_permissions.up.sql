@@ -0,0 +1,11 @@
+-- Users a task is shared with, besides its owner. Collaborators act in the
+-- workspace of the task.
+CREATE TABLE IF NOT EXISTS task_permissions (
+	task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL,
+	role TEXT NOT NULL CHECK (role IN ('viewer', 'editor')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_permissions_user_idx ON task_permissions (user_id);
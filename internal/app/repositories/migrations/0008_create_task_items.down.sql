@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_items;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS auto_complete;
@@ -0,0 +1,12 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS auto_complete BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS task_items (
+	id UUID PRIMARY KEY,
+	task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+	position INT NOT NULL,
+	text TEXT NOT NULL,
+	done BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	-- Deferred, so that reordering may swap positions within a transaction.
+	UNIQUE (task_id, position) DEFERRABLE INITIALLY DEFERRED
+);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS tasks_remind_at_idx;
+DROP INDEX IF EXISTS tasks_due_at_idx;
+
+ALTER TABLE tasks
+	DROP COLUMN IF EXISTS overdue_notified_at,
+	DROP COLUMN IF EXISTS reminded_at,
+	DROP COLUMN IF EXISTS remind_at,
+	DROP COLUMN IF EXISTS due_at;
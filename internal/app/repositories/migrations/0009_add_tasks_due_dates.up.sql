@@ -0,0 +1,11 @@
+-- reminded_at and overdue_notified_at are set by the scheduler once it has
+-- sent the event, and cleared when the time it was sent for changes.
+ALTER TABLE tasks
+	ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS remind_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS overdue_notified_at TIMESTAMPTZ;
+
+-- Only open tasks are ever due, so the indexes leave done ones out.
+CREATE INDEX IF NOT EXISTS tasks_due_at_idx ON tasks (due_at) WHERE NOT done;
+CREATE INDEX IF NOT EXISTS tasks_remind_at_idx ON tasks (remind_at) WHERE NOT done AND reminded_at IS NULL;
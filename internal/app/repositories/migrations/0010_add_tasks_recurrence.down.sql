@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+	DROP COLUMN IF EXISTS time_zone,
+	DROP COLUMN IF EXISTS recurrence;
@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+	ADD COLUMN IF NOT EXISTS recurrence TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS time_zone TEXT NOT NULL DEFAULT '';
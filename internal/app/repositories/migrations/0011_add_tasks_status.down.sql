@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS workflow_transitions;
+
+DROP INDEX IF EXISTS tasks_remind_at_idx;
+DROP INDEX IF EXISTS tasks_due_at_idx;
+
+-- Statuses other than done were all open before.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS done BOOLEAN DEFAULT FALSE;
+UPDATE tasks SET done = (status = 'done');
+
+CREATE INDEX IF NOT EXISTS tasks_due_at_idx ON tasks (due_at) WHERE NOT done;
+CREATE INDEX IF NOT EXISTS tasks_remind_at_idx ON tasks (remind_at) WHERE NOT done AND reminded_at IS NULL;
+
+ALTER TABLE tasks
+	DROP COLUMN IF EXISTS next_occurrence_id,
+	DROP COLUMN IF EXISTS status_changed_by,
+	DROP COLUMN IF EXISTS status_changed_at,
+	DROP COLUMN IF EXISTS status;
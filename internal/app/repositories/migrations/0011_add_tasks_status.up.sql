@@ -0,0 +1,31 @@
+-- status replaces done. Tasks that were done stay done, the others start as
+-- todo. status_changed_at and status_changed_by record the last transition.
+ALTER TABLE tasks
+	ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'todo'
+		CHECK (status IN ('todo', 'in_progress', 'blocked', 'done', 'cancelled')),
+	ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS status_changed_by TEXT NOT NULL DEFAULT '',
+	-- The occurrence created when a recurring task was done, so that doing
+	-- it again after reopening creates no second one.
+	ADD COLUMN IF NOT EXISTS next_occurrence_id UUID REFERENCES tasks (id) ON DELETE SET NULL;
+
+UPDATE tasks SET status = 'done' WHERE done;
+
+DROP INDEX IF EXISTS tasks_remind_at_idx;
+DROP INDEX IF EXISTS tasks_due_at_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS done;
+
+-- Done and cancelled tasks are closed and never due.
+CREATE INDEX IF NOT EXISTS tasks_due_at_idx ON tasks (due_at) WHERE status NOT IN ('done', 'cancelled');
+CREATE INDEX IF NOT EXISTS tasks_remind_at_idx ON tasks (remind_at)
+	WHERE status NOT IN ('done', 'cancelled') AND reminded_at IS NULL;
+
+-- The transitions allowed in a workspace. Workspaces without rows use the
+-- default workflow.
+CREATE TABLE IF NOT EXISTS workflow_transitions (
+	workspace_id TEXT NOT NULL,
+	from_status TEXT NOT NULL,
+	to_status TEXT NOT NULL,
+	PRIMARY KEY (workspace_id, from_status, to_status)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+
+-- Trashed tasks were deleted, so they go for good.
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
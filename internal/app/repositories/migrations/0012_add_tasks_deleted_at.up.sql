@@ -0,0 +1,5 @@
+-- Deleted tasks stay in the trash, with deleted_at set, until they are
+-- restored or purged after the retention period.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,25 @@
+-- The history of every task, written in the same transaction as the change.
+-- Entries outlive their task, so there is no foreign key, and they cannot be
+-- changed or removed.
+CREATE TABLE IF NOT EXISTS task_events (
+	id BIGSERIAL PRIMARY KEY,
+	task_id UUID NOT NULL,
+	workspace_id TEXT NOT NULL,
+	type TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	occurred_at TIMESTAMPTZ NOT NULL,
+	changes JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS task_events_task_idx ON task_events (task_id, id);
+CREATE INDEX IF NOT EXISTS task_events_workspace_idx ON task_events (workspace_id, id);
+
+CREATE OR REPLACE FUNCTION task_events_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'task_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS task_events_immutable ON task_events;
+CREATE TRIGGER task_events_immutable BEFORE UPDATE OR DELETE ON task_events
+	FOR EACH ROW EXECUTE FUNCTION task_events_immutable();
@@ -0,0 +1,3 @@
+-- version counts the writes of a task, so that clients can tell whether it
+-- changed since they read it. Every write increments it.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS outbox_pending_tx_idx;
+ALTER TABLE outbox DROP COLUMN IF EXISTS tx_id;
@@ -0,0 +1,7 @@
+-- tx_id is the transaction that stored the event, so that the relay
+-- publishes the events of one transaction, such as those of a batch of
+-- changes, together. Events stored before have none.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS tx_id BIGINT;
+ALTER TABLE outbox ALTER COLUMN tx_id SET DEFAULT txid_current();
+
+CREATE INDEX IF NOT EXISTS outbox_pending_tx_idx ON outbox (tx_id) WHERE sent_at IS NULL;
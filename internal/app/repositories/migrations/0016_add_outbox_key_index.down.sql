@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS outbox_sent_idx;
+DROP INDEX IF EXISTS outbox_pending_key_idx;
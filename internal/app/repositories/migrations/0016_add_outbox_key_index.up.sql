@@ -0,0 +1,4 @@
+-- The relay holds back a message while an older one with the same key is
+-- pending, and the scheduler deletes the messages sent long ago.
+CREATE INDEX IF NOT EXISTS outbox_pending_key_idx ON outbox (message_key, id) WHERE sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_sent_idx ON outbox (sent_at) WHERE sent_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- created_at was a timestamp without time zone, so it kept the wall clock
+-- time of whoever wrote it. The values written so far are taken as UTC, the
+-- time zone of the service containers.
+ALTER TABLE tasks ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
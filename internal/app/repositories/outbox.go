@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+	"github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/lib/pq"
+)
+
+// NewEvent returns an event about a task made by the actor of ctx.
+func NewEvent(ctx context.Context, eventType string, taskID uuid.UUID) kafka.Event {
+	event := kafka.NewEvent(eventType, taskID.String())
+	meta := reqmeta.FromContext(ctx)
+	event.Actor, event.CorrelationID = meta.Actor, meta.CorrelationID
+	if p := auth.FromContext(ctx); p != nil {
+		event.Actor = p.Subject
+	}
+	return event
+}
+
+// enqueueEvent records an event about a task change in the outbox, and in the
+// history of the task if it is one of the historyEvents. It must run in the
+// transaction that makes the change, so that the event is stored if and only
+// if the change is committed.
+func enqueueEvent(ctx context.Context, tx *sql.Tx, eventType string, taskID uuid.UUID, before, after *models.Task) error {
+	event := NewEvent(ctx, eventType, taskID)
+	if historyEvents[eventType] {
+		if err := recordHistory(ctx, tx, event, before, after); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+
+	msg, err := event.Message()
+	if err != nil {
+		return err
+	}
+	traceContext, err := json.Marshal(kafka.InjectTraceMap(ctx))
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO outbox (event_id, message_key, payload, trace_context) VALUES ($1, $2, $3, $4)",
+		event.ID, string(msg.Key), msg.Value, traceContext)
+	return err
+}
+
+type OutboxMessage struct {
+	ID       int64
+	Key      []byte
+	Payload  []byte
+	Attempts int
+	// TraceContext is the trace context of the request that made the change.
+	TraceContext map[string]string
+}
+
+// PostgresOutbox hands the events stored by the repositories over to a
+// publisher.
+type PostgresOutbox struct {
+	db *sql.DB
+}
+
+func NewPostgresOutbox(db *sql.DB) *PostgresOutbox {
+	return &PostgresOutbox{db: db}
+}
+
+// Relay passes up to limit pending messages, oldest first, to publish,
+// along with the other pending messages stored in the same transaction as
+// them, so that the events of a batch of changes are published together,
+// even if that makes more than limit. If publish succeeds they are marked
+// sent, otherwise they are retried later with exponential backoff capped at
+// five minutes. A message waits while an older one with the same key is
+// pending and not picked along with it, so that the messages of a key are
+// published in order. The rows stay locked while publishing, so concurrent
+// relays never pick the same messages. Relay returns how many messages it
+// handled and the error of publish.
+func (o *PostgresOutbox) Relay(ctx context.Context, limit int, publish func(ctx context.Context, msgs []OutboxMessage) error) (int, error) {
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH head AS (
+			SELECT id, tx_id
+			FROM outbox
+			WHERE sent_at IS NULL AND next_attempt_at <= now()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), picked AS (
+			SELECT id, message_key, payload, attempts, trace_context
+			FROM outbox
+			WHERE id IN (SELECT id FROM head)
+				OR (tx_id IN (SELECT tx_id FROM head) AND sent_at IS NULL AND next_attempt_at <= now())
+			FOR UPDATE SKIP LOCKED
+		)
+		SELECT id, message_key, payload, attempts, trace_context
+		FROM picked
+		WHERE NOT EXISTS (
+			SELECT 1 FROM outbox older
+			WHERE older.message_key = picked.message_key
+				AND older.sent_at IS NULL
+				AND older.id < picked.id
+				AND older.id NOT IN (SELECT id FROM picked))
+		ORDER BY id`, limit)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		msgs []OutboxMessage
+		ids  []int64
+	)
+	for rows.Next() {
+		var (
+			m            OutboxMessage
+			traceContext []byte
+		)
+		if err := rows.Scan(&m.ID, &m.Key, &m.Payload, &m.Attempts, &traceContext); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := json.Unmarshal(traceContext, &m.TraceContext); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		msgs = append(msgs, m)
+		ids = append(ids, m.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	publishErr := publish(ctx, msgs)
+	if publishErr == nil {
+		_, err = tx.ExecContext(ctx, "UPDATE outbox SET sent_at = now(), attempts = attempts + 1 WHERE id = ANY($1)", pq.Array(ids))
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE outbox
+			SET attempts = attempts + 1,
+				last_error = $2,
+				next_attempt_at = now() + LEAST(interval '1 second' * power(2, LEAST(attempts, 9)), interval '5 minutes')
+			WHERE id = ANY($1)`, pq.Array(ids), publishErr.Error())
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(msgs), publishErr
+}
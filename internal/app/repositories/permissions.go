@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+var (
+	ErrCollaboratorNotFound = errors.New("collaborator not found")
+	// ErrPermissionDenied means the principal has a role on the task that
+	// does not include the one required, see checkRole.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// PermissionRepository stores who a task is shared with. Like
+// TaskRepository it acts for the principal of ctx, and tasks invisible to it
+// are reported as ErrNotFound. It does not check roles: TaskRepository
+// checks them for the changes of tasks, TaskService for the rest.
+type PermissionRepository interface {
+	// Role returns the role of the principal on a task.
+	Role(ctx context.Context, taskID uuid.UUID) (models.Role, error)
+	// Share grants a role on a task to a user, replacing the role they had.
+	Share(ctx context.Context, c *models.Collaborator) error
+	Unshare(ctx context.Context, taskID uuid.UUID, userID string) error
+	ListCollaborators(ctx context.Context, taskID uuid.UUID) ([]models.Collaborator, error)
+}
+
+// visibleTo restricts a query on tasks to the ones a user owns or
+// collaborates on in a workspace, leaving out the trash. user and workspace
+// are query placeholders.
+func visibleTo(user, workspace string) string {
+	return fmt.Sprintf(
+		"deleted_at IS NULL AND workspace_id = %[2]s AND (owner_id = %[1]s OR EXISTS ("+
+			"SELECT 1 FROM task_permissions p WHERE p.task_id = tasks.id AND p.user_id = %[1]s))",
+		user, workspace)
+}
+
+// checkRole locks the row of a task visible to tn until the end of tx, and
+// the permission of tn on it against changes, and fails with
+// ErrPermissionDenied unless the role of tn includes need.
+func checkRole(ctx context.Context, tx *sql.Tx, tn Tenant, id uuid.UUID, need models.Role) error {
+	var owner string
+	err := tx.QueryRowContext(ctx,
+		"SELECT owner_id FROM tasks WHERE id = $1 AND deleted_at IS NULL AND workspace_id = $2 FOR UPDATE",
+		id, tn.WorkspaceID).Scan(&owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	role := models.RoleOwner
+	if owner != tn.OwnerID {
+		err = tx.QueryRowContext(ctx,
+			"SELECT role FROM task_permissions WHERE task_id = $1 AND user_id = $2 FOR SHARE",
+			id, tn.OwnerID).Scan(&role)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !role.Includes(need) {
+		return fmt.Errorf("%w: %s role required, have %s", ErrPermissionDenied, need, role)
+	}
+	return nil
+}
+
+type PostgresPermissionRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresPermissionRepo(db *sql.DB) *PostgresPermissionRepo {
+	return &PostgresPermissionRepo{db: db}
+}
+
+func (r *PostgresPermissionRepo) Role(ctx context.Context, taskID uuid.UUID) (models.Role, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	var role models.Role
+	err = r.db.QueryRowContext(ctx, `
+		SELECT CASE WHEN t.owner_id = $2 THEN 'owner' ELSE p.role END
+		FROM tasks t
+		LEFT JOIN task_permissions p ON p.task_id = t.id AND p.user_id = $2
+		WHERE t.id = $1 AND t.deleted_at IS NULL AND t.workspace_id = $3 AND (t.owner_id = $2 OR p.role IS NOT NULL)`,
+		taskID, tn.OwnerID, tn.WorkspaceID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return role, err
+}
+
+func (r *PostgresPermissionRepo) Share(ctx context.Context, c *models.Collaborator) error {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	// The select yields no row for a task invisible to the principal.
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO task_permissions (task_id, user_id, role, created_at)
+		SELECT id, $4, $5, $6 FROM tasks WHERE id = $1 AND `+visibleTo("$2", "$3")+`
+		ON CONFLICT (task_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING created_at`,
+		c.TaskID, tn.OwnerID, tn.WorkspaceID, c.UserID, c.Role, time.Now()).Scan(&c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *PostgresPermissionRepo) Unshare(ctx context.Context, taskID uuid.UUID, userID string) error {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM task_permissions
+		WHERE user_id = $4 AND task_id IN (SELECT id FROM tasks WHERE id = $1 AND `+visibleTo("$2", "$3")+`)`,
+		taskID, tn.OwnerID, tn.WorkspaceID, userID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, ErrCollaboratorNotFound)
+}
+
+func (r *PostgresPermissionRepo) ListCollaborators(ctx context.Context, taskID uuid.UUID) ([]models.Collaborator, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT task_id, user_id, role, created_at
+		FROM task_permissions
+		WHERE task_id IN (SELECT id FROM tasks WHERE id = $1 AND `+visibleTo("$2", "$3")+`)
+		ORDER BY created_at, user_id`,
+		taskID, tn.OwnerID, tn.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	collaborators := []models.Collaborator{}
+	for rows.Next() {
+		var c models.Collaborator
+		if err := rows.Scan(&c.TaskID, &c.UserID, &c.Role, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		collaborators = append(collaborators, c)
+	}
+	return collaborators, rows.Err()
+}
@@ -1,79 +1,601 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/google/uuid"
 	"github.com/kalpovskii/checklist/internal/app/models"
-	_ "github.com/lib/pq"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+	"github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
+var (
+	ErrNotFound = errors.New("task not found")
+	ErrConflict = errors.New("task already exists")
+	// ErrTransitionNotAllowed means the workflow of the workspace has no
+	// transition from the status of the task to the requested one.
+	ErrTransitionNotAllowed = errors.New("transition not allowed")
+	// ErrVersionMismatch means the task is not at the version the request
+	// expected, see CheckVersion.
+	ErrVersionMismatch = errors.New("version mismatch")
+	// ErrRecurrenceWithoutDue means an update would leave a recurring task
+	// without a due time.
+	ErrRecurrenceWithoutDue = errors.New("a recurring task needs a due time")
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// TaskRepository stores tasks. Every method acts for the principal of ctx,
+// see auth.FromContext: it sees the tasks it owns or collaborates on in its
+// workspace, others are reported as not found. Delete and Restore only see
+// owned tasks. The methods that change a task need the principal to be an
+// editor of it, Delete to own it, checked while the task is locked for the
+// change; otherwise they fail with ErrPermissionDenied. They increment the
+// version of the task, and fail with ErrVersionMismatch if ctx expects
+// another one. Deleted tasks are
+// in the trash, invisible to every method but Restore and List with Trashed
+// set. Without a principal ErrNoTenant is returned.
 type TaskRepository interface {
-	Create(task *models.Task) error
-	List() ([]models.Task, error)
-	Delete(id uuid.UUID) error
-	MarkDone(id uuid.UUID) error
+	Create(ctx context.Context, task *models.Task) error
+	List(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error)
+	// Delete moves a task to the trash.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Restore takes a task out of the trash and returns it.
+	Restore(ctx context.Context, id uuid.UUID) (*models.Task, error)
+	// Transition moves a task to status to if wf allows it, and records the
+	// principal of ctx as the one who did. Moving a task to the status it is
+	// in changes nothing. When a task becomes done, next, if not nil, is
+	// asked for its next occurrence, which is created in the same
+	// transaction, once per task.
+	Transition(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next NextOccurrence) (*models.Task, error)
+	// Reopen is Transition to models.StatusTodo of a task that is closed,
+	// see models.Status.Closed. Open tasks are returned unchanged. The
+	// status is checked while the task is locked for the change.
+	Reopen(ctx context.Context, id uuid.UUID, wf *models.Workflow) (*models.Task, error)
+	// Update applies patch to a task. It fails with ErrRecurrenceWithoutDue
+	// if the task would recur without a due time.
+	Update(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error)
+	Search(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
+
+	// The item methods change the items of a task and return the task with
+	// its items after the change. UpdateItem and RemoveItem also complete a
+	// task with AutoComplete set whose items are all done after the change,
+	// in the same transaction, if wf allows it; a nil wf never completes it.
+	AddItem(ctx context.Context, taskID uuid.UUID, item *models.TaskItem) (*models.Task, error)
+	UpdateItem(ctx context.Context, taskID, itemID uuid.UUID, patch models.TaskItemPatch, wf *models.Workflow, next NextOccurrence) (*models.Task, error)
+	// ReorderItems moves the items to the positions of their IDs in itemIDs,
+	// which must list every item of the task exactly once.
+	ReorderItems(ctx context.Context, taskID uuid.UUID, itemIDs []uuid.UUID) (*models.Task, error)
+	RemoveItem(ctx context.Context, taskID, itemID uuid.UUID, wf *models.Workflow, next NextOccurrence) (*models.Task, error)
+
+	// The batch methods are Create, Transition and Delete of many tasks in
+	// one transaction. They return the result of every item, in order, and
+	// an error only if the batch could not run. With models.BatchAllOrNothing
+	// the first failed item rolls back the batch, and the others fail with
+	// ErrBatchAborted; with models.BatchBestEffort only failed items are
+	// rolled back.
+	BatchCreate(ctx context.Context, tasks []*models.Task, mode models.BatchMode) ([]models.BatchResult, error)
+	BatchTransition(ctx context.Context, ids []uuid.UUID, to models.Status, wf *models.Workflow, next NextOccurrence, mode models.BatchMode) ([]models.BatchResult, error)
+	BatchDelete(ctx context.Context, ids []uuid.UUID, mode models.BatchMode) ([]models.BatchResult, error)
 }
 
 type PostgresTaskRepo struct {
 	db *sql.DB
 }
 
-func NewPostgresTaskRepo(dsn string) (*PostgresTaskRepo, error) {
-	db, err := sql.Open("postgres", dsn)
+// OpenPostgres connects to Postgres and applies pending schema migrations.
+func OpenPostgres(ctx context.Context, dsn string) (*sql.DB, error) {
+	// Every query gets a span, as a child of the span in its context.
+	db, err := otelsql.Open("postgres", dsn, otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL))
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := NewMigrator(db)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
+	if _, err := migrator.Up(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// NewPostgresTaskRepo expects the schema to be migrated, see OpenPostgres.
+func NewPostgresTaskRepo(db *sql.DB) *PostgresTaskRepo {
+	return &PostgresTaskRepo{db: db}
+}
+
+// taskColumns lists the columns read by scanTask, in order.
+const taskColumns = "id, title, content, status, created_at, owner_id, workspace_id, auto_complete, due_at, remind_at, " +
+	"recurrence, time_zone, status_changed_at, status_changed_by, deleted_at, version"
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS tasks (
-			id UUID PRIMARY KEY,
-			title TEXT NOT NULL,
-			content TEXT,
-			done BOOLEAN DEFAULT FALSE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
+func scanTask(row rowScanner) (*models.Task, error) {
+	var t models.Task
+	err := row.Scan(&t.ID, &t.Title, &t.Content, &t.Status, &t.CreatedAt, &t.OwnerID, &t.WorkspaceID, &t.AutoComplete,
+		&t.DueAt, &t.RemindAt, &t.Recurrence, &t.TimeZone, &t.StatusChangedAt, &t.StatusChangedBy, &t.DeletedAt, &t.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
+	t.Done = t.Status == models.StatusDone
+	return &t, nil
+}
 
-	return &PostgresTaskRepo{db: db}, nil
+// withTx runs fn in a transaction and commits it if fn succeeds.
+func (r *PostgresTaskRepo) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *PostgresTaskRepo) Create(task *models.Task) error {
+// ownedBy restricts a query to the tasks a tenant owns, and visible to the
+// ones it owns or collaborates on. Both expect the user and workspace of the
+// tenant as arguments $2 and $3.
+var (
+	ownedBy = "owner_id = $2 AND workspace_id = $3"
+	visible = visibleTo("$2", "$3")
+)
+
+// openTask restricts a query to tasks whose status is not closed, see
+// models.Status.Closed. It matches the partial indexes on due and remind
+// times.
+const openTask = "status NOT IN ('done', 'cancelled')"
+
+// lockTask reads a task tn is an editor of and locks its row until the end
+// of tx, see checkRole. The task must be at the version expected by ctx.
+func lockTask(ctx context.Context, tx *sql.Tx, tn Tenant, id uuid.UUID) (*models.Task, error) {
+	if err := checkRole(ctx, tx, tn, id, models.RoleEditor); err != nil {
+		return nil, err
+	}
+	task, err := scanTask(tx.QueryRowContext(ctx,
+		"SELECT "+taskColumns+" FROM tasks WHERE id = $1 AND "+visible+" FOR UPDATE", id, tn.OwnerID, tn.WorkspaceID))
+	if err != nil {
+		return nil, err
+	}
+	return task, CheckVersion(ctx, task)
+}
+
+// CheckVersion returns ErrVersionMismatch if the request of ctx expects
+// another version of task, see reqmeta.Meta.ExpectedVersions.
+func CheckVersion(ctx context.Context, task *models.Task) error {
+	if want := reqmeta.FromContext(ctx).ExpectedVersions; len(want) > 0 && !slices.Contains(want, task.Version) {
+		return fmt.Errorf("%w: task is at version %d, not %v", ErrVersionMismatch, task.Version, want)
+	}
+	return nil
+}
+
+// Create stores task as owned by the principal of ctx, in status todo unless
+// it has one, at version 1.
+func (r *PostgresTaskRepo) Create(ctx context.Context, task *models.Task) error {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return createTask(ctx, tx, tn, task)
+	})
+}
+
+// createTask is Create in tx.
+func createTask(ctx context.Context, tx *sql.Tx, tn Tenant, task *models.Task) error {
 	task.ID = uuid.New()
 	task.CreatedAt = time.Now()
-	_, err := r.db.Exec("INSERT INTO tasks (id, title, content, done, created_at) VALUES ($1, $2, $3, $4, $5)",
-		task.ID, task.Title, task.Content, task.Done, task.CreatedAt)
+	task.OwnerID, task.WorkspaceID = tn.OwnerID, tn.WorkspaceID
+	if task.Status == "" {
+		task.Status = models.StatusTodo
+	}
+	task.Done = task.Status == models.StatusDone
+	task.Version = 1
+	task.Items = []models.TaskItem{}
+	if err := insertTask(ctx, tx, task); err != nil {
+		return err
+	}
+	return enqueueEvent(ctx, tx, kafka.EventTaskCreated, task.ID, nil, task)
+}
+
+// insertTask stores the row of task, without its items.
+func insertTask(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO tasks ("+taskColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)",
+		task.ID, task.Title, task.Content, task.Status, task.CreatedAt, task.OwnerID, task.WorkspaceID, task.AutoComplete,
+		task.DueAt, task.RemindAt, task.Recurrence, task.TimeZone, task.StatusChangedAt, task.StatusChangedBy, task.DeletedAt,
+		task.Version)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrConflict
+	}
 	return err
 }
 
-func (r *PostgresTaskRepo) List() ([]models.Task, error) {
-	rows, err := r.db.Query("SELECT id, title, content, done, created_at FROM tasks")
+// List returns one page of tasks using keyset pagination over (created_at, id).
+func (r *PostgresTaskRepo) List(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Trashed {
+		conds = append(conds, "deleted_at IS NOT NULL AND owner_id = "+arg(tn.OwnerID)+" AND workspace_id = "+arg(tn.WorkspaceID))
+	} else {
+		conds = append(conds, visibleTo(arg(tn.OwnerID), arg(tn.WorkspaceID)))
+	}
+
+	if q.Done != nil {
+		op := "<>"
+		if *q.Done {
+			op = "="
+		}
+		conds = append(conds, "status "+op+" "+arg(models.StatusDone))
+	}
+	if len(q.Statuses) > 0 {
+		statuses := make([]string, len(q.Statuses))
+		for i, status := range q.Statuses {
+			statuses[i] = string(status)
+		}
+		conds = append(conds, "status = ANY("+arg(pq.Array(statuses))+"::text[])")
+	}
+	if q.CreatedAfter != nil {
+		conds = append(conds, "created_at >= "+arg(*q.CreatedAfter))
+	}
+	if q.CreatedBefore != nil {
+		conds = append(conds, "created_at < "+arg(*q.CreatedBefore))
+	}
+	if q.Overdue {
+		conds = append(conds, openTask+" AND due_at <= now()")
+	}
+	if q.DueWithin > 0 {
+		conds = append(conds, openTask+" AND due_at > now() AND due_at <= now() + "+arg(q.DueWithin.Seconds())+" * interval '1 second'")
+	}
+
+	order, cmp := "ASC", ">"
+	if q.Descending {
+		order, cmp = "DESC", "<"
+	}
+	if q.PageToken != "" {
+		c, err := DecodeCursor(q.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, fmt.Sprintf("(created_at, id) %s (%s, %s)", cmp, arg(c.CreatedAt), arg(c.ID)))
+	}
+
+	query := "SELECT " + taskColumns + " FROM tasks WHERE " + strings.Join(conds, " AND ")
+	// One extra row tells whether there is a next page.
+	query += fmt.Sprintf(" ORDER BY created_at %s, id %s LIMIT %s", order, order, arg(q.PageSize+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var tasks []models.Task
+	tasks := []models.Task{}
 	for rows.Next() {
-		var t models.Task
-		err := rows.Scan(&t.ID, &t.Title, &t.Content, &t.Done, &t.CreatedAt)
+		t, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, t)
+		tasks = append(tasks, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
-	return tasks, nil
+
+	page := &models.TaskPage{Tasks: tasks}
+	if len(tasks) > q.PageSize {
+		page.Tasks = tasks[:q.PageSize]
+		page.NextPageToken = EncodeCursor(page.Tasks[q.PageSize-1])
+	}
+	ptrs := make([]*models.Task, len(page.Tasks))
+	for i := range page.Tasks {
+		ptrs[i] = &page.Tasks[i]
+	}
+	if err := loadItems(ctx, r.db, ptrs...); err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
-func (r *PostgresTaskRepo) Delete(id uuid.UUID) error {
-	_, err := r.db.Exec("DELETE FROM tasks WHERE id = $1", id)
-	return err
+func (r *PostgresTaskRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := scanTask(r.db.QueryRowContext(ctx,
+		"SELECT "+taskColumns+" FROM tasks WHERE id = $1 AND "+visible, id, tn.OwnerID, tn.WorkspaceID))
+	if err != nil {
+		return nil, err
+	}
+	if err := loadItems(ctx, r.db, task); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
-func (r *PostgresTaskRepo) MarkDone(id uuid.UUID) error {
-	_, err := r.db.Exec("UPDATE tasks SET done = TRUE WHERE id = $1", id)
-	return err
-}
\ No newline at end of file
+// Search ranks tasks matching a web-search style query against title and
+// content, title matches weighing more.
+func (r *PostgresTaskRepo) Search(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+taskColumns+`,
+			ts_rank(search_vector, q),
+			ts_headline('simple', title, q, 'StartSel=<b>, StopSel=</b>, HighlightAll=true'),
+			ts_headline('simple', coalesce(content, ''), q, 'StartSel=<b>, StopSel=</b>, MaxFragments=2')
+		FROM tasks, websearch_to_tsquery('simple', $1) q
+		WHERE search_vector @@ q AND `+visibleTo("$3", "$4")+`
+		ORDER BY 17 DESC, created_at DESC
+		LIMIT $2`, query, limit, tn.OwnerID, tn.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var res models.SearchResult
+		t := &res.Task
+		err := rows.Scan(&t.ID, &t.Title, &t.Content, &t.Status, &t.CreatedAt, &t.OwnerID, &t.WorkspaceID, &t.AutoComplete,
+			&t.DueAt, &t.RemindAt, &t.Recurrence, &t.TimeZone, &t.StatusChangedAt, &t.StatusChangedBy, &t.DeletedAt, &t.Version,
+			&res.Rank, &res.TitleSnippet, &res.ContentSnippet)
+		if err != nil {
+			return nil, err
+		}
+		t.Done = t.Status == models.StatusDone
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	ptrs := make([]*models.Task, len(results))
+	for i := range results {
+		ptrs[i] = &results[i].Task
+	}
+	if err := loadItems(ctx, r.db, ptrs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *PostgresTaskRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return deleteTask(ctx, tx, tn, id)
+	})
+}
+
+// deleteTask is Delete in tx.
+func deleteTask(ctx context.Context, tx *sql.Tx, tn Tenant, id uuid.UUID) error {
+	if err := checkRole(ctx, tx, tn, id, models.RoleOwner); err != nil {
+		return err
+	}
+	before, err := scanTask(tx.QueryRowContext(ctx,
+		"SELECT "+taskColumns+" FROM tasks WHERE id = $1 AND deleted_at IS NULL AND "+ownedBy+" FOR UPDATE",
+		id, tn.OwnerID, tn.WorkspaceID))
+	if err != nil {
+		return err
+	}
+	if err := CheckVersion(ctx, before); err != nil {
+		return err
+	}
+	if err := loadItems(ctx, tx, before); err != nil {
+		return err
+	}
+	after, err := scanTask(tx.QueryRowContext(ctx,
+		"UPDATE tasks SET deleted_at = now(), version = version + 1 WHERE id = $1 RETURNING "+taskColumns, id))
+	if err != nil {
+		return err
+	}
+	after.Items = before.Items
+	return enqueueEvent(ctx, tx, kafka.EventTaskDeleted, id, before, after)
+}
+
+func (r *PostgresTaskRepo) Restore(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var after *models.Task
+	err = r.withTx(ctx, func(tx *sql.Tx) error {
+		before, err := scanTask(tx.QueryRowContext(ctx,
+			"SELECT "+taskColumns+" FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL AND "+ownedBy+" FOR UPDATE",
+			id, tn.OwnerID, tn.WorkspaceID))
+		if err != nil {
+			return err
+		}
+		if err := CheckVersion(ctx, before); err != nil {
+			return err
+		}
+		if err := loadItems(ctx, tx, before); err != nil {
+			return err
+		}
+		after, err = scanTask(tx.QueryRowContext(ctx,
+			"UPDATE tasks SET deleted_at = NULL, version = version + 1 WHERE id = $1 RETURNING "+taskColumns, id))
+		if err != nil {
+			return err
+		}
+		after.Items = before.Items
+		return enqueueEvent(ctx, tx, kafka.EventTaskRestored, id, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+func (r *PostgresTaskRepo) Transition(ctx context.Context, id uuid.UUID, to models.Status, wf *models.Workflow, next NextOccurrence) (*models.Task, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var after *models.Task
+	err = r.withTx(ctx, func(tx *sql.Tx) error {
+		after, err = transitionTask(ctx, tx, tn, id, to, wf, next)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// transitionTask is Transition in tx.
+func transitionTask(ctx context.Context, tx *sql.Tx, tn Tenant, id uuid.UUID, to models.Status, wf *models.Workflow, next NextOccurrence) (*models.Task, error) {
+	before, err := lockTask(ctx, tx, tn, id)
+	if err != nil {
+		return nil, err
+	}
+	return moveTask(ctx, tx, tn, before, to, wf, next)
+}
+
+func (r *PostgresTaskRepo) Reopen(ctx context.Context, id uuid.UUID, wf *models.Workflow) (*models.Task, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var after *models.Task
+	err = r.withTx(ctx, func(tx *sql.Tx) error {
+		before, err := lockTask(ctx, tx, tn, id)
+		if err != nil {
+			return err
+		}
+		if !before.Status.Closed() {
+			after = before
+			return loadItems(ctx, tx, after)
+		}
+		after, err = moveTask(ctx, tx, tn, before, models.StatusTodo, wf, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// moveTask is transitionTask of before, locked by lockTask.
+func moveTask(ctx context.Context, tx *sql.Tx, tn Tenant, before *models.Task, to models.Status, wf *models.Workflow, next NextOccurrence) (*models.Task, error) {
+	id := before.ID
+	if !wf.Allows(before.Status, to) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrTransitionNotAllowed, before.Status, to)
+	}
+	if err := loadItems(ctx, tx, before); err != nil {
+		return nil, err
+	}
+	if before.Status == to {
+		return before, nil
+	}
+	after, err := scanTask(tx.QueryRowContext(ctx, `
+		UPDATE tasks SET status = $2, status_changed_at = now(), status_changed_by = $3, version = version + 1
+		WHERE id = $1
+		RETURNING `+taskColumns, id, to, tn.OwnerID))
+	if err != nil {
+		return nil, err
+	}
+	after.Items = before.Items
+	eventType := kafka.EventTaskStatusChanged
+	if to == models.StatusDone {
+		eventType = kafka.EventTaskDone
+	}
+	if err := enqueueEvent(ctx, tx, eventType, id, before, after); err != nil {
+		return nil, err
+	}
+	if next != nil && to == models.StatusDone {
+		if err := createOccurrence(ctx, tx, before, next); err != nil {
+			return nil, err
+		}
+	}
+	return after, nil
+}
+
+// Update applies the non-nil fields of patch and returns the resulting task.
+// Changing a due or remind time lets the scheduler notify about it again.
+func (r *PostgresTaskRepo) Update(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var after *models.Task
+	err = r.withTx(ctx, func(tx *sql.Tx) error {
+		before, err := lockTask(ctx, tx, tn, id)
+		if err != nil {
+			return err
+		}
+		if err := loadItems(ctx, tx, before); err != nil {
+			return err
+		}
+		setDue, dueAt := TimePatch(patch.DueAt)
+		setRemind, remindAt := TimePatch(patch.RemindAt)
+		after, err = scanTask(tx.QueryRowContext(ctx, `
+			UPDATE tasks
+			SET title = COALESCE($2, title), content = COALESCE($3, content), auto_complete = COALESCE($4, auto_complete),
+				due_at = CASE WHEN $5 THEN $6::timestamptz ELSE due_at END,
+				overdue_notified_at = CASE WHEN $5 THEN NULL ELSE overdue_notified_at END,
+				remind_at = CASE WHEN $7 THEN $8::timestamptz ELSE remind_at END,
+				reminded_at = CASE WHEN $7 THEN NULL ELSE reminded_at END,
+				recurrence = COALESCE($9, recurrence), time_zone = COALESCE($10, time_zone),
+				version = version + 1
+			WHERE id = $1
+			RETURNING `+taskColumns,
+			id, patch.Title, patch.Content, patch.AutoComplete, setDue, dueAt, setRemind, remindAt, patch.Recurrence, patch.TimeZone,
+		))
+		if err != nil {
+			return err
+		}
+		if after.Recurrence != "" && after.DueAt == nil {
+			return ErrRecurrenceWithoutDue
+		}
+		after.Items = before.Items
+		return enqueueEvent(ctx, tx, kafka.EventTaskUpdated, id, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// TimePatch splits a time field of TaskPatch into whether to set the column
+// and the value to set it to, nil for a zero time.
+func TimePatch(t *time.Time) (bool, *time.Time) {
+	if t == nil || t.IsZero() {
+		return t != nil, nil
+	}
+	return true, t
+}
@@ -0,0 +1,383 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+// testPostgresDSNEnv names the DSN of a Postgres database the tests may
+// create schemas in. Without it the tests on Postgres are skipped.
+const testPostgresDSNEnv = "CHECKLIST_TEST_POSTGRES_DSN"
+
+// openTestPostgres opens the database of testPostgresDSNEnv with its own
+// schema, migrated by OpenPostgres and dropped when the test ends.
+func openTestPostgres(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(testPostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s не задан", testPostgresDSNEnv)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("не удалось открыть базу: %v", err)
+	}
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		t.Fatalf("не удалось создать схему: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("не удалось удалить схему: %v", err)
+		}
+		admin.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	db, err := OpenPostgres(ctx, withSearchPath(t, dsn, schema))
+	if err != nil {
+		t.Fatalf("не удалось применить миграции: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// withSearchPath adds search_path to dsn, given as a URL or as key=value
+// pairs, so that every connection works in schema.
+func withSearchPath(t *testing.T, dsn, schema string) string {
+	t.Helper()
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("неверный DSN: %v", err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func principalContext(subject, workspace string) context.Context {
+	return auth.NewContext(context.Background(), &auth.Principal{Subject: subject, WorkspaceID: workspace})
+}
+
+func createPostgresTask(t *testing.T, ctx context.Context, repo *PostgresTaskRepo, task models.Task) *models.Task {
+	t.Helper()
+	if err := repo.Create(ctx, &task); err != nil {
+		t.Fatalf("не удалось создать задачу: %v", err)
+	}
+	return &task
+}
+
+func TestPostgresMigrations(t *testing.T) {
+	db := openTestPostgres(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	t.Run("created_at хранится с часовым поясом", func(t *testing.T) {
+		var dataType string
+		err := db.QueryRowContext(ctx, `
+			SELECT data_type FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'tasks' AND column_name = 'created_at'`).Scan(&dataType)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if dataType != "timestamp with time zone" {
+			t.Errorf("ожидался timestamp with time zone, получено %q", dataType)
+		}
+	})
+
+	t.Run("все миграции откатываются и применяются снова", func(t *testing.T) {
+		reverted, err := m.Down(ctx, len(m.migrations))
+		if err != nil {
+			t.Fatalf("откат: %v", err)
+		}
+		if len(reverted) != len(m.migrations) {
+			t.Fatalf("ожидался откат %d миграций, получено %d", len(m.migrations), len(reverted))
+		}
+		applied, err := m.Up(ctx)
+		if err != nil {
+			t.Fatalf("применение: %v", err)
+		}
+		if len(applied) != len(m.migrations) {
+			t.Errorf("ожидалось применение %d миграций, получено %d", len(m.migrations), len(applied))
+		}
+	})
+}
+
+func TestPostgresTaskRepo_Roles(t *testing.T) {
+	db := openTestPostgres(t)
+	repo := NewPostgresTaskRepo(db)
+	perms := NewPostgresPermissionRepo(db)
+	aliceCtx := principalContext("alice", "ws-1")
+	bobCtx := principalContext("bob", "ws-1")
+	id := createPostgresTask(t, aliceCtx, repo, models.Task{Title: "Задача"}).ID
+	wf := models.DefaultWorkflow("ws-1")
+	title := "Новый заголовок"
+	share := func(role models.Role) {
+		t.Helper()
+		if err := perms.Share(aliceCtx, &models.Collaborator{TaskID: id, UserID: "bob", Role: role}); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+
+	t.Run("зритель не меняет задачу", func(t *testing.T) {
+		share(models.RoleViewer)
+		if _, err := repo.Update(bobCtx, id, models.TaskPatch{Title: &title}); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("Update: ожидалась ErrPermissionDenied, получено %v", err)
+		}
+		if _, err := repo.Transition(bobCtx, id, models.StatusDone, wf, nil); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("Transition: ожидалась ErrPermissionDenied, получено %v", err)
+		}
+		if _, err := repo.AddItem(bobCtx, id, &models.TaskItem{Text: "Пункт"}); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("AddItem: ожидалась ErrPermissionDenied, получено %v", err)
+		}
+	})
+
+	t.Run("редактор меняет, но не удаляет", func(t *testing.T) {
+		share(models.RoleEditor)
+		if _, err := repo.Update(bobCtx, id, models.TaskPatch{Title: &title}); err != nil {
+			t.Errorf("Update: неожиданная ошибка: %v", err)
+		}
+		if err := repo.Delete(bobCtx, id); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("Delete: ожидалась ErrPermissionDenied, получено %v", err)
+		}
+	})
+
+	t.Run("без роли задача не найдена", func(t *testing.T) {
+		if _, err := repo.Update(principalContext("carol", "ws-1"), id, models.TaskPatch{Title: &title}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("ожидалась ErrNotFound, получено %v", err)
+		}
+	})
+}
+
+func TestPostgresTaskRepo_Batch(t *testing.T) {
+	db := openTestPostgres(t)
+	repo := NewPostgresTaskRepo(db)
+	ctx := principalContext("alice", "ws-1")
+	wf := models.DefaultWorkflow("ws-1")
+
+	results, err := repo.BatchCreate(ctx, []*models.Task{{Title: "Первая"}, {Title: "Вторая"}}, models.BatchAllOrNothing)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	var ids []uuid.UUID
+	for _, r := range results {
+		if r.Err != nil || r.Task == nil {
+			t.Fatalf("ожидалась созданная задача, получено %+v", r)
+		}
+		ids = append(ids, r.Task.ID)
+	}
+	missing := uuid.New()
+
+	t.Run("всё или ничего откатывает пакет", func(t *testing.T) {
+		results, err := repo.BatchTransition(ctx, []uuid.UUID{ids[0], missing}, models.StatusDone, wf, nil, models.BatchAllOrNothing)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !errors.Is(results[0].Err, ErrBatchAborted) || !errors.Is(results[1].Err, ErrNotFound) {
+			t.Errorf("ожидались ErrBatchAborted и ErrNotFound, получено %+v", results)
+		}
+		got, err := repo.GetByID(ctx, ids[0])
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if got.Status != models.StatusTodo || got.Version != 1 {
+			t.Errorf("задача не должна меняться: %+v", got)
+		}
+	})
+
+	t.Run("лучшее усилие применяет удачные элементы", func(t *testing.T) {
+		results, err := repo.BatchTransition(ctx, []uuid.UUID{missing, ids[0], ids[1]}, models.StatusDone, wf, nil, models.BatchBestEffort)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if !errors.Is(results[0].Err, ErrNotFound) {
+			t.Errorf("ожидалась ErrNotFound, получено %v", results[0].Err)
+		}
+		for _, id := range ids {
+			got, err := repo.GetByID(ctx, id)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if got.Status != models.StatusDone {
+				t.Errorf("задача %s должна быть выполнена, получено %q", id, got.Status)
+			}
+		}
+	})
+}
+
+func TestPostgresTaskRepo_AutoComplete(t *testing.T) {
+	db := openTestPostgres(t)
+	repo := NewPostgresTaskRepo(db)
+	ctx := principalContext("alice", "ws-1")
+	task := createPostgresTask(t, ctx, repo, models.Task{Title: "Чек-лист", AutoComplete: true})
+	added, err := repo.AddItem(ctx, task.ID, &models.TaskItem{Text: "Пункт"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	done := true
+	got, err := repo.UpdateItem(ctx, task.ID, added.Items[0].ID, models.TaskItemPatch{Done: &done}, models.DefaultWorkflow("ws-1"), nil)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got.Status != models.StatusDone || got.Version != 4 {
+		t.Errorf("ожидалась выполненная задача версии 4, получено %q версии %d", got.Status, got.Version)
+	}
+	var events int
+	if err := db.QueryRow("SELECT count(*) FROM task_events WHERE task_id = $1 AND type = 'task.done'", task.ID).Scan(&events); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if events != 1 {
+		t.Errorf("ожидалось одно событие task.done, получено %d", events)
+	}
+}
+
+func TestPostgresHistoryRepo(t *testing.T) {
+	db := openTestPostgres(t)
+	repo := NewPostgresTaskRepo(db)
+	history := NewPostgresHistoryRepo(db)
+	ctx := principalContext("alice", "ws-1")
+	task := createPostgresTask(t, ctx, repo, models.Task{Title: "Отчёт"})
+	title := "Годовой отчёт"
+	if _, err := repo.Update(ctx, task.ID, models.TaskPatch{Title: &title}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	t.Run("изменения записываются в историю", func(t *testing.T) {
+		page, err := history.Events(ctx, models.HistoryQuery{PageSize: 10, TaskID: &task.ID, Visible: true})
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(page.Events) != 2 || page.Events[0].Type != "task.updated" || page.Events[1].Type != "task.created" {
+			t.Fatalf("ожидались task.updated и task.created, получено %+v", page.Events)
+		}
+		changes := page.Events[0].Changes
+		if len(changes) != 1 || changes[0].Field != "title" || string(changes[0].After) != `"Годовой отчёт"` {
+			t.Errorf("неожиданные изменения: %+v", changes)
+		}
+		if page.Events[0].Actor != "alice" {
+			t.Errorf("ожидался автор alice, получено %q", page.Events[0].Actor)
+		}
+	})
+
+	t.Run("история невидимой задачи не найдена", func(t *testing.T) {
+		_, err := history.Events(principalContext("bob", "ws-1"), models.HistoryQuery{PageSize: 10, TaskID: &task.ID, Visible: true})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("ожидалась ErrNotFound, получено %v", err)
+		}
+	})
+}
+
+func TestPostgresOutbox(t *testing.T) {
+	db := openTestPostgres(t)
+	repo := NewPostgresTaskRepo(db)
+	outbox := NewPostgresOutbox(db)
+	ctx := principalContext("alice", "ws-1")
+	errPublish := errors.New("kafka недоступна")
+	relay := func(limit int, fail bool) ([]string, error) {
+		t.Helper()
+		var keys []string
+		_, err := outbox.Relay(ctx, limit, func(ctx context.Context, msgs []OutboxMessage) error {
+			if fail {
+				return errPublish
+			}
+			for _, m := range msgs {
+				keys = append(keys, string(m.Key))
+			}
+			return nil
+		})
+		return keys, err
+	}
+
+	first := createPostgresTask(t, ctx, repo, models.Task{Title: "Первая"})
+	title := "Первая, исправленная"
+	if _, err := repo.Update(ctx, first.ID, models.TaskPatch{Title: &title}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	second := createPostgresTask(t, ctx, repo, models.Task{Title: "Вторая"})
+
+	t.Run("события одного ключа идут по порядку", func(t *testing.T) {
+		if _, err := relay(1, true); !errors.Is(err, errPublish) {
+			t.Fatalf("ожидалась ошибка публикации, получено %v", err)
+		}
+		// Создание первой задачи ждёт повтора, её изменение ждёт создания.
+		if _, err := db.Exec("UPDATE outbox SET next_attempt_at = now() + interval '1 hour' WHERE attempts > 0"); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		keys, err := relay(10, false)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(keys) != 1 || keys[0] != second.ID.String() {
+			t.Fatalf("ожидалось только событие второй задачи, получено %v", keys)
+		}
+
+		if _, err := db.Exec("UPDATE outbox SET next_attempt_at = now() WHERE sent_at IS NULL"); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		keys, err = relay(10, false)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(keys) != 2 || keys[0] != first.ID.String() || keys[1] != first.ID.String() {
+			t.Errorf("ожидались оба события первой задачи, получено %v", keys)
+		}
+	})
+
+	t.Run("задержка повтора не переполняется", func(t *testing.T) {
+		createPostgresTask(t, ctx, repo, models.Task{Title: "Третья"})
+		if _, err := db.Exec("UPDATE outbox SET attempts = 10000 WHERE sent_at IS NULL"); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if _, err := relay(10, true); !errors.Is(err, errPublish) {
+			t.Fatalf("ожидалась ошибка публикации, получено %v", err)
+		}
+		var late bool
+		err := db.QueryRow("SELECT bool_or(next_attempt_at > now() + interval '5 minutes') FROM outbox WHERE sent_at IS NULL").Scan(&late)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if late {
+			t.Error("задержка повтора должна быть не больше пяти минут")
+		}
+	})
+
+	t.Run("отправленные события удаляются по сроку", func(t *testing.T) {
+		if _, err := db.Exec("UPDATE outbox SET sent_at = now() - interval '2 hours' WHERE sent_at IS NOT NULL"); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		n, err := NewPostgresScheduler(db).PurgeOutbox(ctx, time.Hour, 100)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if n != 3 {
+			t.Errorf("ожидалось удаление трёх событий, удалено %d", n)
+		}
+		var pending int
+		if err := db.QueryRow("SELECT count(*) FROM outbox").Scan(&pending); err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if pending != 1 {
+			t.Errorf("неотправленное событие должно остаться, в outbox %d", pending)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/kafka"
+)
+
+// NextOccurrence returns the task that follows done, a recurring task being
+// marked done, or nil if there is none. Of the returned task the fields a
+// client may set on create are used, and the text of its items.
+type NextOccurrence func(done *models.Task) (*models.Task, error)
+
+// Occurrence asks next for the task following done and prepares it for
+// storing: it gets the owner and workspace of done, and undone items.
+func Occurrence(done *models.Task, next NextOccurrence) (*models.Task, error) {
+	draft, err := next(done)
+	if err != nil || draft == nil {
+		return nil, err
+	}
+	task := &models.Task{
+		ID:           uuid.New(),
+		Title:        draft.Title,
+		Content:      draft.Content,
+		CreatedAt:    time.Now(),
+		OwnerID:      done.OwnerID,
+		WorkspaceID:  done.WorkspaceID,
+		AutoComplete: draft.AutoComplete,
+		DueAt:        draft.DueAt,
+		RemindAt:     draft.RemindAt,
+		Recurrence:   draft.Recurrence,
+		TimeZone:     draft.TimeZone,
+		Status:       models.StatusTodo,
+		Version:      1,
+		Items:        make([]models.TaskItem, len(draft.Items)),
+	}
+	for i, it := range draft.Items {
+		task.Items[i] = models.TaskItem{
+			ID:        uuid.New(),
+			TaskID:    task.ID,
+			Position:  i,
+			Text:      it.Text,
+			CreatedAt: task.CreatedAt,
+		}
+	}
+	return task, nil
+}
+
+// createOccurrence stores the occurrence following done in tx, unless one
+// was stored before and done has been reopened since. It is shared with the
+// collaborators of done, like done was.
+func createOccurrence(ctx context.Context, tx *sql.Tx, done *models.Task, next NextOccurrence) error {
+	var created bool
+	err := tx.QueryRowContext(ctx, "SELECT next_occurrence_id IS NOT NULL FROM tasks WHERE id = $1", done.ID).Scan(&created)
+	if err != nil || created {
+		return err
+	}
+	task, err := Occurrence(done, next)
+	if err != nil || task == nil {
+		return err
+	}
+	if err := insertTask(ctx, tx, task); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE tasks SET next_occurrence_id = $2 WHERE id = $1", done.ID, task.ID); err != nil {
+		return err
+	}
+	for _, it := range task.Items {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO task_items ("+itemColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+			it.ID, it.TaskID, it.Position, it.Text, it.Done, it.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO task_permissions (task_id, user_id, role)
+		SELECT $1, user_id, role FROM task_permissions WHERE task_id = $2`, task.ID, done.ID)
+	if err != nil {
+		return err
+	}
+	return enqueueEvent(ctx, tx, kafka.EventTaskCreated, task.ID, nil, task)
+}
@@ -6,18 +6,27 @@ import (
 	"time"
 
 	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/metrics"
 	"github.com/redis/go-redis/v9"
 )
 
+// TaskCache caches tasks per workspace, the one of the principal of ctx.
+// Entries of different workspaces never mix.
 type TaskCache interface {
 	GetTask(ctx context.Context, id string) (*models.Task, error)
 	SetTask(ctx context.Context, task *models.Task, ttl time.Duration) error
 
-	GetTaskList(ctx context.Context) ([]models.Task, error)
-	SetTaskList(ctx context.Context, tasks []models.Task, ttl time.Duration) error
+	// List pages are cached per query; key identifies the query.
+	GetTaskList(ctx context.Context, key string) (*models.TaskPage, error)
+	SetTaskList(ctx context.Context, key string, page *models.TaskPage, ttl time.Duration) error
 
 	DeleteTask(ctx context.Context, id string) error
+	// DeleteTaskList drops every cached list page of the workspace
+	// regardless of query.
 	DeleteTaskList(ctx context.Context) error
+	// DeleteTasks drops the tasks with the given IDs and every list page of
+	// the workspace at once, for changes of many tasks.
+	DeleteTasks(ctx context.Context, ids ...string) error
 }
 
 type RedisTaskRepository struct {
@@ -28,30 +37,60 @@ func NewRedisTaskRepository(rdb *redis.Client) *RedisTaskRepository {
 	return &RedisTaskRepository{rdb: rdb}
 }
 
-func taskKey(id string) string {
-	return "task:" + id
+// workspacePrefix namespaces the keys of the workspace of ctx.
+func workspacePrefix(ctx context.Context) (string, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "ws:" + tn.WorkspaceID + ":", nil
+}
+
+func taskKey(ws, id string) string {
+	return ws + "task:" + id
 }
 
-const taskListKey = "tasks:list"
+func taskListKey(ws, key string) string {
+	return ws + "tasks:list:" + key
+}
+
+// taskListIndexKey is a set with the keys of all cached list pages of a
+// workspace, so that they can be invalidated together.
+func taskListIndexKey(ws string) string {
+	return ws + "tasks:list"
+}
+
+func countLookup(kind, result string) {
+	metrics.CacheRequests.WithLabelValues(kind, result).Inc()
+}
 
 func (r *RedisTaskRepository) GetTask(
 	ctx context.Context,
 	id string,
 ) (*models.Task, error) {
 
-	val, err := r.rdb.Get(ctx, taskKey(id)).Result()
+	ws, err := workspacePrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := r.rdb.Get(ctx, taskKey(ws, id)).Result()
 	if err == redis.Nil {
+		countLookup("task", metrics.CacheMiss)
 		return nil, nil // cache miss
 	}
 	if err != nil {
+		countLookup("task", metrics.CacheError)
 		return nil, err
 	}
 
 	var task models.Task
 	if err := json.Unmarshal([]byte(val), &task); err != nil {
+		countLookup("task", metrics.CacheError)
 		return nil, err
 	}
 
+	countLookup("task", metrics.CacheHit)
 	return &task, nil
 }
 
@@ -61,50 +100,105 @@ func (r *RedisTaskRepository) SetTask(
 	ttl time.Duration,
 ) error {
 
+	ws, err := workspacePrefix(ctx)
+	if err != nil {
+		return err
+	}
+
 	data, err := json.Marshal(task)
 	if err != nil {
 		return err
 	}
 
-	return r.rdb.Set(ctx, taskKey(task.ID.String()), data, ttl).Err()
+	return r.rdb.Set(ctx, taskKey(ws, task.ID.String()), data, ttl).Err()
 }
 
 func (r *RedisTaskRepository) DeleteTask(ctx context.Context, id string) error {
-	return r.rdb.Del(ctx, taskKey(id)).Err()
+	ws, err := workspacePrefix(ctx)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Del(ctx, taskKey(ws, id)).Err()
 }
 
 func (r *RedisTaskRepository) DeleteTaskList(ctx context.Context) error {
-	return r.rdb.Del(ctx, taskListKey).Err()
+	ws, err := workspacePrefix(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := r.rdb.SMembers(ctx, taskListIndexKey(ws)).Result()
+	if err != nil {
+		return err
+	}
+	return r.rdb.Del(ctx, append(keys, taskListIndexKey(ws))...).Err()
 }
 
-func (r *RedisTaskRepository) GetTaskList(ctx context.Context) ([]models.Task, error) {
-	val, err := r.rdb.Get(ctx, taskListKey).Result()
+func (r *RedisTaskRepository) DeleteTasks(ctx context.Context, ids ...string) error {
+	ws, err := workspacePrefix(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := r.rdb.SMembers(ctx, taskListIndexKey(ws)).Result()
+	if err != nil {
+		return err
+	}
+	keys = append(keys, taskListIndexKey(ws))
+	for _, id := range ids {
+		keys = append(keys, taskKey(ws, id))
+	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
+
+func (r *RedisTaskRepository) GetTaskList(ctx context.Context, key string) (*models.TaskPage, error) {
+	ws, err := workspacePrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := r.rdb.Get(ctx, taskListKey(ws, key)).Result()
 	if err == redis.Nil {
+		countLookup("task_list", metrics.CacheMiss)
 		return nil, nil
 	}
 	if err != nil {
+		countLookup("task_list", metrics.CacheError)
 		return nil, err
 	}
 
-	var tasks []models.Task
-	if err := json.Unmarshal([]byte(val), &tasks); err != nil {
+	var page models.TaskPage
+	if err := json.Unmarshal([]byte(val), &page); err != nil {
+		countLookup("task_list", metrics.CacheError)
 		return nil, err
 	}
 
-	return tasks, nil
+	countLookup("task_list", metrics.CacheHit)
+	return &page, nil
 }
 
 func (r *RedisTaskRepository) SetTaskList(
 	ctx context.Context,
-	tasks []models.Task,
+	key string,
+	page *models.TaskPage,
 	ttl time.Duration,
 ) error {
 
-	data, err := json.Marshal(tasks)
+	ws, err := workspacePrefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(page)
 	if err != nil {
 		return err
 	}
 
-	return r.rdb.Set(ctx, taskListKey, data, ttl).Err()
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, taskListKey(ws, key), data, ttl)
+		pipe.SAdd(ctx, taskListIndexKey(ws), taskListKey(ws, key))
+		// Every member expires within ttl, so the index may too.
+		pipe.Expire(ctx, taskListIndexKey(ws), ttl)
+		return nil
+	})
+	return err
 }
 
@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/kafka"
+	"github.com/lib/pq"
+)
+
+// PostgresScheduler finds open tasks whose remind or due time has passed and
+// records an event about each of them in the outbox, from where the relay
+// publishes it. Every task is notified once per time it is set to. It also
+// empties the trash of tasks deleted long enough ago, and the outbox of
+// messages sent long enough ago.
+type PostgresScheduler struct {
+	db *sql.DB
+}
+
+func NewPostgresScheduler(db *sql.DB) *PostgresScheduler {
+	return &PostgresScheduler{db: db}
+}
+
+// Remind records a task.reminder event for up to limit tasks whose remind
+// time has passed and returns how many it found.
+func (s *PostgresScheduler) Remind(ctx context.Context, limit int) (int, error) {
+	return s.notify(ctx, limit, kafka.EventTaskReminder, "remind_at", "reminded_at")
+}
+
+// NotifyOverdue records a task.overdue event for up to limit tasks whose due
+// time has passed and returns how many it found.
+func (s *PostgresScheduler) NotifyOverdue(ctx context.Context, limit int) (int, error) {
+	return s.notify(ctx, limit, kafka.EventTaskOverdue, "due_at", "overdue_notified_at")
+}
+
+// notify sends eventType for the open tasks with timeColumn in the past and
+// notifiedColumn unset, and sets notifiedColumn. The rows stay locked until
+// the events are stored, so concurrent schedulers never notify twice.
+func (s *PostgresScheduler) notify(ctx context.Context, limit int, eventType, timeColumn, notifiedColumn string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE deleted_at IS NULL AND `+openTask+` AND `+timeColumn+` <= now() AND `+notifiedColumn+` IS NULL
+		ORDER BY `+timeColumn+`
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		tasks []*models.Task
+		ids   []string
+	)
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		tasks = append(tasks, t)
+		ids = append(ids, t.ID.String())
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+
+	if err := loadItems(ctx, tx, tasks...); err != nil {
+		return 0, err
+	}
+	for _, t := range tasks {
+		if err := enqueueEvent(ctx, tx, eventType, t.ID, nil, t); err != nil {
+			return 0, err
+		}
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE tasks SET "+notifiedColumn+" = now() WHERE id = ANY($1::uuid[])", pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(tasks), nil
+}
+
+// PurgeTrash permanently deletes up to limit tasks that have been in the
+// trash for longer than retention, with their items and collaborators,
+// records a task.purged event for each and returns how many it deleted.
+func (s *PostgresScheduler) PurgeTrash(ctx context.Context, retention time.Duration, limit int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM tasks
+		WHERE id IN (
+			SELECT id FROM tasks
+			WHERE deleted_at <= now() - $1 * interval '1 second'
+			ORDER BY deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id`, retention.Seconds(), limit)
+	if err != nil {
+		return 0, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := enqueueEvent(ctx, tx, kafka.EventTaskPurged, id, nil, nil); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// PurgeOutbox deletes up to limit outbox messages that were sent longer
+// than retention ago and returns how many it deleted.
+func (s *PostgresScheduler) PurgeOutbox(ctx context.Context, retention time.Duration, limit int) (int, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM outbox
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE sent_at <= now() - $1 * interval '1 second'
+			ORDER BY sent_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED)`, retention.Seconds(), limit)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
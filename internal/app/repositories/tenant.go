@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+// ErrNoTenant is returned for task queries made without a principal in the
+// context, since every task belongs to some owner and workspace.
+var ErrNoTenant = errors.New("no principal in context")
+
+// Tenant is who a task query runs for. Tasks of other owners or workspaces
+// are invisible to it and look as if they did not exist.
+type Tenant struct {
+	OwnerID     string
+	WorkspaceID string
+}
+
+// TenantFromContext returns the tenant of the principal of ctx, or
+// ErrNoTenant if it has none.
+func TenantFromContext(ctx context.Context) (Tenant, error) {
+	p := auth.FromContext(ctx)
+	if p == nil || p.Subject == "" || p.WorkspaceID == "" {
+		return Tenant{}, ErrNoTenant
+	}
+	return Tenant{OwnerID: p.Subject, WorkspaceID: p.WorkspaceID}, nil
+}
+
+// Owns reports whether task belongs to t, in the trash or not.
+func (t Tenant) Owns(task models.Task) bool {
+	return task.OwnerID == t.OwnerID && task.WorkspaceID == t.WorkspaceID
+}
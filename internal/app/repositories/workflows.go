@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+// WorkflowRepository stores the workflow of the workspace of the principal of
+// ctx. Workspaces that configured none get models.DefaultWorkflow.
+type WorkflowRepository interface {
+	Workflow(ctx context.Context) (*models.Workflow, error)
+	// SetWorkflow replaces the transitions of the workspace, which must be
+	// distinct, and returns the resulting workflow. Setting no transitions
+	// restores the default.
+	SetWorkflow(ctx context.Context, transitions []models.Transition) (*models.Workflow, error)
+}
+
+// NewWorkflow returns the workflow of a workspace with the given stored
+// transitions, in the order of models.Statuses.
+func NewWorkflow(workspaceID string, transitions []models.Transition) *models.Workflow {
+	if len(transitions) == 0 {
+		return models.DefaultWorkflow(workspaceID)
+	}
+	wf := &models.Workflow{WorkspaceID: workspaceID, Transitions: slices.Clone(transitions)}
+	slices.SortFunc(wf.Transitions, func(a, b models.Transition) int {
+		if c := slices.Index(models.Statuses, a.From) - slices.Index(models.Statuses, b.From); c != 0 {
+			return c
+		}
+		return slices.Index(models.Statuses, a.To) - slices.Index(models.Statuses, b.To)
+	})
+	return wf
+}
+
+type PostgresWorkflowRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresWorkflowRepo(db *sql.DB) *PostgresWorkflowRepo {
+	return &PostgresWorkflowRepo{db: db}
+}
+
+func (r *PostgresWorkflowRepo) Workflow(ctx context.Context) (*models.Workflow, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT from_status, to_status FROM workflow_transitions WHERE workspace_id = $1", tn.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var transitions []models.Transition
+	for rows.Next() {
+		var t models.Transition
+		if err := rows.Scan(&t.From, &t.To); err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return NewWorkflow(tn.WorkspaceID, transitions), nil
+}
+
+func (r *PostgresWorkflowRepo) SetWorkflow(ctx context.Context, transitions []models.Transition) (*models.Workflow, error) {
+	tn, err := TenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM workflow_transitions WHERE workspace_id = $1", tn.WorkspaceID); err != nil {
+		return nil, err
+	}
+	for _, t := range transitions {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO workflow_transitions (workspace_id, from_status, to_status) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`, tn.WorkspaceID, t.From, t.To)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return NewWorkflow(tn.WorkspaceID, transitions), nil
+}
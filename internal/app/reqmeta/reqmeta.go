@@ -0,0 +1,111 @@
+// Package reqmeta carries request metadata, such as who made a request and
+// its correlation ID, from the API to the DB service.
+package reqmeta
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC metadata keys.
+const (
+	ActorKey           = "x-actor"
+	CorrelationIDKey   = "x-correlation-id"
+	ExpectedVersionKey = "x-expected-version"
+	IdempotencyKeyKey  = "x-idempotency-key"
+)
+
+// Reasons of the ErrorInfo details that tell some errors from others with
+// the same code.
+const (
+	// VersionMismatchReason marks the codes.FailedPrecondition errors
+	// returned when the task is not at the expected version.
+	VersionMismatchReason = "VERSION_MISMATCH"
+	// IdempotencyKeyReusedReason marks the codes.InvalidArgument errors
+	// returned when an idempotency key is sent again with another request.
+	IdempotencyKeyReusedReason = "IDEMPOTENCY_KEY_REUSED"
+)
+
+type Meta struct {
+	Actor         string
+	CorrelationID string
+	// ExpectedVersions, if not empty, are the versions one of which the task
+	// changed by the request must be at, such as the ones of the If-Match
+	// header.
+	ExpectedVersions []int64
+	// IdempotencyKey, if set, makes a retry of the request return the
+	// response to the first attempt instead of repeating it.
+	IdempotencyKey string
+}
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, m Meta) context.Context {
+	return context.WithValue(ctx, ctxKey{}, m)
+}
+
+func FromContext(ctx context.Context) Meta {
+	m, _ := ctx.Value(ctxKey{}).(Meta)
+	return m
+}
+
+// UnaryClientInterceptor sends the Meta of the call context as gRPC metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		m := FromContext(ctx)
+		var kv []string
+		if m.Actor != "" {
+			kv = append(kv, ActorKey, m.Actor)
+		}
+		if m.CorrelationID != "" {
+			kv = append(kv, CorrelationIDKey, m.CorrelationID)
+		}
+		for _, v := range m.ExpectedVersions {
+			kv = append(kv, ExpectedVersionKey, strconv.FormatInt(v, 10))
+		}
+		if m.IdempotencyKey != "" {
+			kv = append(kv, IdempotencyKeyKey, m.IdempotencyKey)
+		}
+		if len(kv) > 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryServerInterceptor restores the Meta sent by UnaryClientInterceptor.
+// Calls with a malformed expected version fail with codes.InvalidArgument.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		m, err := fromMetadata(ctx)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return handler(NewContext(ctx, m), req)
+	}
+}
+
+func fromMetadata(ctx context.Context) (Meta, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	m := Meta{Actor: first(ActorKey), CorrelationID: first(CorrelationIDKey), IdempotencyKey: first(IdempotencyKeyKey)}
+	for _, v := range md.Get(ExpectedVersionKey) {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return Meta{}, fmt.Errorf("malformed %s %q", ExpectedVersionKey, v)
+		}
+		m.ExpectedVersions = append(m.ExpectedVersions, n)
+	}
+	return m, nil
+}
@@ -0,0 +1,66 @@
+package reqmeta
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestInterceptorsRoundTrip(t *testing.T) {
+	sent := Meta{Actor: "alice", CorrelationID: "corr-1", ExpectedVersions: []int64{2, 3}, IdempotencyKey: "key-1"}
+
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	err := UnaryClientInterceptor()(NewContext(context.Background(), sent), "/checklist.TaskService/Create", nil, nil, nil, invoker)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	var received Meta
+	handler := func(ctx context.Context, req any) (any, error) {
+		received = FromContext(ctx)
+		return nil, nil
+	}
+	serverCtx := metadata.NewIncomingContext(context.Background(), outgoing)
+	if _, err := UnaryServerInterceptor()(serverCtx, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if !reflect.DeepEqual(received, sent) {
+		t.Fatalf("метаданные не дошли до сервера: отправлено %+v, получено %+v", sent, received)
+	}
+}
+
+func TestServerInterceptorWithoutMetadata(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		if m := FromContext(ctx); !reflect.DeepEqual(m, Meta{}) {
+			t.Errorf("ожидались пустые метаданные, получено %+v", m)
+		}
+		return nil, nil
+	}
+
+	if _, err := UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+}
+
+func TestServerInterceptorMalformedVersion(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		t.Error("обработчик не должен вызываться")
+		return nil, nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ExpectedVersionKey, "abc"))
+
+	_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("ожидался InvalidArgument, получено %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+)
+
+// apiKeyPrefixLen is how much of an issued key is kept in clear text to
+// recognise it later: "ck_" and five random characters.
+const apiKeyPrefixLen = 8
+
+type AuthService struct {
+	keys repositories.APIKeyRepository
+	jwt  *auth.JWTVerifier
+}
+
+// NewAuthService accepts a nil jwt, in which case only API keys work.
+func NewAuthService(keys repositories.APIKeyRepository, jwt *auth.JWTVerifier) *AuthService {
+	return &AuthService{keys: keys, jwt: jwt}
+}
+
+// Authenticate implements auth.Authenticator.
+func (s *AuthService) Authenticate(ctx context.Context, c auth.Credential) (*auth.Principal, error) {
+	switch c.Scheme {
+	case auth.SchemeBearer:
+		if s.jwt == nil {
+			return nil, fmt.Errorf("%w: bearer tokens are not accepted", ErrUnauthenticated)
+		}
+		return s.jwt.Verify(c.Value)
+	case auth.SchemeAPIKey:
+		if !auth.ValidAPIKeyFormat(c.Value) {
+			return nil, fmt.Errorf("%w: malformed api key", ErrUnauthenticated)
+		}
+		key, err := s.keys.GetActiveByHash(ctx, auth.HashAPIKey(c.Value))
+		if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+			return nil, fmt.Errorf("%w: unknown or revoked api key", ErrUnauthenticated)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Keys administer no shared workspace, even if their owner does.
+		return &auth.Principal{
+			Subject:     key.Owner,
+			WorkspaceID: key.WorkspaceID,
+			Scheme:      auth.SchemeAPIKey,
+			Admin:       key.WorkspaceID == auth.PersonalWorkspace(key.Owner),
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported scheme %q", ErrUnauthenticated, c.Scheme)
+}
+
+// CreateAPIKey issues a key owned by the principal of ctx, acting in its
+// current workspace. The returned key cannot be recovered later. Only
+// principals authenticated with a bearer token may issue keys, so that a
+// leaked key cannot be used to mint more of them.
+func (s *AuthService) CreateAPIKey(ctx context.Context, name string) (*models.APIKey, string, error) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return nil, "", ErrUnauthenticated
+	}
+	if p.Scheme != auth.SchemeBearer {
+		return nil, "", fmt.Errorf("%w: api keys can only be created with a bearer token", ErrPermissionDenied)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: api key name must not be empty", ErrInvalidArgument)
+	}
+
+	secret, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key := &models.APIKey{
+		Owner:       p.Subject,
+		WorkspaceID: p.WorkspaceID,
+		Name:        name,
+		Prefix:      secret[:apiKeyPrefixLen],
+	}
+	if err := s.keys.Create(ctx, key, auth.HashAPIKey(secret)); err != nil {
+		return nil, "", fromRepo(err)
+	}
+	return key, secret, nil
+}
+
+// RevokeAPIKey revokes a key of the principal of ctx. Keys of other owners
+// are reported as not found.
+func (s *AuthService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return ErrUnauthenticated
+	}
+	if err := s.keys.Revoke(ctx, id, p.Subject); err != nil {
+		return fromRepo(err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/app/reqmeta"
+)
+
+// maxBatchSize bounds the items of a batch, which all run in one
+// transaction.
+const maxBatchSize = 100
+
+// BatchCreate is Create of every draft, in one transaction. The results are
+// in the order of drafts, see repositories.TaskRepository.BatchCreate. An
+// empty mode means models.BatchAllOrNothing.
+func (s *TaskService) BatchCreate(ctx context.Context, drafts []models.Task, mode models.BatchMode) ([]models.BatchResult, error) {
+	mode, err := validateBatch(len(drafts), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.BatchResult, len(drafts))
+	tasks := make([]*models.Task, len(drafts))
+	for i, draft := range drafts {
+		tasks[i], results[i].Err = newTask(draft)
+	}
+	results, err = s.runBatch(ctx, results, mode, func(ctx context.Context, pending []int) ([]models.BatchResult, error) {
+		batch := make([]*models.Task, len(pending))
+		for j, i := range pending {
+			batch[j] = tasks[i]
+		}
+		return s.repo.BatchCreate(ctx, batch, mode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// New tasks are not cached yet, only the list pages are stale.
+	s.invalidateBatch(ctx, nil)
+
+	return results, nil
+}
+
+// BatchMarkDone is MarkDone of every task in ids, in one transaction. The
+// results hold the tasks after the change, see BatchCreate.
+func (s *TaskService) BatchMarkDone(ctx context.Context, ids []uuid.UUID, mode models.BatchMode) ([]models.BatchResult, error) {
+	mode, err := validateBatch(len(ids), mode)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := s.workflows.Workflow(ctx)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+
+	results := make([]models.BatchResult, len(ids))
+	results, err = s.runBatch(ctx, results, mode, func(ctx context.Context, pending []int) ([]models.BatchResult, error) {
+		return s.repo.BatchTransition(ctx, pick(ids, pending), models.StatusDone, wf, nextOccurrence, mode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateBatch(ctx, ids)
+
+	return results, nil
+}
+
+// BatchDelete is Delete of every task in ids, in one transaction, see
+// BatchCreate.
+func (s *TaskService) BatchDelete(ctx context.Context, ids []uuid.UUID, mode models.BatchMode) ([]models.BatchResult, error) {
+	mode, err := validateBatch(len(ids), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.BatchResult, len(ids))
+	results, err = s.runBatch(ctx, results, mode, func(ctx context.Context, pending []int) ([]models.BatchResult, error) {
+		return s.repo.BatchDelete(ctx, pick(ids, pending), mode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateBatch(ctx, ids)
+
+	return results, nil
+}
+
+// validateBatch checks the size of a batch of n items and returns its mode,
+// with the default applied.
+func validateBatch(n int, mode models.BatchMode) (models.BatchMode, error) {
+	switch {
+	case n == 0:
+		return "", fmt.Errorf("%w: a batch needs at least one item", ErrInvalidArgument)
+	case n > maxBatchSize:
+		return "", fmt.Errorf("%w: a batch holds at most %d items, got %d", ErrInvalidArgument, maxBatchSize, n)
+	case mode == "":
+		return models.BatchAllOrNothing, nil
+	case !mode.Valid():
+		return "", fmt.Errorf("%w: batch mode must be %s or %s, got %q", ErrInvalidArgument, models.BatchAllOrNothing, models.BatchBestEffort, mode)
+	}
+	return mode, nil
+}
+
+// runBatch passes the items of a batch that have not failed the checks of
+// the service to run, by their indexes, and merges what it returns into
+// results. In all-or-nothing mode an item that failed a check aborts the
+// batch before anything is stored. The expected versions of ctx are for one
+// task, so they do not apply to batches.
+func (s *TaskService) runBatch(ctx context.Context, results []models.BatchResult, mode models.BatchMode, run func(ctx context.Context, pending []int) ([]models.BatchResult, error)) ([]models.BatchResult, error) {
+	var pending []int
+	for i, r := range results {
+		if r.Err == nil {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+	if len(pending) < len(results) && mode == models.BatchAllOrNothing {
+		for _, i := range pending {
+			results[i].Err = fmt.Errorf("%w: another item of the batch failed", ErrBatchAborted)
+		}
+		return results, nil
+	}
+
+	meta := reqmeta.FromContext(ctx)
+	meta.ExpectedVersions = nil
+	stored, err := run(reqmeta.NewContext(ctx, meta), pending)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	for j, i := range pending {
+		results[i] = models.BatchResult{Task: stored[j].Task, Err: fromRepo(stored[j].Err)}
+	}
+	return results, nil
+}
+
+// invalidateBatch is invalidate of every task in ids, in a single call to
+// the cache.
+func (s *TaskService) invalidateBatch(ctx context.Context, ids []uuid.UUID) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = id.String()
+	}
+	_ = s.cache.DeleteTasks(context.WithoutCancel(ctx), keys...)
+}
+
+// pick returns the ids at the given indexes.
+func pick(ids []uuid.UUID, indexes []int) []uuid.UUID {
+	picked := make([]uuid.UUID, len(indexes))
+	for j, i := range indexes {
+		picked[j] = ids[i]
+	}
+	return picked
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/repositories"
+)
+
+// Domain errors returned by the services. Callers should match them with
+// errors.Is, since they are usually wrapped with details.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrConflict        = errors.New("conflict")
+	// ErrPermissionDenied means the caller sees the task but its role does
+	// not allow the operation, or its credential does not.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrFailedPrecondition means the task is in a state that does not
+	// allow the operation, such as a status it cannot move from.
+	ErrFailedPrecondition = errors.New("failed precondition")
+	// ErrVersionMismatch means the task is not at the version the caller
+	// expected, because it was changed in the meantime.
+	ErrVersionMismatch = errors.New("version mismatch")
+	// ErrBatchAborted is the result of the items of an all-or-nothing batch
+	// that were not applied because another item failed.
+	ErrBatchAborted = errors.New("batch aborted")
+	// ErrUnauthenticated is auth.ErrUnauthenticated, so that either matches.
+	ErrUnauthenticated = auth.ErrUnauthenticated
+)
+
+// ParseID parses a task ID coming from a client.
+func ParseID(raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: malformed task id %q", ErrInvalidArgument, raw)
+	}
+	return id, nil
+}
+
+// ParseItemID parses a checklist item ID coming from a client.
+func ParseItemID(raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: malformed item id %q", ErrInvalidArgument, raw)
+	}
+	return id, nil
+}
+
+// ParseAPIKeyID parses an API key ID coming from a client.
+func ParseAPIKeyID(raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: malformed api key id %q", ErrInvalidArgument, raw)
+	}
+	return id, nil
+}
+
+// fromRepo translates storage errors into domain errors.
+func fromRepo(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return fmt.Errorf("task %w", ErrNotFound)
+	case errors.Is(err, repositories.ErrItemNotFound):
+		return fmt.Errorf("item %w", ErrNotFound)
+	case errors.Is(err, repositories.ErrInvalidItemOrder):
+		return fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	case errors.Is(err, repositories.ErrPermissionDenied):
+		return fmt.Errorf("%w: %v", ErrPermissionDenied, err)
+	case errors.Is(err, repositories.ErrCollaboratorNotFound):
+		return fmt.Errorf("collaborator %w", ErrNotFound)
+	case errors.Is(err, repositories.ErrAPIKeyNotFound):
+		return fmt.Errorf("api key %w", ErrNotFound)
+	case errors.Is(err, repositories.ErrInvalidCursor):
+		return fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	case errors.Is(err, repositories.ErrRecurrenceWithoutDue):
+		return fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	case errors.Is(err, repositories.ErrTransitionNotAllowed):
+		return fmt.Errorf("%w: %v", ErrFailedPrecondition, err)
+	case errors.Is(err, repositories.ErrVersionMismatch):
+		return fmt.Errorf("%w: %v", ErrVersionMismatch, err)
+	case errors.Is(err, repositories.ErrBatchAborted):
+		return fmt.Errorf("%w: another item of the batch failed", ErrBatchAborted)
+	case errors.Is(err, repositories.ErrConflict):
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	case errors.Is(err, repositories.ErrNoTenant):
+		return fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
+	"github.com/kalpovskii/checklist/internal/app/models"
+)
+
+// History returns one page of the history of a task, newest change first.
+// Anyone who may view the task may read its history. Of q only the page
+// size and token are used.
+func (s *TaskService) History(ctx context.Context, id uuid.UUID, q models.HistoryQuery) (*models.TaskEventPage, error) {
+	size, err := pageSize(q.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.history.Events(ctx, models.HistoryQuery{PageSize: size, PageToken: q.PageToken, TaskID: &id, Visible: true})
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	return page, nil
+}
+
+// Audit returns one page of the history of every task of the workspace of
+// the caller, who must administer it, filtered by q. It includes the tasks
+// that were deleted since.
+func (s *TaskService) Audit(ctx context.Context, q models.HistoryQuery) (*models.TaskEventPage, error) {
+	var err error
+	if q.PageSize, err = pageSize(q.PageSize); err != nil {
+		return nil, err
+	}
+	if q.Since != nil && q.Until != nil && !q.Since.Before(*q.Until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidArgument)
+	}
+	q.Actor = strings.TrimSpace(q.Actor)
+	if p := auth.FromContext(ctx); p != nil && !p.Admin {
+		return nil, fmt.Errorf("%w: only administrators may read the audit log of a workspace", ErrPermissionDenied)
+	}
+
+	page, err := s.history.Events(ctx, q)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	return page, nil
+}
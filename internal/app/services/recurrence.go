@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	// Time zones of recurring tasks must load in containers without
+	// zoneinfo too.
+	_ "time/tzdata"
+
+	"github.com/kalpovskii/checklist/internal/app/models"
+	"github.com/kalpovskii/checklist/internal/rrule"
+)
+
+// normalizeRecurrence checks a recurrence rule and returns it in canonical
+// form. An empty rule stays empty.
+func normalizeRecurrence(recurrence string) (string, error) {
+	if recurrence == "" {
+		return "", nil
+	}
+	rule, err := rrule.Parse(recurrence)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+	return rule.String(), nil
+}
+
+// validateTimeZone accepts IANA time zone names, and empty for UTC.
+func validateTimeZone(name string) error {
+	if _, err := time.LoadLocation(name); err != nil || name == "Local" {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidArgument, name)
+	}
+	return nil
+}
+
+// nextOccurrence is the repositories.NextOccurrence of recurring tasks. The
+// next occurrence is due at the next time of the rule after the due time of
+// task, in its time zone. It is reminded of as long before that as task was,
+// and has the items of task, undone. Tasks without a due time do not recur.
+func nextOccurrence(task *models.Task) (*models.Task, error) {
+	if task.Recurrence == "" || task.DueAt == nil {
+		return nil, nil
+	}
+	rule, err := rrule.Parse(task.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("recurrence of task %s: %w", task.ID, err)
+	}
+	// An empty name loads UTC.
+	loc, err := time.LoadLocation(task.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("time zone of task %s: %w", task.ID, err)
+	}
+
+	due, rest, ok := rule.Next(task.DueAt.In(loc))
+	if !ok {
+		return nil, nil
+	}
+	next := &models.Task{
+		Title:        task.Title,
+		Content:      task.Content,
+		AutoComplete: task.AutoComplete,
+		DueAt:        &due,
+		Recurrence:   rest.String(),
+		TimeZone:     task.TimeZone,
+	}
+	if task.RemindAt != nil {
+		remindAt := due.Add(task.RemindAt.Sub(*task.DueAt))
+		next.RemindAt = &remindAt
+	}
+	for _, it := range task.Items {
+		next.Items = append(next.Items, models.TaskItem{Text: it.Text})
+	}
+	return next, nil
+}
@@ -2,9 +2,15 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kalpovskii/checklist/internal/app/auth"
 	"github.com/kalpovskii/checklist/internal/app/models"
 	"github.com/kalpovskii/checklist/internal/app/repositories"
 )
@@ -12,31 +18,50 @@ import (
 const (
 	taskTTL     = 60 * time.Second
 	taskListTTL = 15 * time.Second
+
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
 )
 
+// TaskService enforces the role of the caller on each task: viewers may
+// read it, editors also update it and move it through the workflow, and
+// only its owner may delete or share it.
 type TaskService struct {
-	repo  repositories.TaskRepository
-	cache repositories.TaskCache
+	repo      repositories.TaskRepository
+	perms     repositories.PermissionRepository
+	workflows repositories.WorkflowRepository
+	history   repositories.HistoryRepository
+	cache     repositories.TaskCache
 }
 
-func NewTaskService(repo repositories.TaskRepository, cache repositories.TaskCache) *TaskService {
+func NewTaskService(repo repositories.TaskRepository, perms repositories.PermissionRepository, workflows repositories.WorkflowRepository, history repositories.HistoryRepository, cache repositories.TaskCache) *TaskService {
 	return &TaskService{
-		repo:  repo,
-		cache: cache,
+		repo:      repo,
+		perms:     perms,
+		workflows: workflows,
+		history:   history,
+		cache:     cache,
 	}
 }
 
-func (s *TaskService) Create(title, content string) (*models.Task, error) {
-	task := &models.Task{
-		Title:   title,
-		Content: content,
+// Create stores a new task owned by the principal of ctx. Of draft only the
+// fields a client may set are used: Title, Content, AutoComplete, DueAt,
+// RemindAt, Recurrence and TimeZone. A recurring task needs a due time.
+func (s *TaskService) Create(ctx context.Context, draft models.Task) (*models.Task, error) {
+	task, err := newTask(draft)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Create(task); err != nil {
-		return nil, err
+	if err := s.repo.Create(ctx, task); err != nil {
+		return nil, fromRepo(err)
 	}
 
-	ctx := context.Background()
+	// The task is stored, so update the cache even if the caller is gone.
+	ctx = context.WithoutCancel(ctx)
 
 	_ = s.cache.SetTask(ctx, task, taskTTL)
 	_ = s.cache.DeleteTaskList(ctx)
@@ -44,45 +69,497 @@ func (s *TaskService) Create(title, content string) (*models.Task, error) {
 	return task, nil
 }
 
-func (s *TaskService) List() ([]models.Task, error) {
-	ctx := context.Background()
+// newTask validates draft and returns the task to store for it, see Create.
+func newTask(draft models.Task) (*models.Task, error) {
+	if err := validateTitle(draft.Title); err != nil {
+		return nil, err
+	}
+	recurrence, err := normalizeRecurrence(draft.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	if recurrence != "" && draft.DueAt == nil {
+		return nil, fmt.Errorf("%w: a recurring task needs a due time", ErrInvalidArgument)
+	}
+	if err := validateTimeZone(draft.TimeZone); err != nil {
+		return nil, err
+	}
+	return &models.Task{
+		Title:        draft.Title,
+		Content:      draft.Content,
+		AutoComplete: draft.AutoComplete,
+		DueAt:        draft.DueAt,
+		RemindAt:     draft.RemindAt,
+		Recurrence:   recurrence,
+		TimeZone:     draft.TimeZone,
+	}, nil
+}
+
+func (s *TaskService) List(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+	var err error
+	if q.PageSize, err = pageSize(q.PageSize); err != nil {
+		return nil, err
+	}
+	q.Trashed = false
+	switch {
+	case q.DueWithin < 0:
+		return nil, fmt.Errorf("%w: due within must not be negative", ErrInvalidArgument)
+	case q.Overdue && q.DueWithin > 0:
+		return nil, fmt.Errorf("%w: overdue and due within cannot be combined", ErrInvalidArgument)
+	}
+	for _, status := range q.Statuses {
+		if err := validateStatus(status); err != nil {
+			return nil, err
+		}
+	}
 
-	if tasks, err := s.cache.GetTaskList(ctx); err == nil && tasks != nil {
-		return tasks, nil
+	// Which tasks are overdue or due soon changes with the clock, so such
+	// pages would go stale before they expire.
+	if q.Overdue || q.DueWithin > 0 {
+		page, err := s.repo.List(ctx, q)
+		if err != nil {
+			return nil, fromRepo(err)
+		}
+		return page, nil
 	}
 
-	tasks, err := s.repo.List()
+	key := listCacheKey(auth.FromContext(ctx), q)
+
+	if page, err := s.cache.GetTaskList(ctx, key); err == nil && page != nil {
+		return page, nil
+	}
+
+	page, err := s.repo.List(ctx, q)
 	if err != nil {
-		return nil, err
+		return nil, fromRepo(err)
 	}
 
-	_ = s.cache.SetTaskList(ctx, tasks, taskListTTL)
+	_ = s.cache.SetTaskList(ctx, key, page, taskListTTL)
 
-	return tasks, nil
+	return page, nil
 }
 
-func (s *TaskService) Delete(id uuid.UUID) error {
-	if err := s.repo.Delete(id); err != nil {
-		return err
+// Get returns a single task, reading through the per-task cache. The cache
+// is shared by a workspace, so a cached task is only returned to its owner.
+func (s *TaskService) Get(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	if task, err := s.cache.GetTask(ctx, id.String()); err == nil && task != nil && ownedBy(ctx, task) {
+		return task, nil
 	}
 
-	ctx := context.Background()
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
 
-	_ = s.cache.DeleteTask(ctx, id.String())
-	_ = s.cache.DeleteTaskList(ctx)
+	_ = s.cache.SetTask(ctx, task, taskTTL)
+
+	return task, nil
+}
+
+// Search runs a full-text query over task titles and content. Results are
+// not cached since queries rarely repeat.
+func (s *TaskService) Search(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("%w: search query must not be empty", ErrInvalidArgument)
+	}
+	switch {
+	case limit < 0:
+		return nil, fmt.Errorf("%w: page size must not be negative", ErrInvalidArgument)
+	case limit == 0:
+		limit = defaultSearchLimit
+	case limit > maxSearchLimit:
+		limit = maxSearchLimit
+	}
+
+	results, err := s.repo.Search(ctx, query, limit)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	return results, nil
+}
+
+// Delete moves a task to the trash. It disappears for its collaborators
+// and is purged for good once the trash retention period is over.
+func (s *TaskService) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fromRepo(err)
+	}
+
+	s.invalidate(ctx, id)
 
 	return nil
 }
 
-func (s *TaskService) MarkDone(id uuid.UUID) error {
-	if err := s.repo.MarkDone(id); err != nil {
+// Restore takes a task of the caller out of the trash. Only the owner of a
+// task sees it in the trash, so nobody else may restore it.
+func (s *TaskService) Restore(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	task, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+
+	s.invalidate(ctx, id)
+
+	return task, nil
+}
+
+// ListTrash returns one page of the deleted tasks of the caller, which are
+// kept until the retention period is over, newest task first. Of q only the
+// page size and token are used. The trash is not cached.
+func (s *TaskService) ListTrash(ctx context.Context, q models.TaskListQuery) (*models.TaskPage, error) {
+	size, err := pageSize(q.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.repo.List(ctx, models.TaskListQuery{
+		PageSize:   size,
+		PageToken:  q.PageToken,
+		Descending: true,
+		Trashed:    true,
+	})
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	return page, nil
+}
+
+// Transition moves a task to another status, if the workflow of its
+// workspace allows it. When a recurring task becomes done its next
+// occurrence is created along with it.
+func (s *TaskService) Transition(ctx context.Context, id uuid.UUID, to models.Status) (*models.Task, error) {
+	if err := validateStatus(to); err != nil {
+		return nil, err
+	}
+
+	task, err := s.transition(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate(ctx, id)
+
+	return task, nil
+}
+
+// Reopen moves a done or cancelled task back to todo, if the workflow
+// allows it. Open tasks are returned unchanged.
+func (s *TaskService) Reopen(ctx context.Context, id uuid.UUID) (*models.Task, error) {
+	wf, err := s.workflows.Workflow(ctx)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	task, err := s.repo.Reopen(ctx, id, wf)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+
+	s.invalidate(ctx, id)
+
+	return task, nil
+}
+
+// MarkDone is Transition to models.StatusDone.
+func (s *TaskService) MarkDone(ctx context.Context, id uuid.UUID) error {
+	_, err := s.Transition(ctx, id, models.StatusDone)
+	return err
+}
+
+// transition moves a task along the workflow of the workspace of ctx. The
+// repository checks that the caller is an editor of the task.
+func (s *TaskService) transition(ctx context.Context, id uuid.UUID, to models.Status) (*models.Task, error) {
+	wf, err := s.workflows.Workflow(ctx)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	task, err := s.repo.Transition(ctx, id, to, wf, nextOccurrence)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	return task, nil
+}
+
+func (s *TaskService) Update(ctx context.Context, id uuid.UUID, patch models.TaskPatch) (*models.Task, error) {
+	if patch.Title != nil {
+		if err := validateTitle(*patch.Title); err != nil {
+			return nil, err
+		}
+	}
+	if patch.Recurrence != nil {
+		recurrence, err := normalizeRecurrence(*patch.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+		patch.Recurrence = &recurrence
+	}
+	if patch.TimeZone != nil {
+		if err := validateTimeZone(*patch.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := s.repo.Update(ctx, id, patch)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+
+	s.invalidate(ctx, id)
+
+	return task, nil
+}
+
+// AddItem appends a checklist item to a task.
+func (s *TaskService) AddItem(ctx context.Context, taskID uuid.UUID, text string) (*models.Task, error) {
+	if err := validateItemText(text); err != nil {
+		return nil, err
+	}
+
+	task, err := s.repo.AddItem(ctx, taskID, &models.TaskItem{Text: text})
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+
+	s.invalidate(ctx, taskID)
+
+	return task, nil
+}
+
+// UpdateItem changes an item of a task. Checking off the last open item
+// completes a task with AutoComplete set.
+func (s *TaskService) UpdateItem(ctx context.Context, taskID, itemID uuid.UUID, patch models.TaskItemPatch) (*models.Task, error) {
+	if patch.Text != nil {
+		if err := validateItemText(*patch.Text); err != nil {
+			return nil, err
+		}
+	}
+	var wf *models.Workflow
+	if patch.Done != nil && *patch.Done {
+		var err error
+		if wf, err = s.workflows.Workflow(ctx); err != nil {
+			return nil, fromRepo(err)
+		}
+	}
+
+	task, err := s.repo.UpdateItem(ctx, taskID, itemID, patch, wf, nextOccurrence)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+
+	s.invalidate(ctx, taskID)
+
+	return task, nil
+}
+
+// ReorderItems puts the items of a task in the order of itemIDs, which must
+// list each of them once.
+func (s *TaskService) ReorderItems(ctx context.Context, taskID uuid.UUID, itemIDs []uuid.UUID) (*models.Task, error) {
+	task, err := s.repo.ReorderItems(ctx, taskID, itemIDs)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+
+	s.invalidate(ctx, taskID)
+
+	return task, nil
+}
+
+// RemoveItem deletes an item of a task. Removing the last open item
+// completes a task with AutoComplete set, like checking it off would.
+func (s *TaskService) RemoveItem(ctx context.Context, taskID, itemID uuid.UUID) (*models.Task, error) {
+	wf, err := s.workflows.Workflow(ctx)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	task, err := s.repo.RemoveItem(ctx, taskID, itemID, wf, nextOccurrence)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+
+	s.invalidate(ctx, taskID)
+
+	return task, nil
+}
+
+// Workflow returns the workflow of the workspace of the caller.
+func (s *TaskService) Workflow(ctx context.Context) (*models.Workflow, error) {
+	wf, err := s.workflows.Workflow(ctx)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	return wf, nil
+}
+
+// SetWorkflow replaces the transitions of the workspace of the caller, who
+// must administer it. No transitions restore the default workflow. Tasks
+// keep their status even if it can no longer be reached.
+func (s *TaskService) SetWorkflow(ctx context.Context, transitions []models.Transition) (*models.Workflow, error) {
+	var distinct []models.Transition
+	for _, t := range transitions {
+		if err := validateStatus(t.From); err != nil {
+			return nil, err
+		}
+		if err := validateStatus(t.To); err != nil {
+			return nil, err
+		}
+		if t.From == t.To {
+			return nil, fmt.Errorf("%w: transition from %s to itself", ErrInvalidArgument, t.From)
+		}
+		if !slices.Contains(distinct, t) {
+			distinct = append(distinct, t)
+		}
+	}
+	if p := auth.FromContext(ctx); p != nil && !p.Admin {
+		return nil, fmt.Errorf("%w: only administrators may change the workflow of a workspace", ErrPermissionDenied)
+	}
+
+	wf, err := s.workflows.SetWorkflow(ctx, distinct)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	return wf, nil
+}
+
+// Share grants role on a task to another user of the workspace, or changes
+// the role they have. Only the owner may share a task.
+func (s *TaskService) Share(ctx context.Context, id uuid.UUID, userID string, role models.Role) (*models.Collaborator, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user id must not be empty", ErrInvalidArgument)
+	}
+	if !role.Grantable() {
+		return nil, fmt.Errorf("%w: role must be %s or %s, got %q", ErrInvalidArgument, models.RoleViewer, models.RoleEditor, role)
+	}
+	if err := s.authorize(ctx, id, models.RoleOwner); err != nil {
+		return nil, err
+	}
+	if userID == auth.FromContext(ctx).Subject {
+		return nil, fmt.Errorf("%w: the owner cannot be a collaborator", ErrInvalidArgument)
+	}
+
+	c := &models.Collaborator{TaskID: id, UserID: userID, Role: role}
+	if err := s.perms.Share(ctx, c); err != nil {
+		return nil, fromRepo(err)
+	}
+
+	// The task now appears in the lists of the collaborator.
+	_ = s.cache.DeleteTaskList(context.WithoutCancel(ctx))
+
+	return c, nil
+}
+
+// Unshare revokes the role of a collaborator. Only the owner may do it.
+func (s *TaskService) Unshare(ctx context.Context, id uuid.UUID, userID string) error {
+	if err := s.authorize(ctx, id, models.RoleOwner); err != nil {
 		return err
 	}
+	if err := s.perms.Unshare(ctx, id, userID); err != nil {
+		return fromRepo(err)
+	}
+
+	_ = s.cache.DeleteTaskList(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+// ListCollaborators returns the users a task is shared with, not including
+// its owner.
+func (s *TaskService) ListCollaborators(ctx context.Context, id uuid.UUID) ([]models.Collaborator, error) {
+	if err := s.authorize(ctx, id, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	collaborators, err := s.perms.ListCollaborators(ctx, id)
+	if err != nil {
+		return nil, fromRepo(err)
+	}
+	return collaborators, nil
+}
+
+// authorize checks that the principal of ctx has at least role need on a
+// task. Tasks it has no role on at all are reported as not found. The check
+// is a query of its own, so it only guards the calls that do not change a
+// task; the changes are checked by the repository, under the lock of the
+// task, see repositories.TaskRepository.
+func (s *TaskService) authorize(ctx context.Context, id uuid.UUID, need models.Role) error {
+	role, err := s.perms.Role(ctx, id)
+	if err != nil {
+		return fromRepo(err)
+	}
+	if !role.Includes(need) {
+		return fmt.Errorf("%w: %s role required, have %s", ErrPermissionDenied, need, role)
+	}
+	return nil
+}
 
-	ctx := context.Background()
+// invalidate drops the cached task and every cached list page after a write.
+// The write has already happened, so it runs even if ctx is cancelled.
+func (s *TaskService) invalidate(ctx context.Context, id uuid.UUID) {
+	ctx = context.WithoutCancel(ctx)
 
 	_ = s.cache.DeleteTask(ctx, id.String())
 	_ = s.cache.DeleteTaskList(ctx)
+}
+
+// ownedBy reports whether task belongs to the principal of ctx.
+func ownedBy(ctx context.Context, task *models.Task) bool {
+	p := auth.FromContext(ctx)
+	return p != nil && task.OwnerID == p.Subject && task.WorkspaceID == p.WorkspaceID
+}
+
+// pageSize applies the default and the maximum to a requested page size.
+func pageSize(n int) (int, error) {
+	switch {
+	case n < 0:
+		return 0, fmt.Errorf("%w: page size must not be negative", ErrInvalidArgument)
+	case n == 0:
+		return defaultPageSize, nil
+	case n > maxPageSize:
+		return maxPageSize, nil
+	}
+	return n, nil
+}
+
+func validateTitle(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return fmt.Errorf("%w: title must not be empty", ErrInvalidArgument)
+	}
+	return nil
+}
+
+func validateStatus(status models.Status) error {
+	if !status.Valid() {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidArgument, status)
+	}
+	return nil
+}
 
+func validateItemText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("%w: item text must not be empty", ErrInvalidArgument)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// listCacheKey identifies a list query of p, so that pages of different
+// queries and of different users of a workspace are cached separately.
+// Queries relative to the current time are not cached, see List.
+func listCacheKey(p *auth.Principal, q models.TaskListQuery) string {
+	h := sha256.New()
+	if p != nil {
+		fmt.Fprintf(h, "owner=%s;", p.Subject)
+	}
+	fmt.Fprintf(h, "size=%d;token=%s;desc=%t", q.PageSize, q.PageToken, q.Descending)
+	if q.Done != nil {
+		fmt.Fprintf(h, ";done=%t", *q.Done)
+	}
+	if len(q.Statuses) > 0 {
+		statuses := slices.Clone(q.Statuses)
+		slices.Sort(statuses)
+		fmt.Fprintf(h, ";status=%v", statuses)
+	}
+	if q.CreatedAfter != nil {
+		fmt.Fprintf(h, ";after=%d", q.CreatedAfter.UnixNano())
+	}
+	if q.CreatedBefore != nil {
+		fmt.Fprintf(h, ";before=%d", q.CreatedBefore.UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
@@ -0,0 +1,76 @@
+package kafka
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// EventSchemaVersion is bumped on incompatible changes of Event.
+const EventSchemaVersion = 1
+
+const (
+	EventTaskCreated   = "task.created"
+	EventTaskViewed    = "task.viewed"
+	EventTaskUpdated   = "task.updated"
+	EventTaskDone      = "task.done"
+	EventTaskDeleted   = "task.deleted"
+	EventTasksListed   = "tasks.listed"
+	EventTasksSearched = "tasks.searched"
+	// Sent for transitions to any status but done, which sends task.done.
+	EventTaskStatusChanged = "task.status_changed"
+	// task.deleted moves a task to the trash, from where it is restored or,
+	// after the retention period, purged.
+	EventTaskRestored = "task.restored"
+	EventTaskPurged   = "task.purged"
+	// Sent by the scheduler when the remind or due time of a task passes.
+	EventTaskReminder = "task.reminder"
+	EventTaskOverdue  = "task.overdue"
+)
+
+// Event is the JSON envelope of every message on the events topic. Before
+// and After hold task snapshots when they are known to the producer.
+type Event struct {
+	SchemaVersion int             `json:"schema_version"`
+	ID            string          `json:"event_id"`
+	Type          string          `json:"type"`
+	TaskID        string          `json:"task_id,omitempty"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+	Actor         string          `json:"actor"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+}
+
+func NewEvent(eventType, taskID string) Event {
+	return Event{
+		SchemaVersion: EventSchemaVersion,
+		ID:            uuid.NewString(),
+		Type:          eventType,
+		TaskID:        taskID,
+		OccurredAt:    time.Now().UTC(),
+	}
+}
+
+// Message encodes the event. Messages are keyed by task ID, so that all
+// events of a task land in one partition and keep their order; events not
+// tied to a task are keyed by their own ID.
+func (e Event) Message() (kafka.Message, error) {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	key := e.TaskID
+	if key == "" {
+		key = e.ID
+	}
+	return kafka.Message{Key: []byte(key), Value: value, Time: e.OccurredAt}, nil
+}
+
+func DecodeEvent(data []byte) (Event, error) {
+	var e Event
+	err := json.Unmarshal(data, &e)
+	return e, err
+}
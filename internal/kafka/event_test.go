@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEventMessage(t *testing.T) {
+	t.Run("сообщение о задаче имеет ключ task_id", func(t *testing.T) {
+		event := NewEvent(EventTaskUpdated, "task-1")
+		event.After = json.RawMessage(`{"id":"task-1"}`)
+
+		msg, err := event.Message()
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if string(msg.Key) != "task-1" {
+			t.Errorf("неожиданный ключ: %s", msg.Key)
+		}
+
+		got, err := DecodeEvent(msg.Value)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if got.ID != event.ID || got.Type != EventTaskUpdated || got.SchemaVersion != EventSchemaVersion {
+			t.Errorf("неожиданный конверт: %+v", got)
+		}
+
+		if string(got.After) != `{"id":"task-1"}` {
+			t.Errorf("неожиданный снимок: %s", got.After)
+		}
+	})
+
+	t.Run("событие без задачи получает ключ event_id", func(t *testing.T) {
+		event := NewEvent(EventTasksListed, "")
+
+		msg, err := event.Message()
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+
+		if string(msg.Key) != event.ID {
+			t.Errorf("неожиданный ключ: %s", msg.Key)
+		}
+	})
+}
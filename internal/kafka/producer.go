@@ -2,10 +2,12 @@ package kafka
 
 import (
 	"context"
-	"log"
-	"time"
+	"errors"
 
+	"github.com/kalpovskii/checklist/internal/metrics"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Producer struct {
@@ -15,21 +17,82 @@ type Producer struct {
 func NewProducer(broker, topic string) *Producer {
 	return &Producer{
 		writer: &kafka.Writer{
-			Addr:     kafka.TCP(broker),
-			Topic:    topic,
-			Balancer: &kafka.LeastBytes{},
+			Addr:  kafka.TCP(broker),
+			Topic: topic,
+			// Hashing the key keeps the events of one task in one partition.
+			Balancer: &kafka.Hash{},
 		},
 	}
 }
 
-func (p *Producer) SendEvent(action string) {
-	msg := kafka.Message{
-		Key:   []byte(time.Now().Format(time.RFC3339Nano)),
-		Value: []byte(action),
-		Time:  time.Now(),
+// SendEvent writes the events in a single batch, carrying the trace context
+// of ctx in the message headers.
+func (p *Producer) SendEvent(ctx context.Context, events ...Event) error {
+	ctx, span := startProducerSpan(ctx, p.writer.Topic, len(events))
+	defer span.End()
+
+	msgs := make([]kafka.Message, 0, len(events))
+	for _, e := range events {
+		msg, err := e.Message()
+		if err != nil {
+			return err
+		}
+		injectTrace(ctx, &msg)
+		msgs = append(msgs, msg)
+	}
+
+	return p.write(ctx, span, msgs)
+}
+
+// SendMessages writes already encoded messages in a single batch. The trace
+// context the messages already carry is kept, and the send span is linked
+// to it.
+func (p *Producer) SendMessages(ctx context.Context, msgs ...kafka.Message) error {
+	links := make([]trace.Link, 0, len(msgs))
+	for _, msg := range msgs {
+		links = append(links, trace.LinkFromContext(extractTrace(context.Background(), msg)))
+	}
+	ctx, span := startProducerSpan(ctx, p.writer.Topic, len(msgs), trace.WithLinks(links...))
+	defer span.End()
+
+	return p.write(ctx, span, msgs)
+}
+
+func (p *Producer) write(ctx context.Context, span trace.Span, msgs []kafka.Message) error {
+	err := p.writer.WriteMessages(ctx, msgs...)
+	p.count(msgs, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// count records the outcome of a batch. The writer reports which messages
+// of a partially written batch failed through kafka.WriteErrors.
+func (p *Producer) count(msgs []kafka.Message, err error) {
+	failed := 0
+	var writeErrs kafka.WriteErrors
+	switch {
+	case err == nil:
+	case errors.As(err, &writeErrs):
+		failed = writeErrs.Count()
+	default:
+		failed = len(msgs)
 	}
+	metrics.KafkaProduced.WithLabelValues(p.writer.Topic).Add(float64(len(msgs) - failed))
+	metrics.KafkaProduceFailures.WithLabelValues(p.writer.Topic).Add(float64(failed))
+}
 
-	if err := p.writer.WriteMessages(context.Background(), msg); err != nil {
-		log.Println("failed to write kafka message:", err)
+// Ping checks that the broker accepts connections.
+func (p *Producer) Ping(ctx context.Context) error {
+	conn, err := kafka.DialContext(ctx, p.writer.Addr.Network(), p.writer.Addr.String())
+	if err != nil {
+		return err
 	}
-}
\ No newline at end of file
+	return conn.Close()
+}
+
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,110 @@
+package kafka
+
+import (
+	"context"
+	"sort"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/kalpovskii/checklist/internal/kafka"
+
+// headerCarrier lets the propagator read and write trace context in the
+// headers of a message.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(*c.headers))
+	for i, h := range *c.headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+// injectTrace writes the trace context of ctx into the headers of msg.
+func injectTrace(ctx context.Context, msg *kafka.Message) {
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{headers: &msg.Headers})
+}
+
+// extractTrace returns ctx carrying the trace context found in the headers
+// of msg.
+func extractTrace(ctx context.Context, msg kafka.Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier{headers: &msg.Headers})
+}
+
+// InjectTraceMap writes the trace context of ctx into a plain map, for
+// messages that are stored before being produced.
+func InjectTraceMap(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// TraceHeaders turns trace context saved by InjectTraceMap into message
+// headers.
+func TraceHeaders(stored map[string]string) []kafka.Header {
+	keys := make([]string, 0, len(stored))
+	for k := range stored {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	headers := make([]kafka.Header, 0, len(keys))
+	for _, k := range keys {
+		headers = append(headers, kafka.Header{Key: k, Value: []byte(stored[k])})
+	}
+	return headers
+}
+
+// startProducerSpan starts the span of producing count messages to topic.
+func startProducerSpan(ctx context.Context, topic string, count int, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append(opts,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+			attribute.Int("messaging.batch.message_count", count),
+		),
+	)
+	return otel.Tracer(tracerName).Start(ctx, "send "+topic, opts...)
+}
+
+// StartConsumerSpan starts the span of processing msg, continuing the trace
+// the producer injected into its headers.
+func StartConsumerSpan(ctx context.Context, msg kafka.Message) (context.Context, trace.Span) {
+	ctx = extractTrace(ctx, msg)
+	return otel.Tracer(tracerName).Start(ctx, "process "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", msg.Topic),
+			attribute.Int("messaging.destination.partition.id", msg.Partition),
+			attribute.Int64("messaging.kafka.offset", msg.Offset),
+		),
+	)
+}
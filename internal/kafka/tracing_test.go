@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracePropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	provider := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "create task")
+	defer parent.End()
+
+	msg, err := NewEvent(EventTaskCreated, "task-1").Message()
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	injectTrace(ctx, &msg)
+	injectTrace(ctx, &msg)
+
+	if len(msg.Headers) != 1 || msg.Headers[0].Key != "traceparent" {
+		t.Fatalf("ожидался один заголовок traceparent: %+v", msg.Headers)
+	}
+
+	t.Run("консьюмер продолжает трейс продюсера", func(t *testing.T) {
+		_, span := StartConsumerSpan(context.Background(), msg)
+		defer span.End()
+
+		got := span.SpanContext()
+		if got.TraceID() != parent.SpanContext().TraceID() {
+			t.Errorf("trace id консьюмера %s, ожидался %s", got.TraceID(), parent.SpanContext().TraceID())
+		}
+		if ro, ok := span.(sdktrace.ReadOnlySpan); !ok || ro.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Error("span консьюмера должен быть дочерним для span продюсера")
+		}
+	})
+
+	t.Run("сохранённый контекст переносится в заголовки", func(t *testing.T) {
+		m := msg
+		m.Headers = TraceHeaders(InjectTraceMap(ctx))
+
+		got := trace.SpanContextFromContext(extractTrace(context.Background(), m))
+		if got.TraceID() != parent.SpanContext().TraceID() {
+			t.Errorf("trace id %s, ожидался %s", got.TraceID(), parent.SpanContext().TraceID())
+		}
+	})
+}
@@ -0,0 +1,68 @@
+// Package metrics defines the Prometheus metrics of the checklist services
+// and the middleware that records them. All metrics live in the default
+// registry, which Handler exposes.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "checklist"
+
+// Cache results.
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+var (
+	// CacheRequests counts cache lookups by kind of entry ("task" or
+	// "task_list") and result.
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Cache lookups by entry kind and result (hit, miss or error).",
+	}, []string{"kind", "result"})
+
+	KafkaProduced = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_produced_total",
+		Help:      "Messages written to Kafka.",
+	}, []string{"topic"})
+
+	KafkaProduceFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "produce_failures_total",
+		Help:      "Messages that could not be written to Kafka.",
+	}, []string{"topic"})
+
+	// KafkaConsumed counts consumed messages by event type, "unknown" for
+	// messages that are not event envelopes.
+	KafkaConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_consumed_total",
+		Help:      "Messages read from Kafka by event type.",
+	}, []string{"type"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDBStats exports the connection pool statistics of db, labelled
+// with name.
+func RegisterDBStats(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(GinMiddleware())
+	router.GET("/tasks/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, path := range []string{"/tasks/1", "/tasks/2", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/tasks/:id", "404")); got != 2 {
+		t.Errorf("ожидалось 2 запроса по шаблону маршрута, получено %v", got)
+	}
+	if got := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "unmatched", "404")); got != 1 {
+		t.Errorf("ожидался 1 запрос без маршрута, получено %v", got)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/checklist.TaskService/Get"}
+
+	interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "task not found")
+	})
+	interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+
+	if got := testutil.ToFloat64(grpcHandled.WithLabelValues(info.FullMethod, "NotFound")); got != 1 {
+		t.Errorf("ожидался 1 вызов с NotFound, получено %v", got)
+	}
+	if got := testutil.ToFloat64(grpcHandled.WithLabelValues(info.FullMethod, "OK")); got != 1 {
+		t.Errorf("ожидался 1 успешный вызов, получено %v", got)
+	}
+}
@@ -0,0 +1,459 @@
+// Package rrule parses and expands recurrence rules, a subset of the RRULE
+// property of RFC 5545 (iCalendar).
+//
+// Supported parts are FREQ (DAILY, WEEKLY, MONTHLY or YEARLY), INTERVAL,
+// COUNT, UNTIL, BYMONTH, BYMONTHDAY, BYDAY and WKST=MO. BYDAY takes
+// ordinals such as 1MO or -1FR only with FREQ=MONTHLY, or FREQ=YEARLY with
+// BYMONTH, and they count within the month.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid recurrence rule")
+
+type Frequency int
+
+const (
+	Daily Frequency = iota + 1
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencyNames = map[Frequency]string{
+	Daily:   "DAILY",
+	Weekly:  "WEEKLY",
+	Monthly: "MONTHLY",
+	Yearly:  "YEARLY",
+}
+
+func (f Frequency) String() string {
+	return frequencyNames[f]
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum is a BYDAY entry: every Day, or with N set the Nth Day of the
+// month, counting from its end if N is negative.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Day]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Day]
+}
+
+// Rule is a parsed recurrence rule. The series it describes starts at a time
+// given separately, like DTSTART in iCalendar, and occurrences keep the wall
+// clock time of the start in its location.
+type Rule struct {
+	Freq Frequency
+	// Interval is the number of periods of Freq between occurrences, at
+	// least 1.
+	Interval int
+	// Count limits the series to that many occurrences, including the
+	// start. Zero means no limit.
+	Count int
+	// Until is the last time an occurrence may happen at. If UntilDate is
+	// set, Until is a date and the whole of that day in the location of the
+	// series is included.
+	Until      time.Time
+	UntilDate  bool
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+}
+
+// maxPeriods bounds the search for the next occurrence, so that rules that
+// never match again, like February 30th, end instead of looping.
+const maxPeriods = 10000
+
+// Parse reads a rule like "FREQ=WEEKLY;BYDAY=MO,WE". An "RRULE:" prefix is
+// allowed.
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return r, fmt.Errorf("%w: empty", ErrInvalid)
+	}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(key)
+		if !ok || value == "" {
+			return r, fmt.Errorf("%w: malformed part %q", ErrInvalid, part)
+		}
+		if seen[key] {
+			return r, fmt.Errorf("%w: %s given twice", ErrInvalid, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq, err = parseFreq(value)
+		case "INTERVAL":
+			r.Interval, err = parsePositive(value)
+		case "COUNT":
+			r.Count, err = parsePositive(value)
+		case "UNTIL":
+			r.Until, r.UntilDate, err = parseUntil(value)
+		case "BYMONTH":
+			r.ByMonth, err = parseList(value, parseMonth)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseList(value, parseMonthDay)
+		case "BYDAY":
+			r.ByDay, err = parseList(value, parseWeekdayNum)
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				err = errors.New("only WKST=MO is supported")
+			}
+		default:
+			err = errors.New("unsupported part")
+		}
+		if err != nil {
+			return r, fmt.Errorf("%w: %s=%s: %v", ErrInvalid, key, value, err)
+		}
+	}
+
+	if r.Freq == 0 {
+		return r, fmt.Errorf("%w: FREQ is required", ErrInvalid)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return r, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalid)
+	}
+	for _, d := range r.ByDay {
+		if d.N == 0 {
+			continue
+		}
+		if r.Freq != Monthly && (r.Freq != Yearly || len(r.ByMonth) == 0) {
+			return r, fmt.Errorf("%w: BYDAY=%s needs FREQ=MONTHLY, or FREQ=YEARLY with BYMONTH", ErrInvalid, d)
+		}
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return r, fmt.Errorf("%w: BYMONTHDAY cannot be used with FREQ=WEEKLY", ErrInvalid)
+	}
+	return r, nil
+}
+
+func parseFreq(s string) (Frequency, error) {
+	for f, name := range frequencyNames {
+		if strings.EqualFold(s, name) {
+			return f, nil
+		}
+	}
+	return 0, errors.New("expected DAILY, WEEKLY, MONTHLY or YEARLY")
+}
+
+func parsePositive(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, errors.New("expected a positive number")
+	}
+	return n, nil
+}
+
+func parseUntil(s string) (time.Time, bool, error) {
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse("20060102", s); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, errors.New("expected a date like 20260131 or a UTC time like 20260131T090000Z")
+}
+
+func parseMonth(s string) (time.Month, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 12 {
+		return 0, errors.New("expected months from 1 to 12")
+	}
+	return time.Month(n), nil
+}
+
+func parseMonthDay(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n == 0 || n < -31 || n > 31 {
+		return 0, errors.New("expected days from 1 to 31 or from -31 to -1")
+	}
+	return n, nil
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	s = strings.ToUpper(s)
+	if len(s) < 2 {
+		return WeekdayNum{}, errors.New("expected days like MO or -1FR")
+	}
+	day := slices.Index(weekdayNames[:], s[len(s)-2:])
+	if day < 0 {
+		return WeekdayNum{}, errors.New("expected days like MO or -1FR")
+	}
+	w := WeekdayNum{Day: time.Weekday(day)}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, errors.New("expected ordinals from 1 to 5 or from -5 to -1")
+		}
+		w.N = n
+	}
+	return w, nil
+}
+
+func parseList[T any](s string, parse func(string) (T, error)) ([]T, error) {
+	var list []T
+	for _, item := range strings.Split(s, ",") {
+		v, err := parse(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// String formats r in the canonical order of its parts, so that Parse of the
+// result gives r back.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinList(r.ByMonth, func(m time.Month) string { return strconv.Itoa(int(m)) }))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinList(r.ByMonthDay, strconv.Itoa))
+	}
+	if len(r.ByDay) > 0 {
+		parts = append(parts, "BYDAY="+joinList(r.ByDay, WeekdayNum.String))
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinList[T any](list []T, format func(T) string) string {
+	s := make([]string, len(list))
+	for i, v := range list {
+		s[i] = format(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// Expand returns up to limit occurrences of the series that starts at start,
+// in order. start is always the first occurrence, as in iCalendar.
+func (r Rule) Expand(start time.Time, limit int) []time.Time {
+	if limit <= 0 {
+		return nil
+	}
+	occurrences := []time.Time{start}
+	if r.Count > 0 {
+		limit = min(limit, r.Count)
+	}
+
+	interval := max(r.Interval, 1)
+	for period := 0; period < maxPeriods && len(occurrences) < limit; period++ {
+		for _, t := range r.candidates(start, period*interval) {
+			if !t.After(occurrences[len(occurrences)-1]) {
+				continue
+			}
+			if !r.beforeUntil(t) {
+				return occurrences
+			}
+			occurrences = append(occurrences, t)
+			if len(occurrences) == limit {
+				break
+			}
+		}
+	}
+	return occurrences
+}
+
+// Next returns the occurrence that follows start in the series starting at
+// it, and the rule of the series that continues from there: r with COUNT
+// reduced by one. ok is false if the series ends with start.
+func (r Rule) Next(start time.Time) (next time.Time, rest Rule, ok bool) {
+	occurrences := r.Expand(start, 2)
+	if len(occurrences) < 2 {
+		return time.Time{}, r, false
+	}
+	rest = r
+	if rest.Count > 0 {
+		rest.Count--
+	}
+	return occurrences[1], rest, true
+}
+
+func (r Rule) beforeUntil(t time.Time) bool {
+	switch {
+	case r.Until.IsZero():
+		return true
+	case r.UntilDate:
+		y, m, d := t.Date()
+		return !time.Date(y, m, d, 0, 0, 0, 0, time.UTC).After(r.Until)
+	default:
+		return !t.After(r.Until)
+	}
+}
+
+// candidates returns the times in the period that is offset periods of Freq
+// after the one containing start, sorted.
+func (r Rule) candidates(start time.Time, offset int) []time.Time {
+	var days []date
+	switch y, m, d := start.Date(); r.Freq {
+	case Daily:
+		days = []date{newDate(y, m, d+offset)}
+	case Weekly:
+		// Weeks start on Monday.
+		monday := d - (int(start.Weekday())+6)%7 + 7*offset
+		for i := range 7 {
+			days = append(days, newDate(y, m, monday+i))
+		}
+		if len(r.ByDay) == 0 {
+			days = slices.DeleteFunc(days, func(dt date) bool { return dt.weekday() != start.Weekday() })
+		}
+	case Monthly:
+		first := newDate(y, m+time.Month(offset), 1)
+		days = r.monthDays(first.year, first.month, d)
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{m}
+			if len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 {
+				months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			}
+		}
+		for _, month := range months {
+			days = append(days, r.monthDays(y+offset, month, d)...)
+		}
+	}
+
+	var times []time.Time
+	for _, dt := range days {
+		if r.matches(dt) {
+			times = append(times, localTime(dt, start))
+		}
+	}
+	slices.SortFunc(times, time.Time.Compare)
+	return slices.CompactFunc(times, time.Time.Equal)
+}
+
+// monthDays returns the days of a month picked by BYMONTHDAY and BYDAY, or
+// the day of the start if there are none. Days the month does not have are
+// skipped, as RFC 5545 requires.
+func (r Rule) monthDays(year int, month time.Month, startDay int) []date {
+	n := daysIn(year, month)
+	var days []date
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d += n + 1
+			}
+			if d >= 1 && d <= n {
+				days = append(days, date{year, month, d})
+			}
+		}
+	case len(r.ByDay) > 0:
+		for d := 1; d <= n; d++ {
+			days = append(days, date{year, month, d})
+		}
+	case startDay <= n:
+		days = append(days, date{year, month, startDay})
+	}
+	return days
+}
+
+// matches applies the BYMONTH and BYDAY filters to a day.
+func (r Rule) matches(dt date) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, dt.month) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != Monthly && r.Freq != Yearly {
+		n := daysIn(dt.year, dt.month)
+		if !slices.ContainsFunc(r.ByMonthDay, func(d int) bool { return d == dt.day || d == dt.day-n-1 }) {
+			return false
+		}
+	}
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	n := daysIn(dt.year, dt.month)
+	return slices.ContainsFunc(r.ByDay, func(w WeekdayNum) bool {
+		switch {
+		case w.Day != dt.weekday():
+			return false
+		case w.N > 0:
+			return (dt.day-1)/7+1 == w.N
+		case w.N < 0:
+			return (n-dt.day)/7+1 == -w.N
+		default:
+			return true
+		}
+	})
+}
+
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// newDate normalizes overflowing months and days like time.Date does.
+func newDate(year int, month time.Month, day int) date {
+	y, m, d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Date()
+	return date{y, m, d}
+}
+
+func (dt date) weekday() time.Weekday {
+	return time.Date(dt.year, dt.month, dt.day, 0, 0, 0, 0, time.UTC).Weekday()
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// localTime returns the wall clock time of start on day dt, in the location
+// of start. As RFC 5545 requires, a time skipped by a DST change is taken
+// with the UTC offset before the change, which moves it forward by the size
+// of the gap, and a time that happens twice is its first occurrence.
+// time.Date guarantees neither.
+func localTime(dt date, start time.Time) time.Time {
+	loc := start.Location()
+	wall := time.Date(dt.year, dt.month, dt.day, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), time.UTC)
+
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := wall.Add(24 * time.Hour).In(loc).Zone()
+	early := wall.Add(-time.Duration(before) * time.Second).In(loc)
+	late := wall.Add(-time.Duration(after) * time.Second).In(loc)
+	if !sameWallClock(early, wall) && sameWallClock(late, wall) {
+		return late
+	}
+	return early
+}
+
+func sameWallClock(t, wall time.Time) bool {
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := wall.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2 && t.Hour() == wall.Hour() && t.Minute() == wall.Minute() && t.Second() == wall.Second()
+}
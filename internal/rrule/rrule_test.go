@@ -0,0 +1,274 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("не удалось загрузить часовой пояс %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;byday=mo,we;interval=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=12", "FREQ=MONTHLY;COUNT=12;BYDAY=-1FR"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1", "FREQ=MONTHLY;BYMONTHDAY=1,-1"},
+		{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;UNTIL=20400101", "FREQ=YEARLY;UNTIL=20400101;BYMONTH=2;BYMONTHDAY=29"},
+		{"FREQ=DAILY;UNTIL=20260131T090000Z;WKST=MO", "FREQ=DAILY;UNTIL=20260131T090000Z"},
+		{"FREQ=WEEKLY;INTERVAL=1", "FREQ=WEEKLY"},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			r, err := Parse(tc.in)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if got := r.String(); got != tc.want {
+				t.Errorf("ожидалось %q, получено %q", tc.want, got)
+			}
+			if again, err := Parse(r.String()); err != nil || again.String() != r.String() {
+				t.Errorf("строка правила должна разбираться обратно: %q, %v", again.String(), err)
+			}
+		})
+	}
+
+	for name, in := range map[string]string{
+		"пустое правило":           "",
+		"без FREQ":                 "INTERVAL=2",
+		"неизвестная частота":      "FREQ=HOURLY",
+		"нулевой интервал":         "FREQ=DAILY;INTERVAL=0",
+		"повтор части":             "FREQ=DAILY;FREQ=WEEKLY",
+		"COUNT вместе с UNTIL":     "FREQ=DAILY;COUNT=3;UNTIL=20260101",
+		"неизвестная часть":        "FREQ=DAILY;BYHOUR=9",
+		"нулевой день месяца":      "FREQ=MONTHLY;BYMONTHDAY=0",
+		"тринадцатый месяц":        "FREQ=YEARLY;BYMONTH=13",
+		"неизвестный день недели":  "FREQ=WEEKLY;BYDAY=XX",
+		"порядковый день в неделе": "FREQ=WEEKLY;BYDAY=1MO",
+		"порядковый день в году":   "FREQ=YEARLY;BYDAY=20MO",
+		"день месяца в недельном":  "FREQ=WEEKLY;BYMONTHDAY=1",
+		"неделя не с понедельника": "FREQ=WEEKLY;WKST=SU",
+		"UNTIL в местном времени":  "FREQ=DAILY;UNTIL=20260101T090000",
+		"часть без значения":       "FREQ=DAILY;COUNT",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(in); !errors.Is(err, ErrInvalid) {
+				t.Errorf("ожидалась ErrInvalid для %q, получено %v", in, err)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	berlin := mustLocation(t, "Europe/Berlin")
+	newYork := mustLocation(t, "America/New_York")
+	utc := func(y int, m time.Month, d, h, min int) time.Time { return time.Date(y, m, d, h, min, 0, 0, time.UTC) }
+	in := func(loc *time.Location) func(y int, m time.Month, d, h, min int) time.Time {
+		return func(y int, m time.Month, d, h, min int) time.Time { return time.Date(y, m, d, h, min, 0, 0, loc) }
+	}
+	ber, ny := in(berlin), in(newYork)
+
+	for _, tc := range []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []time.Time
+		// ends is set for series that have no more occurrences than want.
+		ends bool
+	}{
+		{
+			name:  "ежедневно в 9:00 через переход на летнее время",
+			rule:  "FREQ=DAILY",
+			start: ber(2026, 3, 28, 9, 0),
+			want:  []time.Time{ber(2026, 3, 28, 9, 0), ber(2026, 3, 29, 9, 0), ber(2026, 3, 30, 9, 0)},
+		},
+		{
+			name:  "несуществующее время сдвигается вперёд на размер разрыва",
+			rule:  "FREQ=DAILY",
+			start: ber(2026, 3, 28, 2, 30),
+			want:  []time.Time{ber(2026, 3, 28, 2, 30), utc(2026, 3, 29, 1, 30), ber(2026, 3, 30, 2, 30)},
+		},
+		{
+			name:  "несуществующее время в Нью-Йорке тоже сдвигается вперёд",
+			rule:  "FREQ=DAILY",
+			start: ny(2026, 3, 7, 2, 30),
+			want:  []time.Time{ny(2026, 3, 7, 2, 30), utc(2026, 3, 8, 7, 30), ny(2026, 3, 9, 2, 30)},
+		},
+		{
+			name:  "повторяющееся время берётся в первый раз",
+			rule:  "FREQ=DAILY",
+			start: ber(2026, 10, 24, 2, 30),
+			want:  []time.Time{ber(2026, 10, 24, 2, 30), utc(2026, 10, 25, 0, 30), ber(2026, 10, 26, 2, 30)},
+		},
+		{
+			name:  "повторяющееся время в Нью-Йорке",
+			rule:  "FREQ=DAILY",
+			start: ny(2026, 10, 31, 1, 30),
+			want:  []time.Time{ny(2026, 10, 31, 1, 30), utc(2026, 11, 1, 5, 30), ny(2026, 11, 2, 1, 30)},
+		},
+		{
+			name:  "еженедельно через переход на зимнее время",
+			rule:  "FREQ=WEEKLY",
+			start: ber(2026, 10, 19, 10, 0),
+			want:  []time.Time{ber(2026, 10, 19, 10, 0), ber(2026, 10, 26, 10, 0), ber(2026, 11, 2, 10, 0)},
+		},
+		{
+			name:  "раз в две недели по понедельникам и средам",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			start: utc(2026, 1, 7, 9, 0),
+			want:  []time.Time{utc(2026, 1, 7, 9, 0), utc(2026, 1, 19, 9, 0), utc(2026, 1, 21, 9, 0), utc(2026, 2, 2, 9, 0)},
+		},
+		{
+			name:  "31-е число пропускает короткие месяцы",
+			rule:  "FREQ=MONTHLY",
+			start: utc(2026, 1, 31, 12, 0),
+			want:  []time.Time{utc(2026, 1, 31, 12, 0), utc(2026, 3, 31, 12, 0), utc(2026, 5, 31, 12, 0), utc(2026, 7, 31, 12, 0)},
+		},
+		{
+			name:  "последний день месяца",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: utc(2028, 1, 31, 18, 0),
+			want:  []time.Time{utc(2028, 1, 31, 18, 0), utc(2028, 2, 29, 18, 0), utc(2028, 3, 31, 18, 0), utc(2028, 4, 30, 18, 0)},
+		},
+		{
+			name:  "30-е число в феврале пропускается",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=30",
+			start: utc(2026, 1, 30, 8, 0),
+			want:  []time.Time{utc(2026, 1, 30, 8, 0), utc(2026, 3, 30, 8, 0)},
+		},
+		{
+			name:  "последняя пятница месяца",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: utc(2026, 1, 30, 16, 0),
+			want:  []time.Time{utc(2026, 1, 30, 16, 0), utc(2026, 2, 27, 16, 0), utc(2026, 3, 27, 16, 0)},
+		},
+		{
+			name:  "первый понедельник раз в квартал",
+			rule:  "FREQ=MONTHLY;INTERVAL=3;BYDAY=1MO",
+			start: utc(2026, 1, 5, 10, 0),
+			want:  []time.Time{utc(2026, 1, 5, 10, 0), utc(2026, 4, 6, 10, 0), utc(2026, 7, 6, 10, 0)},
+		},
+		{
+			name:  "29 февраля бывает только в високосные годы",
+			rule:  "FREQ=YEARLY",
+			start: utc(2028, 2, 29, 0, 0),
+			want:  []time.Time{utc(2028, 2, 29, 0, 0), utc(2032, 2, 29, 0, 0), utc(2036, 2, 29, 0, 0)},
+		},
+		{
+			name:  "конец февраля каждый год",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+			start: utc(2027, 2, 28, 0, 0),
+			want:  []time.Time{utc(2027, 2, 28, 0, 0), utc(2028, 2, 29, 0, 0), utc(2029, 2, 28, 0, 0)},
+		},
+		{
+			name:  "по будням с переходом через месяц",
+			rule:  "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			start: utc(2026, 1, 29, 9, 0),
+			want:  []time.Time{utc(2026, 1, 29, 9, 0), utc(2026, 1, 30, 9, 0), utc(2026, 2, 2, 9, 0)},
+		},
+		{
+			name:  "COUNT учитывает начало серии",
+			rule:  "FREQ=DAILY;COUNT=2",
+			start: utc(2026, 1, 1, 9, 0),
+			want:  []time.Time{utc(2026, 1, 1, 9, 0), utc(2026, 1, 2, 9, 0)},
+			ends:  true,
+		},
+		{
+			name:  "UNTIL датой включает весь день в часовом поясе серии",
+			rule:  "FREQ=DAILY;UNTIL=20260330",
+			start: ber(2026, 3, 29, 23, 30),
+			want:  []time.Time{ber(2026, 3, 29, 23, 30), ber(2026, 3, 30, 23, 30)},
+			ends:  true,
+		},
+		{
+			name:  "UNTIL временем в UTC",
+			rule:  "FREQ=WEEKLY;UNTIL=20260115T090000Z",
+			start: utc(2026, 1, 1, 9, 0),
+			want:  []time.Time{utc(2026, 1, 1, 9, 0), utc(2026, 1, 8, 9, 0), utc(2026, 1, 15, 9, 0)},
+			ends:  true,
+		},
+		{
+			name:  "правило, которое больше не срабатывает",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			start: utc(2026, 1, 1, 0, 0),
+			want:  []time.Time{utc(2026, 1, 1, 0, 0)},
+			ends:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := Parse(tc.rule)
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			limit := len(tc.want)
+			if tc.ends {
+				limit++
+			}
+			got := r.Expand(tc.start, limit)
+			if len(got) != len(tc.want) {
+				t.Fatalf("ожидалось %d повторений, получено %d: %v", len(tc.want), len(got), got)
+			}
+			for i := range got {
+				if !got[i].Equal(tc.want[i]) {
+					t.Errorf("повторение %d: ожидалось %v, получено %v", i, tc.want[i], got[i])
+				}
+				if got[i].Location() != tc.start.Location() {
+					t.Errorf("повторение %d должно быть в часовом поясе начала: %v", i, got[i].Location())
+				}
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	t.Run("COUNT уменьшается с каждым повторением", func(t *testing.T) {
+		r, _ := Parse("FREQ=DAILY;COUNT=2")
+		start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+		next, rest, ok := r.Next(start)
+		if !ok || !next.Equal(start.AddDate(0, 0, 1)) {
+			t.Fatalf("неожиданное повторение: %v, %t", next, ok)
+		}
+		if rest.String() != "FREQ=DAILY;COUNT=1" {
+			t.Errorf("неожиданное правило продолжения: %s", rest)
+		}
+		if _, _, ok := rest.Next(next); ok {
+			t.Error("после последнего повторения серия должна закончиться")
+		}
+	})
+
+	t.Run("цепочка повторений совпадает с разворачиванием", func(t *testing.T) {
+		r, _ := Parse("FREQ=MONTHLY;BYMONTHDAY=31,-1;COUNT=6")
+		start := time.Date(2026, 1, 31, 9, 0, 0, 0, mustLocation(t, "Europe/Moscow"))
+		want := r.Expand(start, 10)
+
+		got := []time.Time{start}
+		for cur, rule := start, r; ; {
+			next, rest, ok := rule.Next(cur)
+			if !ok {
+				break
+			}
+			got = append(got, next)
+			cur, rule = next, rest
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("ожидалось %v, получено %v", want, got)
+		}
+		for i := range got {
+			if !got[i].Equal(want[i]) {
+				t.Errorf("повторение %d: ожидалось %v, получено %v", i, want[i], got[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetupFileExporter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.json")
+
+	shutdown, err := Setup(context.Background(), Config{
+		ServiceName: "checklist-test",
+		Exporter:    ExporterStdout,
+		File:        file,
+	})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "create task")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("неожиданная ошибка при остановке: %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Name":"create task"`, "checklist-test"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("в экспорте нет %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "jaeger"}); err == nil {
+		t.Fatal("ожидалась ошибка для неизвестного экспортёра")
+	}
+}